@@ -0,0 +1,107 @@
+package extractor
+
+import (
+	"github.com/LeadNewswire/article-extractor/internal/cleaner"
+	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/LeadNewswire/article-extractor/internal/render"
+	"github.com/LeadNewswire/article-extractor/internal/scorer"
+	"github.com/LeadNewswire/article-extractor/internal/trace"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Alternative is a runner-up content candidate, returned so that a person
+// can pick the right body when the top candidate is wrong.
+type Alternative struct {
+	// Element is a short CSS-like description of the candidate element
+	Element string `json:"element"`
+
+	// Content is the cleaned HTML content
+	Content string `json:"content"`
+
+	// TextContent is the plain text content
+	TextContent string `json:"textContent"`
+
+	// WordCount is the number of words in the content
+	WordCount int `json:"wordCount"`
+
+	// Score is the candidate's content score
+	Score float64 `json:"score"`
+
+	// Confidence scores (0-1) how likely this candidate is to be the
+	// correct content
+	Confidence float64 `json:"confidence"`
+}
+
+// runAlternatives extracts the best scoring candidates other than the
+// chosen content. Candidates inside or around the content, or inside or
+// around an earlier alternative, are skipped, as are candidates shorter
+// than MinContentLength.
+func runAlternatives(state *State) error {
+	limit := state.Config.Alternatives
+	if limit <= 0 || state.scoreMap == nil || state.Candidate == nil || state.Candidate.Length() == 0 {
+		return nil
+	}
+
+	chosen := []*goquery.Selection{state.Candidate}
+	topScore := state.topCandidate.GetScore()
+
+	var alternatives []Alternative
+	for _, candidate := range state.scoreMap.GetCandidatesByScore() {
+		if len(alternatives) >= limit {
+			break
+		}
+		if candidate.Selection == nil || candidate.Selection.Length() == 0 || overlaps(candidate.Selection, chosen) {
+			continue
+		}
+
+		alternative, ok := extractAlternative(state, candidate, topScore)
+		if !ok {
+			continue
+		}
+		alternatives = append(alternatives, alternative)
+		chosen = append(chosen, candidate.Selection)
+	}
+
+	state.Article.Alternatives = alternatives
+	return nil
+}
+
+// extractAlternative cleans and measures a candidate like the main content.
+func extractAlternative(state *State, candidate *scorer.NodeScore, topScore float64) (Alternative, bool) {
+	content := candidate.Selection.Clone()
+	cleaner.PostprocessWithOptions(content, state.cleanerOpts)
+	if state.URL != "" {
+		cleaner.ConvertRelativeURLs(content, state.URL)
+	}
+
+	rawText := cleaner.GetCleanText(content)
+	if len(rawText) < state.Config.MinContentLength {
+		return Alternative{}, false
+	}
+
+	wordCount := dom.CountWords(rawText)
+	features := contentFeatures(state, candidate, content, rawText, wordCount, topScore)
+
+	return Alternative{
+		Element:     trace.Describe(candidate.Selection),
+		Content:     cleaner.GetCleanHTML(content),
+		TextContent: render.Text(content, render.TextOptions{LinkFootnotes: state.Config.LinkFootnotes}),
+		WordCount:   wordCount,
+		Score:       candidate.GetScore(),
+		Confidence:  confidenceModel(state.Config).Predict(features),
+	}, true
+}
+
+// overlaps reports whether sel is, contains or is contained in any of the
+// chosen selections.
+func overlaps(sel *goquery.Selection, chosen []*goquery.Selection) bool {
+	node := sel.Nodes[0]
+	for _, c := range chosen {
+		for _, n := range c.Nodes {
+			if n == node || c.Contains(node) || sel.Contains(n) {
+				return true
+			}
+		}
+	}
+	return false
+}
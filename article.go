@@ -10,12 +10,28 @@ type Article struct {
 	// Content is the cleaned HTML content
 	Content string `json:"content"`
 
-	// TextContent is the plain text content
+	// TextContent is the plain text content, with blank lines between
+	// blocks, marked list items, underlined headings and aligned tables
 	TextContent string `json:"textContent"`
 
-	// Excerpt is a short summary/excerpt of the article
+	// Markdown is the content as GitHub-flavored Markdown (only set when
+	// Markdown output is enabled)
+	Markdown string `json:"markdown,omitempty"`
+
+	// Blocks is the content as a tree of typed blocks (only set when
+	// block output is enabled)
+	Blocks []Block `json:"blocks,omitempty"`
+
+	// Excerpt is a short excerpt of the article, from the page
+	// description when it matches the content
 	Excerpt string `json:"excerpt"`
 
+	// Summary is the article's most central sentences, in order
+	Summary string `json:"summary,omitempty"`
+
+	// Keywords lists the article's key phrases, best first
+	Keywords []Keyword `json:"keywords,omitempty"`
+
 	// Author is the article author
 	Author string `json:"author,omitempty"`
 
@@ -25,17 +41,54 @@ type Article struct {
 	// LeadImage is the main article image
 	LeadImage *Image `json:"leadImage,omitempty"`
 
+	// Images lists the images of the content in the order they appear
+	Images []Image `json:"images,omitempty"`
+
 	// URL is the source URL
 	URL string `json:"url,omitempty"`
 
-	// WordCount is the number of words in the article
+	// Language is the ISO 639-1 code of the article's language, e.g. "en"
+	Language string `json:"language,omitempty"`
+
+	// LanguageConfidence is the estimated probability (0-1) that Language
+	// is correct
+	LanguageConfidence float64 `json:"languageConfidence,omitempty"`
+
+	// WordCount is the number of words in the article; each Chinese or
+	// Japanese character counts as a word
 	WordCount int `json:"wordCount"`
 
-	// Score is the extraction score of the content
+	// ReadingTime is the estimated reading time at the reading speed of
+	// the article's language
+	ReadingTime time.Duration `json:"readingTime"`
+
+	// Fingerprint identifies the text content, without link footnotes, for
+	// duplicate detection
+	Fingerprint Fingerprint `json:"fingerprint"`
+
+	// Score is the extraction score of the content; for a multi-page
+	// article it is the score of the first page
 	Score float64 `json:"score"`
 
-	// Confidence is the confidence level (0-1)
+	// Confidence is the estimated probability (0-1) that the extraction is
+	// correct
 	Confidence float64 `json:"confidence"`
+
+	// Alternatives lists runner-up content candidates (only set when
+	// alternatives are enabled)
+	Alternatives []Alternative `json:"alternatives,omitempty"`
+
+	// PageType is the kind of page, e.g. news, press release or listing
+	PageType PageType `json:"pageType,omitempty"`
+
+	// NextPageURL is the URL of the next page of a paginated article
+	NextPageURL string `json:"nextPageUrl,omitempty"`
+
+	// Pages lists the URLs of the pages merged into the article
+	Pages []string `json:"pages,omitempty"`
+
+	// Trace records the extraction decisions (debug mode only)
+	Trace *Trace `json:"trace,omitempty"`
 }
 
 // Image represents an image in the article.
@@ -51,4 +104,14 @@ type Image struct {
 
 	// Alt is the alternative text
 	Alt string `json:"alt,omitempty"`
+
+	// Caption is the figure caption, without the credit
+	Caption string `json:"caption,omitempty"`
+
+	// Credit is the credit line from the caption, e.g. "Photo: Reuters"
+	Credit string `json:"credit,omitempty"`
+
+	// Position is the image's place in Article.Images, from 1 and counted
+	// across merged pages; 0 for a lead image outside the content
+	Position int `json:"position,omitempty"`
 }
@@ -0,0 +1,148 @@
+package extractor
+
+import (
+	"context"
+	"sync"
+
+	"github.com/LeadNewswire/article-extractor/internal/fetcher"
+)
+
+// Result is the outcome of extracting one URL in a batch.
+type Result struct {
+	// Index is the position of the URL in the input slice
+	Index int
+
+	// URL is the input URL
+	URL string
+
+	// Article is the extracted article, nil if Err is set
+	Article *Article
+
+	// Err is the extraction error for this URL
+	Err error
+}
+
+// ExtractAll fetches and extracts a batch of URLs concurrently. A pool of
+// MaxConcurrency workers takes the URLs in order, and at most MaxPerHost
+// of them work on any single host at once; URLs of a busy host wait in a
+// queue while the workers move on to other hosts. Results are delivered
+// as they complete, in no particular order; each carries the index of its
+// input URL. Errors are reported per URL and do not stop the batch. When
+// ctx is cancelled, the remaining URLs are reported with the context
+// error. The channel is closed once every URL has a result, and must be
+// drained.
+func (e *Extractor) ExtractAll(ctx context.Context, urls []string) <-chan Result {
+	e = e.current()
+
+	workers := e.config.MaxConcurrency
+	if workers <= 0 || workers > len(urls) {
+		workers = len(urls)
+	}
+	results := make(chan Result, workers)
+
+	jobs := make(chan batchJob)
+	go func() {
+		defer close(jobs)
+		for i, url := range urls {
+			jobs <- batchJob{index: i, url: url, host: hostFromURL(fetcher.NormalizeURL(url))}
+		}
+	}()
+
+	hosts := &hostLimiter{
+		limit:   e.config.MaxPerHost,
+		active:  make(map[string]int),
+		pending: make(map[string][]batchJob),
+	}
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				// A worker that finishes a job on a host runs the next job
+				// queued for it, so queued jobs always have a worker
+				for ok := hosts.start(job); ok; job, ok = hosts.finish(job.host) {
+					results <- e.extractJob(ctx, job)
+				}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// batchJob is a URL of a batch.
+type batchJob struct {
+	index int
+	url   string
+	host  string
+}
+
+// extractJob extracts the URL of a batch job.
+func (e *Extractor) extractJob(ctx context.Context, job batchJob) Result {
+	result := Result{Index: job.index, URL: job.url}
+	if err := ctx.Err(); err != nil {
+		result.Err = NewExtractionError("fetch", job.url, err)
+		return result
+	}
+	result.Article, result.Err = e.ExtractFromURL(ctx, job.url)
+	return result
+}
+
+// hostLimiter limits the number of jobs running against each host.
+type hostLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	active  map[string]int
+	pending map[string][]batchJob
+}
+
+// start takes a slot on the job's host and reports whether the job may
+// run. If the host is busy, the job is queued and start returns false.
+func (h *hostLimiter) start(job batchJob) bool {
+	if h.limit <= 0 {
+		return true
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.active[job.host] >= h.limit {
+		h.pending[job.host] = append(h.pending[job.host], job)
+		return false
+	}
+	h.active[job.host]++
+	return true
+}
+
+// finish releases a slot on a host. If a job is queued for the host, it
+// keeps the slot and returns the job to run next.
+func (h *hostLimiter) finish(host string) (batchJob, bool) {
+	if h.limit <= 0 {
+		return batchJob{}, false
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if queue := h.pending[host]; len(queue) > 0 {
+		job := queue[0]
+		if len(queue) == 1 {
+			delete(h.pending, host)
+		} else {
+			h.pending[host] = queue[1:]
+		}
+		return job, true
+	}
+
+	if h.active[host]--; h.active[host] == 0 {
+		delete(h.active, host)
+	}
+	return batchJob{}, false
+}
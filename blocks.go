@@ -0,0 +1,39 @@
+package extractor
+
+import "github.com/LeadNewswire/article-extractor/internal/render"
+
+// Block is a typed content block: a paragraph, heading, list, quote, code
+// block, image, table or embed. Only the fields relevant to its Type are set.
+type Block = render.Block
+
+// BlockType identifies the kind of a content block.
+type BlockType = render.BlockType
+
+// Inline is a run of text with the same formatting.
+type Inline = render.Inline
+
+// Mark is an inline formatting mark.
+type Mark = render.Mark
+
+// TableCell is a cell of a table block.
+type TableCell = render.TableCell
+
+// Content block types.
+const (
+	BlockParagraph = render.BlockParagraph
+	BlockHeading   = render.BlockHeading
+	BlockList      = render.BlockList
+	BlockQuote     = render.BlockQuote
+	BlockCode      = render.BlockCode
+	BlockImage     = render.BlockImage
+	BlockTable     = render.BlockTable
+	BlockEmbed     = render.BlockEmbed
+)
+
+// Inline marks.
+const (
+	MarkBold   = render.MarkBold
+	MarkItalic = render.MarkItalic
+	MarkCode   = render.MarkCode
+	MarkLink   = render.MarkLink
+)
@@ -0,0 +1,20 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/LeadNewswire/article-extractor/internal/classify"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// IsProbablyArticle makes a quick guess at whether an HTML page is an
+// article, without running the extraction pipeline. It looks at og:type,
+// JSON-LD @type, the number of long paragraphs and the page's link
+// density, and is much cheaper than a full extraction.
+func IsProbablyArticle(html string) bool {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		return false
+	}
+	return classify.IsProbablyArticle(doc)
+}
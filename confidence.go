@@ -0,0 +1,157 @@
+package extractor
+
+import (
+	"context"
+	"os"
+	"unicode/utf8"
+
+	"github.com/LeadNewswire/article-extractor/internal/confidence"
+	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/LeadNewswire/article-extractor/internal/scorer"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ConfidenceModel is a logistic regression model that turns extraction
+// features into Article.Confidence, the estimated probability that an
+// extraction is correct. The built-in model is fitted to a corpus of
+// labeled news and blog pages; FitConfidenceModel fits one to your own.
+type ConfidenceModel = confidence.Model
+
+// ConfidenceFeatures describes an extraction for the confidence model.
+type ConfidenceFeatures = confidence.Features
+
+// ConfidenceCorpusEntry is a page in a labeled confidence corpus.
+type ConfidenceCorpusEntry = confidence.Entry
+
+// ConfidenceSample is a labeled extraction of a confidence corpus.
+type ConfidenceSample = confidence.Sample
+
+// ConfidenceFit reports how FitConfidenceModel fitted a model.
+type ConfidenceFit struct {
+	// Samples holds the file, features and label of each extracted page
+	Samples []ConfidenceSample
+
+	// Skipped lists the corpus files that could not be parsed or
+	// extracted. They yield no Article and no features, so they are left
+	// out of the fit
+	Skipped []string
+
+	// Iterations is the number of gradient descent steps taken
+	Iterations int
+
+	// Loss is the final mean log loss on the samples
+	Loss float64
+
+	// Converged is true if training stopped at the gradient tolerance
+	// rather than the iteration limit
+	Converged bool
+}
+
+// ErrNotEnoughSamples is returned by FitConfidenceModel when the corpus does
+// not contain both correct and incorrect extractions.
+var ErrNotEnoughSamples = confidence.ErrNotEnoughSamples
+
+// defaultConfidenceModel is the built-in model, shared by extractors
+// without their own.
+var defaultConfidenceModel = confidence.Default()
+
+// DefaultConfidenceModel returns a copy of the built-in confidence model.
+func DefaultConfidenceModel() *ConfidenceModel {
+	return confidence.Default()
+}
+
+// LoadConfidenceModel reads a confidence model saved with
+// ConfidenceModel.Save.
+func LoadConfidenceModel(path string) (*ConfidenceModel, error) {
+	return confidence.Load(path)
+}
+
+// FitConfidenceModel fits a confidence model to a labeled corpus on disk.
+// The directory holds HTML files and a labels.jsonl manifest with one
+// entry per line, e.g.
+//
+//	{"file": "story.html", "url": "https://example.com/story", "correct": true}
+//
+// where correct records whether this extractor's output for the page was
+// judged correct. Pages are extracted with the extractor's configuration.
+// Pages that fail to parse or extract have no confidence to calibrate and
+// are not fitted; the returned ConfidenceFit lists them with the samples
+// and the training loss.
+func (e *Extractor) FitConfidenceModel(dir string) (*ConfidenceModel, *ConfidenceFit, error) {
+	e = e.current()
+
+	entries, err := confidence.LoadCorpus(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fit := &ConfidenceFit{}
+	for _, entry := range entries {
+		f, err := os.Open(entry.File)
+		if err != nil {
+			return nil, nil, err
+		}
+		doc, err := goquery.NewDocumentFromReader(f)
+		f.Close()
+		if err != nil {
+			fit.Skipped = append(fit.Skipped, entry.File)
+			continue
+		}
+
+		p, err := e.extractPage(context.Background(), doc, entry.URL)
+		if err != nil {
+			fit.Skipped = append(fit.Skipped, entry.File)
+			continue
+		}
+		fit.Samples = append(fit.Samples, confidence.Sample{
+			File:     entry.File,
+			Features: p.features,
+			Correct:  entry.Correct,
+		})
+	}
+
+	model, stats, err := confidence.Fit(fit.Samples)
+	if err != nil {
+		return nil, fit, err
+	}
+	fit.Iterations, fit.Loss, fit.Converged = stats.Iterations, stats.Loss, stats.Converged
+	return model, fit, nil
+}
+
+// confidenceModel returns the configured confidence model or the default.
+func confidenceModel(config *Config) *ConfidenceModel {
+	if config.ConfidenceModel != nil {
+		return config.ConfidenceModel
+	}
+	return defaultConfidenceModel
+}
+
+// contentFeatures completes the confidence features for a candidate and
+// its cleaned content. Metadata and preprocessing features are already
+// set on the state. rival is the score of the strongest competing
+// candidate.
+func contentFeatures(state *State, candidate *scorer.NodeScore, content *goquery.Selection, rawText string, wordCount int, rival float64) confidence.Features {
+	features := state.features
+
+	features.Score = candidate.GetScore()
+	features.WordCount = wordCount
+	features.Paragraphs = content.Find("p").Length()
+	features.LinkDensity = candidate.LinkDensity
+	features.ScoreRatio = confidence.ScoreRatio(features.Score, rival)
+
+	// Whether the title matches a heading on the page
+	var headings []string
+	state.Document.Find("h1, h2").Each(func(_ int, sel *goquery.Selection) {
+		headings = append(headings, sel.Text())
+	})
+	features.TitleAgreement = confidence.TitleAgreement(state.Article.Title, headings)
+
+	if state.pageText > 0 {
+		features.ContentRatio = float64(utf8.RuneCountInString(dom.NormalizeText(rawText))) / float64(state.pageText)
+		if features.ContentRatio > 1 {
+			features.ContentRatio = 1
+		}
+	}
+
+	return features
+}
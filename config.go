@@ -1,6 +1,12 @@
 package extractor
 
-import "time"
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LeadNewswire/article-extractor/internal/keywords"
+)
 
 // Config holds the configuration options for the extractor.
 type Config struct {
@@ -16,11 +22,92 @@ type Config struct {
 	// HTTPTimeout is the timeout for HTTP requests
 	HTTPTimeout time.Duration
 
+	// ExtractTimeout is the time budget for extracting each page once it
+	// is parsed (0 means no limit)
+	ExtractTimeout time.Duration
+
 	// UserAgent is the User-Agent header for HTTP requests
 	UserAgent string
 
 	// MaxContentLength is the maximum HTML content length to process
 	MaxContentLength int
+
+	// Rules holds site-specific extraction rules keyed by domain
+	Rules *RuleRegistry
+
+	// MultiPage enables fetching and merging the later pages of
+	// paginated articles in ExtractFromURL
+	MultiPage bool
+
+	// MaxPages is the maximum number of pages to merge in multi-page mode
+	MaxPages int
+
+	// MaxConcurrency is the maximum number of concurrent extractions in
+	// ExtractAll (0 means unlimited)
+	MaxConcurrency int
+
+	// MaxPerHost is the maximum number of concurrent extractions against a
+	// single host in ExtractAll (0 means unlimited)
+	MaxPerHost int
+
+	// ArticleCheck makes ExtractFromURL return ErrNotArticle for pages
+	// that IsProbablyArticle rejects, before running the extraction
+	ArticleCheck bool
+
+	// ConfidenceModel computes Article.Confidence (nil uses the built-in
+	// model)
+	ConfidenceModel *ConfidenceModel
+
+	// Alternatives is the number of runner-up content candidates to return
+	// in Article.Alternatives (0 disables them)
+	Alternatives int
+
+	// Markdown enables rendering the content as Markdown into
+	// Article.Markdown
+	Markdown bool
+
+	// LinkFootnotes lists link URLs as numbered footnotes in
+	// Article.TextContent
+	LinkFootnotes bool
+
+	// Blocks enables building the typed block tree into Article.Blocks
+	Blocks bool
+
+	// SummarySentences is the number of sentences in Article.Summary
+	// (0 disables the summary)
+	SummarySentences int
+
+	// MaxKeywords is the maximum number of key phrases in
+	// Article.Keywords (0 disables them)
+	MaxKeywords int
+
+	// Blacklist holds the class and id keywords of unlikely content, used
+	// to strip elements, weight candidates and reject siblings
+	Blacklist []string
+
+	// Whitelist holds the class and id keywords of likely content
+	Whitelist []string
+
+	// BlacklistWeight is subtracted from a candidate's weight for a class
+	// or id matching the blacklist
+	BlacklistWeight int
+
+	// WhitelistWeight is added to a candidate's weight for a class or id
+	// matching the whitelist
+	WhitelistWeight int
+
+	// WidgetClasses are extra class names of widgets that are always removed
+	WidgetClasses []string
+
+	// PreserveTags are tags that cleaning never removes (e.g. "iframe")
+	PreserveTags []string
+
+	// AllowedAttributes are extra attributes kept on content elements,
+	// keyed by tag ("*" for every tag)
+	AllowedAttributes map[string][]string
+
+	// Pipeline holds edits applied in order to the default pipeline stages
+	Pipeline []PipelineEdit
 }
 
 // DefaultConfig returns the default configuration.
@@ -32,6 +119,16 @@ func DefaultConfig() *Config {
 		HTTPTimeout:        30 * time.Second,
 		UserAgent:          "Mozilla/5.0 (compatible; ArticleExtractor/1.0)",
 		MaxContentLength:   10 * 1024 * 1024, // 10MB
+		MultiPage:          false,
+		MaxPages:           10,
+		MaxConcurrency:     8,
+		MaxPerHost:         2,
+		SummarySentences:   0,
+		MaxKeywords:        10,
+		Blacklist:          keywords.DefaultBlacklist(),
+		Whitelist:          keywords.DefaultWhitelist(),
+		BlacklistWeight:    keywords.DefaultBlacklistWeight,
+		WhitelistWeight:    keywords.DefaultWhitelistWeight,
 	}
 }
 
@@ -66,6 +163,13 @@ func WithHTTPTimeout(timeout time.Duration) Option {
 	}
 }
 
+// WithExtractTimeout sets the time budget for extracting each page.
+func WithExtractTimeout(timeout time.Duration) Option {
+	return func(c *Config) {
+		c.ExtractTimeout = timeout
+	}
+}
+
 // WithUserAgent sets the User-Agent header.
 func WithUserAgent(ua string) Option {
 	return func(c *Config) {
@@ -79,3 +183,269 @@ func WithMaxContentLength(length int) Option {
 		c.MaxContentLength = length
 	}
 }
+
+// WithMultiPage enables or disables multi-page article stitching.
+func WithMultiPage(enabled bool) Option {
+	return func(c *Config) {
+		c.MultiPage = enabled
+	}
+}
+
+// WithMaxPages sets the maximum number of pages to merge.
+func WithMaxPages(pages int) Option {
+	return func(c *Config) {
+		c.MaxPages = pages
+	}
+}
+
+// WithMaxConcurrency sets the maximum number of concurrent batch extractions.
+func WithMaxConcurrency(n int) Option {
+	return func(c *Config) {
+		c.MaxConcurrency = n
+	}
+}
+
+// WithMaxPerHost sets the maximum number of concurrent batch extractions per host.
+func WithMaxPerHost(n int) Option {
+	return func(c *Config) {
+		c.MaxPerHost = n
+	}
+}
+
+// WithArticleCheck enables or disables the article pre-check in ExtractFromURL.
+func WithArticleCheck(enabled bool) Option {
+	return func(c *Config) {
+		c.ArticleCheck = enabled
+	}
+}
+
+// WithConfidenceModel sets the model used to compute Article.Confidence,
+// e.g. one fitted with FitConfidenceModel.
+func WithConfidenceModel(model *ConfidenceModel) Option {
+	return func(c *Config) {
+		c.ConfidenceModel = model
+	}
+}
+
+// WithAlternatives returns up to n runner-up content candidates with each
+// article.
+func WithAlternatives(n int) Option {
+	return func(c *Config) {
+		c.Alternatives = n
+	}
+}
+
+// WithMarkdown enables or disables Markdown output.
+func WithMarkdown(enabled bool) Option {
+	return func(c *Config) {
+		c.Markdown = enabled
+	}
+}
+
+// WithLinkFootnotes enables or disables link footnotes in the text content.
+func WithLinkFootnotes(enabled bool) Option {
+	return func(c *Config) {
+		c.LinkFootnotes = enabled
+	}
+}
+
+// WithBlocks enables or disables the typed block tree output. Iframes,
+// objects and embeds are removed during preprocessing, so embed blocks
+// need them preserved, e.g. WithPreserveTags("iframe").
+func WithBlocks(enabled bool) Option {
+	return func(c *Config) {
+		c.Blocks = enabled
+	}
+}
+
+// WithSummarySentences sets the number of sentences in the summary (0
+// disables it).
+func WithSummarySentences(n int) Option {
+	return func(c *Config) {
+		c.SummarySentences = n
+	}
+}
+
+// WithMaxKeywords sets the maximum number of key phrases (0 disables
+// them). Phrases are taken from the text only in languages with a
+// stopword list (en, de, fr, es, it, pt, nl, sv, da, pl, cs and ru);
+// Chinese, Japanese, Korean and the other detected languages, such as
+// tr, fi, hu, uk, bg, ro and vi, get only the meta tag keywords.
+func WithMaxKeywords(n int) Option {
+	return func(c *Config) {
+		c.MaxKeywords = n
+	}
+}
+
+// WithBlacklistKeywords adds class and id keywords of unlikely content.
+func WithBlacklistKeywords(keywords ...string) Option {
+	return func(c *Config) {
+		c.Blacklist = append(c.Blacklist, keywords...)
+	}
+}
+
+// WithoutBlacklistKeywords removes keywords from the blacklist.
+func WithoutBlacklistKeywords(keywords ...string) Option {
+	return func(c *Config) {
+		c.Blacklist = removeKeywords(c.Blacklist, keywords)
+	}
+}
+
+// WithBlacklist replaces the blacklist.
+func WithBlacklist(keywords ...string) Option {
+	return func(c *Config) {
+		c.Blacklist = keywords
+	}
+}
+
+// WithWhitelistKeywords adds class and id keywords of likely content.
+func WithWhitelistKeywords(keywords ...string) Option {
+	return func(c *Config) {
+		c.Whitelist = append(c.Whitelist, keywords...)
+	}
+}
+
+// WithoutWhitelistKeywords removes keywords from the whitelist.
+func WithoutWhitelistKeywords(keywords ...string) Option {
+	return func(c *Config) {
+		c.Whitelist = removeKeywords(c.Whitelist, keywords)
+	}
+}
+
+// WithWhitelist replaces the whitelist.
+func WithWhitelist(keywords ...string) Option {
+	return func(c *Config) {
+		c.Whitelist = keywords
+	}
+}
+
+// WithKeywordWeights sets the weights of whitelist and blacklist matches.
+func WithKeywordWeights(whitelist, blacklist int) Option {
+	return func(c *Config) {
+		c.WhitelistWeight = whitelist
+		c.BlacklistWeight = blacklist
+	}
+}
+
+// DefaultBlacklistKeywords returns the built-in blacklist keywords.
+func DefaultBlacklistKeywords() []string {
+	return keywords.DefaultBlacklist()
+}
+
+// DefaultWhitelistKeywords returns the built-in whitelist keywords.
+func DefaultWhitelistKeywords() []string {
+	return keywords.DefaultWhitelist()
+}
+
+// removeKeywords returns list without the given keywords, ignoring case.
+func removeKeywords(list, remove []string) []string {
+	var kept []string
+	for _, kw := range list {
+		removed := false
+		for _, r := range remove {
+			if strings.EqualFold(kw, r) {
+				removed = true
+				break
+			}
+		}
+		if !removed {
+			kept = append(kept, kw)
+		}
+	}
+	return kept
+}
+
+// WithWidgetClasses adds class names of widgets to remove.
+func WithWidgetClasses(classes ...string) Option {
+	return func(c *Config) {
+		c.WidgetClasses = append(c.WidgetClasses, classes...)
+	}
+}
+
+// WithPreserveTags adds tags that cleaning never removes. Preserved
+// iframes, objects and embeds keep their source and size attributes.
+func WithPreserveTags(tags ...string) Option {
+	return func(c *Config) {
+		c.PreserveTags = append(c.PreserveTags, tags...)
+	}
+}
+
+// WithAllowedAttributes keeps the attributes on content elements with the
+// tag ("*" for every tag).
+func WithAllowedAttributes(tag string, attrs ...string) Option {
+	return func(c *Config) {
+		if c.AllowedAttributes == nil {
+			c.AllowedAttributes = make(map[string][]string)
+		}
+		c.AllowedAttributes[tag] = append(c.AllowedAttributes[tag], attrs...)
+	}
+}
+
+// WithConfig replaces the configuration with a copy of cfg, e.g. one
+// returned by LoadConfig. Options after it modify the copy, never cfg. A
+// nil cfg leaves the configuration unchanged.
+func WithConfig(cfg *Config) Option {
+	return func(c *Config) {
+		if cfg == nil {
+			return
+		}
+
+		*c = *cfg
+		c.Blacklist = slices.Clone(cfg.Blacklist)
+		c.Whitelist = slices.Clone(cfg.Whitelist)
+		c.WidgetClasses = slices.Clone(cfg.WidgetClasses)
+		c.PreserveTags = slices.Clone(cfg.PreserveTags)
+		c.Pipeline = slices.Clone(cfg.Pipeline)
+		if cfg.AllowedAttributes != nil {
+			c.AllowedAttributes = make(map[string][]string, len(cfg.AllowedAttributes))
+			for tag, attrs := range cfg.AllowedAttributes {
+				c.AllowedAttributes[tag] = slices.Clone(attrs)
+			}
+		}
+		if cfg.Rules != nil {
+			c.Rules = cfg.Rules.clone()
+		}
+	}
+}
+
+// WithPipelineEdit adds an edit to the pipeline stages, e.g.
+// InsertAfter(StagePostprocess, stage) or Replace(StageScore, stage).
+func WithPipelineEdit(edit PipelineEdit) Option {
+	return func(c *Config) {
+		c.Pipeline = append(c.Pipeline, edit)
+	}
+}
+
+// WithStages sets a function that receives the pipeline stages and returns
+// them added to, removed from or reordered.
+func WithStages(fn func(stages []Stage) []Stage) Option {
+	return WithPipelineEdit(PipelineEdit(fn))
+}
+
+// WithHook runs a function at a point in the pipeline. Hooks at the same
+// point run in the order they are added, as stages named by HookName.
+// Unknown points are ignored.
+func WithHook(point HookPoint, hook Hook) Option {
+	return WithPipelineEdit(hookEdit(point, hook))
+}
+
+// WithSiteRules registers site-specific extraction rules. The rules are
+// added to a copy of the current registry, so a registry shared with
+// WithRuleRegistry is not modified.
+func WithSiteRules(rules ...*SiteRule) Option {
+	return func(c *Config) {
+		c.Rules = c.Rules.clone()
+		for _, rule := range rules {
+			c.Rules.Register(rule)
+		}
+	}
+}
+
+// WithRuleRegistry sets the registry of site-specific extraction rules.
+// The registry may be shared between extractors; rules registered in it
+// later apply to all of them.
+func WithRuleRegistry(registry *RuleRegistry) Option {
+	return func(c *Config) {
+		c.Rules = registry
+	}
+}
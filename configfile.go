@@ -0,0 +1,410 @@
+package extractor
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/andybalholm/cascadia"
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration file formats.
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+)
+
+// fileConfig is the configuration file layout. Keys are the Config field
+// names in camelCase; absent keys keep their defaults. The keyword lists
+// are replaced by "blacklist" and "whitelist", then edited by the
+// remove and add keys.
+type fileConfig struct {
+	MinContentLength   int                 `json:"minContentLength"`
+	MinParagraphLength int                 `json:"minParagraphLength"`
+	Debug              bool                `json:"debug"`
+	HTTPTimeout        any                 `json:"httpTimeout"`
+	ExtractTimeout     any                 `json:"extractTimeout"`
+	UserAgent          string              `json:"userAgent"`
+	MaxContentLength   int                 `json:"maxContentLength"`
+	MultiPage          bool                `json:"multiPage"`
+	MaxPages           int                 `json:"maxPages"`
+	MaxConcurrency     int                 `json:"maxConcurrency"`
+	MaxPerHost         int                 `json:"maxPerHost"`
+	ArticleCheck       bool                `json:"articleCheck"`
+	ConfidenceModel    string              `json:"confidenceModel"`
+	Alternatives       int                 `json:"alternatives"`
+	Markdown           bool                `json:"markdown"`
+	LinkFootnotes      bool                `json:"linkFootnotes"`
+	Blocks             bool                `json:"blocks"`
+	SummarySentences   int                 `json:"summarySentences"`
+	MaxKeywords        int                 `json:"maxKeywords"`
+	Blacklist          []string            `json:"blacklist"`
+	Whitelist          []string            `json:"whitelist"`
+	BlacklistKeywords  []string            `json:"blacklistKeywords"`
+	WhitelistKeywords  []string            `json:"whitelistKeywords"`
+	RemoveBlacklist    []string            `json:"removeBlacklistKeywords"`
+	RemoveWhitelist    []string            `json:"removeWhitelistKeywords"`
+	BlacklistWeight    int                 `json:"blacklistWeight"`
+	WhitelistWeight    int                 `json:"whitelistWeight"`
+	WidgetClasses      []string            `json:"widgetClasses"`
+	PreserveTags       []string            `json:"preserveTags"`
+	AllowedAttributes  map[string][]string `json:"allowedAttributes"`
+	Rules              []fileRule          `json:"rules"`
+}
+
+// fileRule is a SiteRule in a configuration file.
+type fileRule struct {
+	Domain           string          `json:"domain"`
+	SupportedDomains []string        `json:"supportedDomains"`
+	Title            []string        `json:"title"`
+	Author           []string        `json:"author"`
+	Date             []string        `json:"date"`
+	Content          []string        `json:"content"`
+	LeadImage        []string        `json:"leadImage"`
+	NextPage         []string        `json:"nextPage"`
+	Clean            []string        `json:"clean"`
+	Transforms       []fileTransform `json:"transforms"`
+}
+
+// fileTransform is a TransformRule in a configuration file. Exactly one of
+// Rename and Unwrap must be set.
+type fileTransform struct {
+	Selector string `json:"selector"`
+	Rename   string `json:"rename"`
+	Unwrap   bool   `json:"unwrap"`
+}
+
+// LoadConfig reads a configuration file and returns the default
+// configuration updated with the file's settings. The format is chosen by
+// the file extension: .json, .yaml or .yml. A confidence model path in the
+// file is resolved relative to the file's directory. Invalid files return
+// a *ConfigError naming the field.
+func LoadConfig(path string) (*Config, error) {
+	var format string
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		format = FormatJSON
+	case ".yaml", ".yml":
+		format = FormatYAML
+	default:
+		return nil, &ConfigError{File: path, Err: fmt.Errorf("unknown file extension %q", filepath.Ext(path))}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := parseConfig(data, format, filepath.Dir(path))
+	if err != nil {
+		var configErr *ConfigError
+		if errors.As(err, &configErr) {
+			configErr.File = path
+		}
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ParseConfig parses configuration data in the given format (FormatJSON or
+// FormatYAML) like LoadConfig. A confidence model path is resolved
+// relative to the working directory.
+func ParseConfig(data []byte, format string) (*Config, error) {
+	return parseConfig(data, format, "")
+}
+
+// parseConfig decodes and validates configuration data.
+func parseConfig(data []byte, format, dir string) (*Config, error) {
+	if format == FormatYAML {
+		// YAML is converted to JSON so that both formats share the
+		// strict decoding below
+		var value any
+		err := yaml.Unmarshal(data, &value)
+		if err != nil {
+			return nil, &ConfigError{Err: err}
+		}
+		if data, err = json.Marshal(value); err != nil {
+			return nil, &ConfigError{Err: err}
+		}
+	} else if format != FormatJSON {
+		return nil, &ConfigError{Err: fmt.Errorf("unknown format %q", format)}
+	}
+
+	defaults := DefaultConfig()
+	file := fileConfig{
+		MinContentLength:   defaults.MinContentLength,
+		MinParagraphLength: defaults.MinParagraphLength,
+		UserAgent:          defaults.UserAgent,
+		MaxContentLength:   defaults.MaxContentLength,
+		MaxPages:           defaults.MaxPages,
+		MaxConcurrency:     defaults.MaxConcurrency,
+		MaxPerHost:         defaults.MaxPerHost,
+		SummarySentences:   defaults.SummarySentences,
+		MaxKeywords:        defaults.MaxKeywords,
+		BlacklistWeight:    defaults.BlacklistWeight,
+		WhitelistWeight:    defaults.WhitelistWeight,
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, decodeError(err, data)
+	}
+
+	cfg, err := file.config(defaults, dir)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// indexPattern matches the slice indexes in JSON field paths ("rules.0").
+var indexPattern = regexp.MustCompile(`\.(\d+)`)
+
+// decodeError converts a JSON decoding error into a *ConfigError.
+func decodeError(err error, data []byte) error {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &typeErr):
+		return &ConfigError{Field: indexPattern.ReplaceAllString(typeErr.Field, "[$1]"), Err: fmt.Errorf("expected %s, got %s", typeErr.Type, typeErr.Value)}
+	case errors.As(err, &syntaxErr):
+		line := 1 + bytes.Count(data[:syntaxErr.Offset], []byte("\n"))
+		return &ConfigError{Err: fmt.Errorf("line %d: %v", line, err)}
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return &ConfigError{Field: field, Err: errors.New("unknown field")}
+	}
+	return &ConfigError{Err: err}
+}
+
+// config validates the file settings and builds a Config.
+func (f *fileConfig) config(defaults *Config, dir string) (*Config, error) {
+	cfg := defaults
+
+	for _, field := range []struct {
+		name  string
+		value int
+		min   int
+	}{
+		{"minContentLength", f.MinContentLength, 0},
+		{"minParagraphLength", f.MinParagraphLength, 0},
+		{"maxContentLength", f.MaxContentLength, 1},
+		{"maxPages", f.MaxPages, 1},
+		{"maxConcurrency", f.MaxConcurrency, 0},
+		{"maxPerHost", f.MaxPerHost, 0},
+		{"alternatives", f.Alternatives, 0},
+		{"summarySentences", f.SummarySentences, 0},
+		{"maxKeywords", f.MaxKeywords, 0},
+		{"blacklistWeight", f.BlacklistWeight, 0},
+		{"whitelistWeight", f.WhitelistWeight, 0},
+	} {
+		if field.value < field.min {
+			return nil, &ConfigError{Field: field.name, Err: fmt.Errorf("must be at least %d, got %d", field.min, field.value)}
+		}
+	}
+
+	timeout, err := parseDuration(f.HTTPTimeout, defaults.HTTPTimeout)
+	if err == nil && timeout == 0 {
+		err = errors.New("must be positive")
+	}
+	if err != nil {
+		return nil, &ConfigError{Field: "httpTimeout", Err: err}
+	}
+	extractTimeout, err := parseDuration(f.ExtractTimeout, defaults.ExtractTimeout)
+	if err != nil {
+		return nil, &ConfigError{Field: "extractTimeout", Err: err}
+	}
+
+	cfg.MinContentLength = f.MinContentLength
+	cfg.MinParagraphLength = f.MinParagraphLength
+	cfg.Debug = f.Debug
+	cfg.HTTPTimeout = timeout
+	cfg.ExtractTimeout = extractTimeout
+	cfg.UserAgent = f.UserAgent
+	cfg.MaxContentLength = f.MaxContentLength
+	cfg.MultiPage = f.MultiPage
+	cfg.MaxPages = f.MaxPages
+	cfg.MaxConcurrency = f.MaxConcurrency
+	cfg.MaxPerHost = f.MaxPerHost
+	cfg.ArticleCheck = f.ArticleCheck
+	cfg.Alternatives = f.Alternatives
+	cfg.Markdown = f.Markdown
+	cfg.LinkFootnotes = f.LinkFootnotes
+	cfg.Blocks = f.Blocks
+	cfg.SummarySentences = f.SummarySentences
+	cfg.MaxKeywords = f.MaxKeywords
+	cfg.BlacklistWeight = f.BlacklistWeight
+	cfg.WhitelistWeight = f.WhitelistWeight
+
+	if f.ConfidenceModel != "" {
+		path := f.ConfidenceModel
+		if dir != "" && !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		model, err := LoadConfidenceModel(path)
+		if err != nil {
+			return nil, &ConfigError{Field: "confidenceModel", Err: err}
+		}
+		cfg.ConfidenceModel = model
+	}
+
+	lists := []struct {
+		name   string
+		values []string
+	}{
+		{"blacklist", f.Blacklist},
+		{"whitelist", f.Whitelist},
+		{"blacklistKeywords", f.BlacklistKeywords},
+		{"whitelistKeywords", f.WhitelistKeywords},
+		{"removeBlacklistKeywords", f.RemoveBlacklist},
+		{"removeWhitelistKeywords", f.RemoveWhitelist},
+		{"widgetClasses", f.WidgetClasses},
+		{"preserveTags", f.PreserveTags},
+	}
+	for _, list := range lists {
+		for i, value := range list.values {
+			if strings.TrimSpace(value) == "" {
+				return nil, &ConfigError{Field: fmt.Sprintf("%s[%d]", list.name, i), Err: errors.New("must not be empty")}
+			}
+		}
+	}
+
+	if f.Blacklist != nil {
+		cfg.Blacklist = f.Blacklist
+	}
+	if f.Whitelist != nil {
+		cfg.Whitelist = f.Whitelist
+	}
+	cfg.Blacklist = append(removeKeywords(cfg.Blacklist, f.RemoveBlacklist), f.BlacklistKeywords...)
+	cfg.Whitelist = append(removeKeywords(cfg.Whitelist, f.RemoveWhitelist), f.WhitelistKeywords...)
+	cfg.WidgetClasses = f.WidgetClasses
+	cfg.PreserveTags = f.PreserveTags
+
+	for tag, attrs := range f.AllowedAttributes {
+		for i, attr := range attrs {
+			if strings.TrimSpace(attr) == "" {
+				return nil, &ConfigError{Field: fmt.Sprintf("allowedAttributes.%s[%d]", tag, i), Err: errors.New("must not be empty")}
+			}
+		}
+	}
+	cfg.AllowedAttributes = f.AllowedAttributes
+
+	if len(f.Rules) > 0 {
+		cfg.Rules = NewRuleRegistry()
+		for i, r := range f.Rules {
+			rule, err := r.siteRule(fmt.Sprintf("rules[%d]", i))
+			if err != nil {
+				return nil, err
+			}
+			cfg.Rules.Register(rule)
+		}
+	}
+
+	return cfg, nil
+}
+
+// parseDuration parses a duration string such as "30s" or a number of
+// seconds. Negative durations are rejected.
+func parseDuration(value any, fallback time.Duration) (time.Duration, error) {
+	var timeout time.Duration
+	switch v := value.(type) {
+	case nil:
+		return fallback, nil
+	case string:
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", v)
+		}
+		timeout = d
+	case float64:
+		timeout = time.Duration(v * float64(time.Second))
+	default:
+		return 0, fmt.Errorf("expected a duration such as \"30s\" or a number of seconds")
+	}
+
+	if timeout < 0 {
+		return 0, errors.New("must not be negative")
+	}
+	return timeout, nil
+}
+
+// siteRule validates a file rule and converts it into a SiteRule. Field
+// names in errors are prefixed with path.
+func (r *fileRule) siteRule(path string) (*SiteRule, error) {
+	if strings.TrimSpace(r.Domain) == "" {
+		return nil, &ConfigError{Field: path + ".domain", Err: errors.New("is required")}
+	}
+
+	selectors := []struct {
+		name  string
+		value []string
+	}{
+		{"title", r.Title},
+		{"author", r.Author},
+		{"date", r.Date},
+		{"content", r.Content},
+		{"leadImage", r.LeadImage},
+		{"nextPage", r.NextPage},
+		{"clean", r.Clean},
+	}
+	for _, field := range selectors {
+		for i, selector := range field.value {
+			if err := validateSelector(selector); err != nil {
+				return nil, &ConfigError{Field: fmt.Sprintf("%s.%s[%d]", path, field.name, i), Err: err}
+			}
+		}
+	}
+
+	rule := &SiteRule{
+		Domain:           r.Domain,
+		SupportedDomains: r.SupportedDomains,
+		Title:            r.Title,
+		Author:           r.Author,
+		Date:             r.Date,
+		Content:          r.Content,
+		LeadImage:        r.LeadImage,
+		NextPage:         r.NextPage,
+		Clean:            r.Clean,
+	}
+
+	for i, t := range r.Transforms {
+		field := fmt.Sprintf("%s.transforms[%d]", path, i)
+		if err := validateSelector(t.Selector); err != nil {
+			return nil, &ConfigError{Field: field + ".selector", Err: err}
+		}
+
+		var transform Transform
+		switch {
+		case t.Rename != "" && t.Unwrap:
+			return nil, &ConfigError{Field: field, Err: errors.New("rename and unwrap are mutually exclusive")}
+		case t.Rename != "":
+			transform = RenameTag(t.Rename)
+		case t.Unwrap:
+			transform = Unwrap()
+		default:
+			return nil, &ConfigError{Field: field, Err: errors.New("one of rename or unwrap is required")}
+		}
+		rule.Transforms = append(rule.Transforms, TransformRule{Selector: t.Selector, Transform: transform})
+	}
+
+	return rule, nil
+}
+
+// validateSelector checks that a CSS selector is not empty and parses.
+func validateSelector(selector string) error {
+	if strings.TrimSpace(selector) == "" {
+		return errors.New("selector must not be empty")
+	}
+	if _, err := cascadia.ParseGroup(selector); err != nil {
+		return fmt.Errorf("invalid selector %q: %v", selector, err)
+	}
+	return nil
+}
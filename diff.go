@@ -0,0 +1,443 @@
+package extractor
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/LeadNewswire/article-extractor/internal/diff"
+	"github.com/LeadNewswire/article-extractor/internal/render"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ChangeType is the kind of a paragraph change.
+type ChangeType string
+
+// Paragraph change types.
+const (
+	ChangeInsert ChangeType = "insert"
+	ChangeDelete ChangeType = "delete"
+	ChangeEdit   ChangeType = "edit"
+)
+
+// Diffed metadata fields.
+const (
+	FieldTitle       = "title"
+	FieldAuthor      = "author"
+	FieldPublishedAt = "publishedAt"
+	FieldLeadImage   = "leadImage"
+)
+
+// editSimilarity is the minimum word similarity for a deleted and an
+// inserted paragraph to be reported as an edit of one paragraph.
+const editSimilarity = 0.5
+
+// ArticleDiff is the difference between two revisions of an article.
+type ArticleDiff struct {
+	// Fields lists the changed metadata fields
+	Fields []FieldChange `json:"fields,omitempty"`
+
+	// Paragraphs lists the inserted, deleted and edited paragraphs, in
+	// document order
+	Paragraphs []ParagraphChange `json:"paragraphs,omitempty"`
+
+	// Similarity is the word similarity (0-1) of the two bodies, ignoring
+	// whitespace
+	Similarity float64 `json:"similarity"`
+
+	// ChangedWords is the number of words deleted or inserted by the
+	// changes that are not cosmetic
+	ChangedWords int `json:"changedWords"`
+}
+
+// FieldChange is a changed metadata field.
+type FieldChange struct {
+	// Field is the name of the field, e.g. FieldTitle
+	Field string `json:"field"`
+
+	// Old is the old value, with dates formatted as RFC 3339
+	Old string `json:"old"`
+
+	// New is the new value
+	New string `json:"new"`
+
+	// Cosmetic is true if the values only differ in whitespace or
+	// tracking parameters
+	Cosmetic bool `json:"cosmetic,omitempty"`
+}
+
+// ParagraphChange is an inserted, deleted or edited paragraph.
+type ParagraphChange struct {
+	// Type is the kind of change
+	Type ChangeType `json:"type"`
+
+	// OldIndex is the paragraph's index in the old body, or -1 if inserted
+	OldIndex int `json:"oldIndex"`
+
+	// NewIndex is the paragraph's index in the new body, or -1 if deleted
+	NewIndex int `json:"newIndex"`
+
+	// Old is the old paragraph text
+	Old string `json:"old,omitempty"`
+
+	// New is the new paragraph text
+	New string `json:"new,omitempty"`
+
+	// Similarity is the word similarity (0-1) of an edited paragraph
+	Similarity float64 `json:"similarity,omitempty"`
+
+	// ChangedWords is the number of words deleted or inserted
+	ChangedWords int `json:"changedWords"`
+
+	// Cosmetic is true if an edit only changes whitespace or tracking
+	// parameters of links
+	Cosmetic bool `json:"cosmetic,omitempty"`
+}
+
+// MaterialThresholds decide whether an ArticleDiff is a material change.
+// A zero threshold is ignored.
+type MaterialThresholds struct {
+	// MinChangedWords is the number of changed words at which a change is
+	// material
+	MinChangedWords int
+
+	// MinSimilarity is the body similarity below which a change is
+	// material
+	MinSimilarity float64
+
+	// Fields lists the metadata fields whose non-cosmetic change is
+	// material
+	Fields []string
+}
+
+// DefaultMaterialThresholds returns thresholds treating a change of three
+// or more words, or of the title, author or publication date, as material.
+func DefaultMaterialThresholds() MaterialThresholds {
+	return MaterialThresholds{
+		MinChangedWords: 3,
+		MinSimilarity:   0.97,
+		Fields:          []string{FieldTitle, FieldAuthor, FieldPublishedAt},
+	}
+}
+
+// Changed reports whether the revisions differ at all, including
+// cosmetic changes.
+func (d *ArticleDiff) Changed() bool {
+	return len(d.Fields) > 0 || len(d.Paragraphs) > 0
+}
+
+// IsMaterial reports whether the revisions differ by more than the
+// thresholds allow. Cosmetic changes are never material.
+func (d *ArticleDiff) IsMaterial(t MaterialThresholds) bool {
+	for _, change := range d.Fields {
+		if change.Cosmetic {
+			continue
+		}
+		for _, field := range t.Fields {
+			if change.Field == field {
+				return true
+			}
+		}
+	}
+
+	if t.MinChangedWords > 0 && d.ChangedWords >= t.MinChangedWords {
+		return true
+	}
+	return t.MinSimilarity > 0 && d.Similarity < t.MinSimilarity
+}
+
+// Diff compares two revisions of an article. Paragraphs are compared by
+// their text and link targets; changes to whitespace and to tracking
+// parameters such as utm_source are marked cosmetic.
+func Diff(before, after *Article) *ArticleDiff {
+	d := &ArticleDiff{Fields: diffFields(before, after)}
+
+	oldParas, newParas := articleParagraphs(before), articleParagraphs(after)
+	d.Similarity = diff.Similarity(paragraphWords(oldParas), paragraphWords(newParas))
+
+	oldKeys := make([]string, len(oldParas))
+	for i, p := range oldParas {
+		oldKeys[i] = p.key
+	}
+	newKeys := make([]string, len(newParas))
+	for i, p := range newParas {
+		newKeys[i] = p.key
+	}
+
+	// Collect the deletions and insertions between equal paragraphs, then
+	// pair them up as edits
+	var deleted, inserted []int
+	flush := func() {
+		d.Paragraphs = append(d.Paragraphs, pairChanges(oldParas, newParas, deleted, inserted)...)
+		deleted, inserted = deleted[:0], inserted[:0]
+	}
+	for _, op := range diff.Compare(oldKeys, newKeys) {
+		switch op.Kind {
+		case diff.Delete:
+			deleted = append(deleted, op.A)
+		case diff.Insert:
+			inserted = append(inserted, op.B)
+		case diff.Equal:
+			flush()
+			if oldParas[op.A].raw != newParas[op.B].raw {
+				d.Paragraphs = append(d.Paragraphs, ParagraphChange{
+					Type:       ChangeEdit,
+					OldIndex:   op.A,
+					NewIndex:   op.B,
+					Old:        oldParas[op.A].text,
+					New:        newParas[op.B].text,
+					Similarity: 1,
+					Cosmetic:   true,
+				})
+			}
+		}
+	}
+	flush()
+
+	for _, change := range d.Paragraphs {
+		if !change.Cosmetic {
+			d.ChangedWords += change.ChangedWords
+		}
+	}
+	return d
+}
+
+// pairChanges pairs each deleted paragraph with the first following
+// inserted paragraph similar enough to be an edit of it. Unpaired
+// paragraphs are reported as deletions and insertions.
+func pairChanges(oldParas, newParas []paragraph, deleted, inserted []int) []ParagraphChange {
+	var changes []ParagraphChange
+	next := 0 // first inserted paragraph not yet reported
+
+	for _, a := range deleted {
+		oldWords := strings.Fields(oldParas[a].key)
+
+		paired := false
+		for k := next; k < len(inserted); k++ {
+			b := inserted[k]
+			newWords := strings.Fields(newParas[b].key)
+			similarity := diff.Similarity(oldWords, newWords)
+			if similarity < editSimilarity {
+				continue
+			}
+
+			for _, skipped := range inserted[next:k] {
+				changes = append(changes, insertChange(newParas, skipped))
+			}
+			changes = append(changes, ParagraphChange{
+				Type:         ChangeEdit,
+				OldIndex:     a,
+				NewIndex:     b,
+				Old:          oldParas[a].text,
+				New:          newParas[b].text,
+				Similarity:   similarity,
+				ChangedWords: diff.Changed(oldWords, newWords),
+			})
+			next = k + 1
+			paired = true
+			break
+		}
+
+		if !paired {
+			changes = append(changes, ParagraphChange{
+				Type:         ChangeDelete,
+				OldIndex:     a,
+				NewIndex:     -1,
+				Old:          oldParas[a].text,
+				ChangedWords: len(oldWords),
+			})
+		}
+	}
+
+	for _, b := range inserted[next:] {
+		changes = append(changes, insertChange(newParas, b))
+	}
+	return changes
+}
+
+// insertChange reports an inserted paragraph.
+func insertChange(newParas []paragraph, b int) ParagraphChange {
+	return ParagraphChange{
+		Type:         ChangeInsert,
+		OldIndex:     -1,
+		NewIndex:     b,
+		New:          newParas[b].text,
+		ChangedWords: len(strings.Fields(newParas[b].key)),
+	}
+}
+
+// diffFields compares the metadata fields of two articles.
+func diffFields(before, after *Article) []FieldChange {
+	var changes []FieldChange
+	compare := func(field, a, b string, normalize func(string) string) {
+		if a != b {
+			changes = append(changes, FieldChange{
+				Field:    field,
+				Old:      a,
+				New:      b,
+				Cosmetic: normalize(a) == normalize(b),
+			})
+		}
+	}
+
+	compare(FieldTitle, before.Title, after.Title, normalizeSpace)
+	compare(FieldAuthor, before.Author, after.Author, normalizeSpace)
+	compare(FieldPublishedAt, formatTime(before.PublishedAt), formatTime(after.PublishedAt), normalizeSpace)
+	compare(FieldLeadImage, imageURL(before.LeadImage), imageURL(after.LeadImage), stripTracking)
+	return changes
+}
+
+// formatTime formats a date as RFC 3339, or "" if it is nil.
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// imageURL returns the URL of an image, or "" if it is nil.
+func imageURL(img *Image) string {
+	if img == nil {
+		return ""
+	}
+	return img.URL
+}
+
+// paragraph is a paragraph of an article body. key holds the normalized
+// text and link targets, which are compared to find changes; raw holds
+// them unnormalized, to tell cosmetic edits from no change.
+type paragraph struct {
+	text string
+	key  string
+	raw  string
+}
+
+// newParagraph creates a paragraph from its text and link targets.
+func newParagraph(text string, links []string) paragraph {
+	p := paragraph{text: text, key: normalizeSpace(text), raw: text}
+	for _, link := range links {
+		p.key += " <" + stripTracking(link) + ">"
+		p.raw += " <" + link + ">"
+	}
+	return p
+}
+
+// articleParagraphs splits an article body into paragraphs: the text
+// blocks of the content, or the blank-line separated blocks of the text
+// content if there is no HTML content.
+func articleParagraphs(article *Article) []paragraph {
+	var paras []paragraph
+
+	if strings.TrimSpace(article.Content) == "" {
+		for _, block := range strings.Split(article.TextContent, "\n\n") {
+			if text := strings.TrimSpace(block); text != "" {
+				paras = append(paras, newParagraph(text, nil))
+			}
+		}
+		return paras
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(article.Content))
+	if err != nil {
+		return nil
+	}
+
+	var walk func(blocks []render.Block)
+	walk = func(blocks []render.Block) {
+		for _, block := range blocks {
+			switch block.Type {
+			case render.BlockList:
+				for _, item := range block.Items {
+					walk(item)
+				}
+			case render.BlockQuote:
+				walk(block.Children)
+			case render.BlockCode:
+				paras = append(paras, newParagraph(block.Text, nil))
+			case render.BlockImage:
+				text, links := inlineText(block.Caption)
+				paras = append(paras, newParagraph(text, append([]string{block.URL}, links...)))
+			case render.BlockEmbed:
+				paras = append(paras, newParagraph("", []string{block.URL}))
+			case render.BlockTable:
+				for _, row := range block.Rows {
+					var cells []string
+					var links []string
+					for _, cell := range row {
+						text, cellLinks := inlineText(cell.Inlines)
+						cells = append(cells, text)
+						links = append(links, cellLinks...)
+					}
+					paras = append(paras, newParagraph(strings.Join(cells, " | "), links))
+				}
+			default:
+				if text, links := inlineText(block.Inlines); text != "" || len(links) > 0 {
+					paras = append(paras, newParagraph(text, links))
+				}
+			}
+		}
+	}
+	walk(render.Blocks(doc.Find("body")))
+	return paras
+}
+
+// inlineText returns the text of inline runs and the targets of their
+// links.
+func inlineText(inlines []render.Inline) (string, []string) {
+	var text strings.Builder
+	var links []string
+	for _, inline := range inlines {
+		text.WriteString(inline.Text)
+		if inline.Href != "" && (len(links) == 0 || links[len(links)-1] != inline.Href) {
+			links = append(links, inline.Href)
+		}
+	}
+	return strings.TrimSpace(text.String()), links
+}
+
+// paragraphWords returns the normalized words of paragraphs.
+func paragraphWords(paras []paragraph) []string {
+	var words []string
+	for _, p := range paras {
+		words = append(words, strings.Fields(p.text)...)
+	}
+	return words
+}
+
+// normalizeSpace collapses runs of whitespace to a single space.
+func normalizeSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// trackingParams are query parameters that only track visits.
+var trackingParams = map[string]bool{
+	"fbclid":  true,
+	"gclid":   true,
+	"dclid":   true,
+	"msclkid": true,
+	"yclid":   true,
+	"igshid":  true,
+	"mc_cid":  true,
+	"mc_eid":  true,
+	"_ga":     true,
+	"_gl":     true,
+	"ref_src": true,
+}
+
+// stripTracking removes tracking parameters, such as utm_source, from a
+// URL's query.
+func stripTracking(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || u.RawQuery == "" {
+		return strings.TrimSpace(rawURL)
+	}
+
+	query := u.Query()
+	for name := range query {
+		if trackingParams[strings.ToLower(name)] || strings.HasPrefix(strings.ToLower(name), "utm_") {
+			query.Del(name)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
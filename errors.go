@@ -1,6 +1,16 @@
 package extractor
 
-import "errors"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/LeadNewswire/article-extractor/internal/fetcher"
+)
 
 var (
 	// ErrNoContent is returned when no content could be extracted.
@@ -21,10 +31,23 @@ var (
 	// ErrContentTooLarge is returned when the content exceeds the maximum size.
 	ErrContentTooLarge = errors.New("content exceeds maximum size")
 
+	// ErrNotArticle is returned when the article check is enabled and the
+	// page does not look like an article.
+	ErrNotArticle = errors.New("page is not an article")
+
 	// ErrTimeout is returned when the operation times out.
 	ErrTimeout = errors.New("operation timed out")
+
+	// ErrInvalidConfig is returned when a configuration file is invalid.
+	ErrInvalidConfig = errors.New("invalid configuration")
 )
 
+// timeoutError returns ErrTimeout wrapped with the reason the context is
+// done, so that errors.Is matches both.
+func timeoutError(ctx context.Context) error {
+	return fmt.Errorf("%w: %w", ErrTimeout, ctx.Err())
+}
+
 // ExtractionError wraps an error with additional context.
 type ExtractionError struct {
 	Op  string // Operation that failed
@@ -51,3 +74,172 @@ func NewExtractionError(op string, url string, err error) *ExtractionError {
 		Err: err,
 	}
 }
+
+// ConfigError reports an invalid configuration field. It matches
+// ErrInvalidConfig with errors.Is.
+type ConfigError struct {
+	File  string // Configuration file (if applicable)
+	Field string // Path of the invalid field, e.g. "rules[0].domain"
+	Err   error  // Underlying error
+}
+
+func (e *ConfigError) Error() string {
+	msg := e.Err.Error()
+	if e.Field != "" {
+		msg = e.Field + ": " + msg
+	}
+	if e.File != "" {
+		msg = e.File + ": " + msg
+	}
+	return "config: " + msg
+}
+
+func (e *ConfigError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrInvalidConfig.
+func (e *ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// HTTPError describes a failed fetch: either a response with a status other
+// than 200 OK, or a transport failure such as a DNS error, refused
+// connection or timeout, in which case StatusCode is 0 and Err is set.
+// Transport failures include those while the body streams, such as a reset
+// connection or a body cut short with io.ErrUnexpectedEOF. It
+// matches ErrHTTPRequest with errors.Is, and ErrTimeout when the request
+// timed out.
+type HTTPError struct {
+	StatusCode int           // Response status code, or 0 if there was no response
+	URL        string        // Final URL after redirects, or the requested URL
+	RetryAfter time.Duration // Delay requested by the Retry-After header, or 0
+	Header     http.Header   // Response headers, or nil if there was no response
+	Err        error         // Underlying transport error, or nil
+}
+
+func (e *HTTPError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether target is ErrHTTPRequest, or ErrTimeout for a request
+// that timed out.
+func (e *HTTPError) Is(target error) bool {
+	switch target {
+	case ErrHTTPRequest:
+		return true
+	case ErrTimeout:
+		return e.Timeout()
+	}
+	return false
+}
+
+// Timeout reports whether the request timed out before a response arrived.
+func (e *HTTPError) Timeout() bool {
+	if e.Err == nil {
+		return false
+	}
+	return errors.Is(e.Err, context.DeadlineExceeded) || isNetTimeout(e.Err)
+}
+
+// isNetTimeout reports whether err is a network error that timed out.
+func isNetTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// newHTTPError converts an error returned by the fetcher to an *HTTPError.
+func newHTTPError(url string, err error) *HTTPError {
+	var status *fetcher.StatusError
+	if errors.As(err, &status) {
+		return &HTTPError{
+			StatusCode: status.StatusCode,
+			URL:        status.URL,
+			RetryAfter: status.RetryAfter,
+			Header:     status.Header,
+		}
+	}
+	return &HTTPError{URL: url, Err: err}
+}
+
+// IsNotFound reports whether err is an HTTP 404 Not Found or 410 Gone
+// response.
+func IsNotFound(err error) bool {
+	status := statusCode(err)
+	return status == http.StatusNotFound || status == http.StatusGone
+}
+
+// IsBlocked reports whether err is a response refusing access: 401
+// Unauthorized, 403 Forbidden, 407 Proxy Authentication Required, 429 Too
+// Many Requests or 451 Unavailable For Legal Reasons. A 429 response is
+// also retryable after its RetryAfter delay.
+func IsBlocked(err error) bool {
+	switch statusCode(err) {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusProxyAuthRequired,
+		http.StatusTooManyRequests, http.StatusUnavailableForLegalReasons:
+		return true
+	}
+	return false
+}
+
+// IsTimeout reports whether err is a timeout, either of the HTTP request or
+// of the extraction.
+func IsTimeout(err error) bool {
+	return errors.Is(err, ErrTimeout) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// IsRetryable reports whether a failed fetch may succeed if retried later:
+// a 408, 425, 429, 500, 502, 503 or 504 response, a request timeout, a
+// body cut short, or a network failure other than a host that does not
+// exist. Cancelled requests and extraction failures are not retryable.
+func IsRetryable(err error) bool {
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		return false
+	}
+
+	switch httpErr.StatusCode {
+	case 0:
+		// Transport failure; classified below
+	case http.StatusRequestTimeout, http.StatusTooEarly, http.StatusTooManyRequests,
+		http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+
+	if httpErr.Timeout() {
+		return true
+	}
+	if errors.Is(httpErr.Err, context.Canceled) {
+		return false
+	}
+	if errors.Is(httpErr.Err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(httpErr.Err, &dnsErr) {
+		return !dnsErr.IsNotFound
+	}
+
+	var opErr *net.OpError
+	return errors.As(httpErr.Err, &opErr)
+}
+
+// statusCode returns the status code of an *HTTPError in err's chain, or 0.
+func statusCode(err error) int {
+	var httpErr *HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode
+	}
+	return 0
+}
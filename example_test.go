@@ -41,7 +41,7 @@ func Example() {
 	// Title: Sample Article
 	// Author: John Doe
 	// Word Count: 61
-	// Confidence: 0.30
+	// Confidence: 0.96
 }
 
 func ExampleExtractor_ExtractWithURL() {
@@ -2,20 +2,35 @@ package extractor
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"strings"
+	"sync/atomic"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/LeadNewswire/article-extractor/internal/classify"
 	"github.com/LeadNewswire/article-extractor/internal/cleaner"
-	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/LeadNewswire/article-extractor/internal/confidence"
 	"github.com/LeadNewswire/article-extractor/internal/fetcher"
-	"github.com/LeadNewswire/article-extractor/internal/metadata"
+	"github.com/LeadNewswire/article-extractor/internal/images"
+	"github.com/LeadNewswire/article-extractor/internal/keywords"
 	"github.com/LeadNewswire/article-extractor/internal/scorer"
 )
 
 // Extractor is the main article extraction engine.
 type Extractor struct {
-	config *Config
-	client *fetcher.Client
+	config   *Config
+	client   *fetcher.Client
+	stages   []Stage
+	cleaning cleaner.Options
+
+	// path and opts rebuild the extractor on Reload
+	path string
+	opts []Option
+
+	// reloaded is the extractor built by the latest Reload
+	reloaded atomic.Pointer[Extractor]
 }
 
 // New creates a new Extractor with the given options.
@@ -32,29 +47,121 @@ func New(opts ...Option) *Extractor {
 	)
 
 	return &Extractor{
-		config: config,
-		client: client,
+		config:   config,
+		client:   client,
+		stages:   buildPipeline(config.Pipeline),
+		cleaning: cleanerOptions(config),
+	}
+}
+
+// NewFromFile creates an Extractor configured from a file (see LoadConfig).
+// The options are applied after the file's settings, here and on every
+// Reload; use them for settings a file cannot hold, such as hooks.
+func NewFromFile(path string, opts ...Option) (*Extractor, error) {
+	cfg, err := LoadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+
+	e := New(append([]Option{WithConfig(cfg)}, opts...)...)
+	e.path = path
+	e.opts = opts
+	return e, nil
+}
+
+// Reload re-reads the configuration file of an Extractor created with
+// NewFromFile. Extractions already running finish with the previous
+// configuration; later ones use the new one. If the file is invalid, the
+// error is returned and the previous configuration stays in use.
+func (e *Extractor) Reload() error {
+	if e.path == "" {
+		return &ConfigError{Err: errors.New("extractor was not created from a file")}
 	}
+
+	cfg, err := LoadConfig(e.path)
+	if err != nil {
+		return err
+	}
+
+	e.reloaded.Store(New(append([]Option{WithConfig(cfg)}, e.opts...)...))
+	return nil
+}
+
+// current returns the extractor to run a call with: the latest reload,
+// or e itself.
+func (e *Extractor) current() *Extractor {
+	if reloaded := e.reloaded.Load(); reloaded != nil {
+		return reloaded
+	}
+	return e
+}
+
+// cleanerOptions builds the cleaning options of a configuration.
+func cleanerOptions(config *Config) cleaner.Options {
+	opts := cleaner.Options{
+		Keywords:          keywords.NewSet(config.Blacklist, config.Whitelist, config.WhitelistWeight, config.BlacklistWeight),
+		WidgetClasses:     config.WidgetClasses,
+		AllowedAttributes: config.AllowedAttributes,
+	}
+	if len(config.PreserveTags) > 0 {
+		opts.PreserveTags = make(map[string]bool)
+		for _, tag := range config.PreserveTags {
+			opts.PreserveTags[strings.ToLower(tag)] = true
+		}
+	}
+	return opts
 }
 
 // Extract extracts an article from HTML content.
 func (e *Extractor) Extract(html string) (*Article, error) {
-	return e.ExtractWithURL(html, "")
+	return e.ExtractWithURLContext(context.Background(), html, "")
+}
+
+// ExtractContext extracts an article from HTML content, stopping when ctx
+// is done (see ExtractWithURLContext).
+func (e *Extractor) ExtractContext(ctx context.Context, html string) (*Article, error) {
+	return e.ExtractWithURLContext(ctx, html, "")
 }
 
 // ExtractWithURL extracts an article from HTML content with a base URL.
 func (e *Extractor) ExtractWithURL(html, baseURL string) (*Article, error) {
+	return e.ExtractWithURLContext(context.Background(), html, baseURL)
+}
+
+// ExtractWithURLContext extracts an article from HTML content with a base
+// URL. The extraction stops when ctx is done or ExtractTimeout elapses and
+// returns an *ExtractionError wrapping ErrTimeout, naming the interrupted
+// stage.
+func (e *Extractor) ExtractWithURLContext(ctx context.Context, html, baseURL string) (*Article, error) {
+	e = e.current()
+
 	// Parse HTML
 	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
 	if err != nil {
 		return nil, NewExtractionError("parse", baseURL, ErrInvalidHTML)
 	}
 
-	return e.extractFromDocument(doc, baseURL)
+	return e.extractFromDocument(ctx, doc, baseURL)
+}
+
+// ExtractReader extracts an article by parsing HTML straight from a reader.
+// The charset is detected from the content. It returns ErrContentTooLarge
+// when the input exceeds MaxContentLength.
+func (e *Extractor) ExtractReader(ctx context.Context, r io.Reader, baseURL string) (*Article, error) {
+	e = e.current()
+
+	doc, err := e.parseReader(ctx, r, "", baseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	return e.extractFromDocument(ctx, doc, baseURL)
 }
 
 // ExtractFromURL fetches and extracts an article from a URL.
 func (e *Extractor) ExtractFromURL(ctx context.Context, url string) (*Article, error) {
+	e = e.current()
+
 	// Validate URL
 	if !fetcher.IsValidURL(url) {
 		url = fetcher.NormalizeURL(url)
@@ -63,107 +170,142 @@ func (e *Extractor) ExtractFromURL(ctx context.Context, url string) (*Article, e
 		}
 	}
 
-	// Fetch HTML
-	html, err := e.client.Fetch(ctx, url)
+	// Fetch and parse HTML
+	doc, err := e.fetchDocument(ctx, url)
 	if err != nil {
-		return nil, NewExtractionError("fetch", url, err)
+		return nil, err
+	}
+
+	// Skip pages that are not articles
+	if e.config.ArticleCheck && !classify.IsProbablyArticle(doc) {
+		return nil, NewExtractionError("classify", url, ErrNotArticle)
 	}
 
 	// Extract article
-	article, err := e.ExtractWithURL(html, url)
+	first, err := e.extractPage(ctx, doc, url)
 	if err != nil {
 		return nil, err
 	}
 
+	article := first.article
+	if e.config.MultiPage {
+		article = e.extractPages(ctx, first)
+	}
+
 	article.URL = url
 	return article, nil
 }
 
-// extractFromDocument extracts an article from a goquery document.
-func (e *Extractor) extractFromDocument(doc *goquery.Document, baseURL string) (*Article, error) {
-	// Extract metadata first (before preprocessing removes elements)
-	title := metadata.ExtractTitle(doc)
-	author := metadata.ExtractAuthor(doc)
-	publishedAt := metadata.ExtractDate(doc)
-	leadImage := e.extractLeadImage(doc, baseURL)
-
-	// Preprocess document
-	cleaner.Preprocess(doc)
-
-	// Score content
-	s := scorer.NewScorer(
-		e.config.MinParagraphLength,
-		e.config.MinContentLength,
-		e.config.Debug,
-	)
-
-	topCandidate, scoreMap := s.Score(doc)
-
-	// Check if we found content
-	if topCandidate == nil || topCandidate.Selection == nil {
-		return nil, NewExtractionError("extract", baseURL, ErrNoContent)
+// fetchDocument fetches a URL and parses the response body as it streams.
+func (e *Extractor) fetchDocument(ctx context.Context, url string) (*goquery.Document, error) {
+	resp, err := e.client.FetchReader(ctx, url)
+	if err != nil {
+		return nil, NewExtractionError("fetch", url, newHTTPError(url, err))
 	}
+	defer resp.Close()
+
+	doc, err := e.parseReader(ctx, resp.Body, resp.ContentType, url)
+	var readErr *ExtractionError
+	if errors.As(err, &readErr) && readErr.Op == "read" && !errors.Is(err, ErrContentTooLarge) {
+		// The connection failed while the body streamed, which is a
+		// failed fetch like one before the response arrived
+		readErr.Err = &HTTPError{URL: resp.URL, Err: readErr.Err}
+	}
+	return doc, err
+}
 
-	// Get the content selection
-	contentSel := topCandidate.Selection
+// parseReader parses HTML from a reader, converting it to UTF-8 and
+// enforcing MaxContentLength. A failure to read r, such as a connection
+// reset or a truncated stream, is a "read" error wrapping the cause;
+// ErrInvalidHTML is only returned when the content was read in full.
+func (e *Extractor) parseReader(ctx context.Context, r io.Reader, contentType, baseURL string) (*goquery.Document, error) {
+	body := &fetcher.ErrorReader{R: fetcher.NewLimitedReader(fetcher.NewContextReader(ctx, r), e.config.MaxContentLength)}
+
+	doc, err := goquery.NewDocumentFromReader(fetcher.DecodeReader(body, contentType))
+	if body.Err != nil {
+		// The charset sniffer takes some read errors for the end of a
+		// short document, so check even when parsing succeeded
+		return nil, NewExtractionError("read", baseURL, readError(ctx, body.Err))
+	}
+	if err != nil {
+		return nil, NewExtractionError("parse", baseURL, ErrInvalidHTML)
+	}
 
-	// Try to merge siblings
-	contentSel = cleaner.MergeSiblings(
-		contentSel,
-		topCandidate.GetScore(),
-		e.config.MinParagraphLength,
-	)
+	return doc, nil
+}
 
-	// Clone the content for cleaning
-	contentClone := contentSel.Clone()
+// readError converts an error reading the content to the error reported
+// for it.
+func readError(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(err, fetcher.ErrTooLarge):
+		return ErrContentTooLarge
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case isNetTimeout(err):
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	default:
+		return err
+	}
+}
 
-	// Postprocess content
-	cleaner.Postprocess(contentClone)
+// page holds the extraction result of a single page along with the
+// scoring state needed to merge it with other pages.
+type page struct {
+	article      *Article
+	content      *goquery.Selection
+	topCandidate *scorer.NodeScore
+	scoreMap     *scorer.ScoreMap
+	features     confidence.Features
+	rawText      string
+	emphasis     []string
+	keywordTags  []string
+}
 
-	// Convert relative URLs if base URL provided
-	if baseURL != "" {
-		cleaner.ConvertRelativeURLs(contentClone, baseURL)
+// extractFromDocument extracts an article from a goquery document.
+func (e *Extractor) extractFromDocument(ctx context.Context, doc *goquery.Document, baseURL string) (*Article, error) {
+	p, err := e.extractPage(ctx, doc, baseURL)
+	if err != nil {
+		return nil, err
 	}
+	return p.article, nil
+}
 
-	// Get cleaned HTML and text
-	contentHTML := cleaner.GetCleanHTML(contentClone)
-	textContent := cleaner.GetCleanText(contentClone)
+// extractPage extracts a single page from a goquery document within the
+// ExtractTimeout budget.
+func (e *Extractor) extractPage(ctx context.Context, doc *goquery.Document, baseURL string) (*page, error) {
+	if e.config.ExtractTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.config.ExtractTimeout)
+		defer cancel()
+	}
 
-	// Check content length
-	if len(textContent) < e.config.MinContentLength {
-		return nil, NewExtractionError("validate", baseURL, ErrContentTooShort)
+	state, err := e.runPipeline(ctx, doc, baseURL)
+	if err != nil {
+		return nil, err
 	}
 
-	// Calculate word count
-	wordCount := dom.CountWords(textContent)
-
-	// Calculate confidence based on score and content quality
-	confidence := e.calculateConfidence(topCandidate, scoreMap, wordCount)
-
-	// Generate excerpt
-	excerpt := dom.GetExcerpt(textContent, 200)
-
-	return &Article{
-		Title:       title,
-		Content:     contentHTML,
-		TextContent: textContent,
-		Excerpt:     excerpt,
-		Author:      author,
-		PublishedAt: publishedAt,
-		LeadImage:   leadImage,
-		URL:         baseURL,
-		WordCount:   wordCount,
-		Score:       topCandidate.GetScore(),
-		Confidence:  confidence,
+	return &page{
+		article:      state.Article,
+		content:      state.Content,
+		topCandidate: state.topCandidate,
+		scoreMap:     state.scoreMap,
+		features:     state.features,
+		rawText:      state.rawText,
+		emphasis:     state.emphasis,
+		keywordTags:  state.keywordTags,
 	}, nil
 }
 
-// extractLeadImage extracts the main image from the document.
-func (e *Extractor) extractLeadImage(doc *goquery.Document, baseURL string) *Image {
+// extractLeadImage extracts the main image from the og:image or
+// twitter:image meta tag and returns the name of the source it came from.
+// Images that do not resolve to an absolute URL or whose file name
+// suggests a logo, icon or advert are skipped; without either, the lead image
+// is chosen from the content.
+func extractLeadImage(doc *goquery.Document, baseURL string) (*Image, string) {
 	// Try og:image first
-	ogImage := doc.Find("meta[property='og:image']").AttrOr("content", "")
-	if ogImage != "" {
-		img := &Image{URL: ogImage}
+	if src := doc.Find("meta[property='og:image']").AttrOr("content", ""); usableMetaImage(src, baseURL) {
+		img := &Image{URL: images.Absolute(baseURL, src)}
 
 		// Try to get dimensions
 		if width := doc.Find("meta[property='og:image:width']").AttrOr("content", ""); width != "" {
@@ -173,100 +315,71 @@ func (e *Extractor) extractLeadImage(doc *goquery.Document, baseURL string) *Ima
 			img.Height = parseInt(height)
 		}
 
-		return img
+		return img, "og:image"
 	}
 
 	// Try twitter:image
-	twitterImage := doc.Find("meta[name='twitter:image']").AttrOr("content", "")
-	if twitterImage != "" {
-		return &Image{URL: twitterImage}
+	if src := doc.Find("meta[name='twitter:image']").AttrOr("content", ""); usableMetaImage(src, baseURL) {
+		return &Image{URL: images.Absolute(baseURL, src)}, "twitter:image"
 	}
 
-	// Try to find a large image in article
-	var leadImage *Image
-	doc.Find("article img, .article img, .post img, main img").Each(func(_ int, sel *goquery.Selection) {
-		if leadImage != nil {
-			return
-		}
-
-		src, _ := sel.Attr("src")
-		if src == "" {
-			// Try data-src for lazy-loaded images
-			src, _ = sel.Attr("data-src")
-		}
-		if src == "" {
-			return
-		}
-
-		img := &Image{
-			URL: src,
-			Alt: sel.AttrOr("alt", ""),
-		}
-
-		// Get dimensions
-		if width := sel.AttrOr("width", ""); width != "" {
-			img.Width = parseInt(width)
-		}
-		if height := sel.AttrOr("height", ""); height != "" {
-			img.Height = parseInt(height)
-		}
-
-		// Only use images that seem like article images
-		if img.Width >= 200 || img.Height >= 200 || (img.Width == 0 && img.Height == 0) {
-			leadImage = img
-		}
-	})
+	return nil, ""
+}
 
-	return leadImage
+// usableMetaImage reports whether a meta tag image resolves to an absolute
+// URL and is not a logo, icon or advert.
+func usableMetaImage(src, baseURL string) bool {
+	return src != "" && images.Absolute(baseURL, src) != "" && !images.UnlikelyMeta(src)
 }
 
-// calculateConfidence calculates a confidence score for the extraction.
-func (e *Extractor) calculateConfidence(topCandidate *scorer.NodeScore, scoreMap *scorer.ScoreMap, wordCount int) float64 {
-	confidence := 0.0
-
-	// Base confidence from score
-	score := topCandidate.GetScore()
-	if score > 100 {
-		confidence += 0.4
-	} else if score > 50 {
-		confidence += 0.3
-	} else if score > 20 {
-		confidence += 0.2
-	} else {
-		confidence += 0.1
+// contentLeadImage scores the images in and around the content candidate
+// and returns the best one, or nil.
+func contentLeadImage(state *State) *Image {
+	c := images.Lead(state.Candidate, state.URL)
+	if c == nil {
+		return nil
 	}
+	return &Image{URL: c.URL, Width: c.Width, Height: c.Height, Alt: c.Alt}
+}
 
-	// Confidence from word count
-	if wordCount > 500 {
-		confidence += 0.3
-	} else if wordCount > 200 {
-		confidence += 0.2
-	} else if wordCount > 100 {
-		confidence += 0.1
+// contentImages lists the images of the cleaned content.
+func contentImages(content *goquery.Selection) []Image {
+	var list []Image
+	for i, info := range images.Collect(content) {
+		list = append(list, Image{
+			URL:      info.URL,
+			Width:    info.Width,
+			Height:   info.Height,
+			Alt:      info.Alt,
+			Caption:  info.Caption,
+			Credit:   info.Credit,
+			Position: i + 1,
+		})
 	}
+	return list
+}
 
-	// Confidence from link density
-	if topCandidate.LinkDensity < 0.1 {
-		confidence += 0.2
-	} else if topCandidate.LinkDensity < 0.2 {
-		confidence += 0.1
+// captionLeadImage finds the content image that is the lead image, comparing
+// their URLs by images.Key, and copies its position, caption and credit to
+// the lead image. A caption the lead image already has is kept.
+func captionLeadImage(lead *Image, list []Image, pageURL string) {
+	if lead == nil {
+		return
 	}
-
-	// Confidence from score dominance
-	candidates := scoreMap.GetCandidatesByScore()
-	if len(candidates) >= 2 {
-		secondScore := candidates[1].GetScore()
-		if score > secondScore*2 {
-			confidence += 0.1
-		}
+	key := images.Key(pageURL, lead.URL)
+	if key == "" {
+		return
 	}
-
-	// Clamp to [0, 1]
-	if confidence > 1.0 {
-		confidence = 1.0
+	for _, img := range list {
+		if images.Key(pageURL, img.URL) != key {
+			continue
+		}
+		lead.Position = img.Position
+		if lead.Caption == "" && lead.Credit == "" {
+			lead.Caption, lead.Credit = img.Caption, img.Credit
+		}
+		return
 	}
-
-	return confidence
 }
 
 // parseInt parses a string to int, returning 0 on error.
@@ -1,8 +1,26 @@
 package extractor
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"strings"
+	"sync"
 	"testing"
+	"testing/iotest"
+	"time"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
 )
 
 func TestExtract_SimpleArticle(t *testing.T) {
@@ -308,3 +326,1930 @@ func TestExtract_Excerpt(t *testing.T) {
 		t.Errorf("Excerpt too long: %d characters", len(article.Excerpt))
 	}
 }
+
+func TestExtract_SiteRule(t *testing.T) {
+	html := `
+<!DOCTYPE html>
+<html>
+<head>
+	<meta property="og:title" content="Generic Title">
+	<meta name="byline" content="Rule Author">
+</head>
+<body>
+	<div class="story-headline">Rule Title</div>
+	<time class="story-date" datetime="2024-05-01T08:00:00Z">May 1</time>
+	<div class="comments">
+		<p>This comment section is long enough to outscore the real story, with plenty of words, commas, and chatter, chatter, chatter.</p>
+		<p>Another long comment that the generic scorer would happily pick up, because it has commas, length, and no links at all.</p>
+	</div>
+	<div class="story-body">
+		<p>This is the real story body selected by the site rule. It should be used instead of the scored candidate.</p>
+		<div class="inline-promo">Subscribe to our newsletter today!</div>
+		<span class="lede">The lede paragraph is rendered as a span on this site and should become a paragraph.</span>
+	</div>
+</body>
+</html>`
+
+	rule := &SiteRule{
+		Domain:  "example.com",
+		Title:   []string{".story-headline"},
+		Author:  []string{"meta[name='byline']"},
+		Date:    []string{"time.story-date"},
+		Content: []string{".story-body"},
+		Clean:   []string{".inline-promo"},
+		Transforms: []TransformRule{
+			{Selector: "span.lede", Transform: RenameTag("p")},
+		},
+	}
+
+	ext := New(WithSiteRules(rule), WithMinContentLength(50))
+	article, err := ext.ExtractWithURL(html, "https://www.news.example.com/story")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if article.Title != "Rule Title" {
+		t.Errorf("Expected title 'Rule Title', got '%s'", article.Title)
+	}
+
+	if article.Author != "Rule Author" {
+		t.Errorf("Expected author 'Rule Author', got '%s'", article.Author)
+	}
+
+	if article.PublishedAt == nil || article.PublishedAt.Month() != 5 {
+		t.Errorf("Expected publication date from rule, got %v", article.PublishedAt)
+	}
+
+	if !strings.Contains(article.TextContent, "real story body") {
+		t.Error("Content should come from the rule's content selector")
+	}
+
+	if strings.Contains(article.TextContent, "comment section") {
+		t.Error("Content should not contain the scored comment section")
+	}
+
+	if strings.Contains(article.TextContent, "Subscribe") {
+		t.Error("Content should not contain elements matched by clean selectors")
+	}
+
+	if !strings.Contains(article.Content, "<p>The lede paragraph") {
+		t.Errorf("Transform should rename span to p, got %s", article.Content)
+	}
+
+	// Rules do not apply to other domains
+	article, err = ext.ExtractWithURL(html, "https://other.org/story")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if article.Title != "Generic Title" {
+		t.Errorf("Expected generic title on other domains, got '%s'", article.Title)
+	}
+}
+
+func TestExtractFromURL_MultiPage(t *testing.T) {
+	pages := map[string]string{
+		"/story": `<html><head><title>Long Story</title></head><body><article>
+			<p>Page one of the long story begins here, with enough words to count as a real paragraph of content.</p>
+			<p>The first page continues with more detail about the events, the people involved, and what happened next.</p>
+		</article><div class="pager"><a href="/story?page=2">Next</a></div></body></html>`,
+		"/story?page=2": `<html><body><article>
+			<p>Page two of the long story picks up where the first page left off, adding further details and quotes.</p>
+			<p>The second page also has plenty of text, so it is extracted and merged into the final article.</p>
+		</article><div class="pager"><a href="/story">Previous</a><a href="/story?page=3">Next</a></div></body></html>`,
+		"/story?page=3": `<html><body><article>
+			<p>Page three wraps up the long story, with a conclusion that should appear at the end of the merged text.</p>
+			<p>Because this page links back to the second page, the extractor must detect the loop and stop here.</p>
+		</article><a href="/story?page=2" rel="next">Next</a></body></html>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	single, err := New().ExtractFromURL(context.Background(), server.URL+"/story")
+	if err != nil {
+		t.Fatalf("ExtractFromURL failed: %v", err)
+	}
+	if single.NextPageURL != server.URL+"/story?page=2" {
+		t.Errorf("Expected next page URL, got '%s'", single.NextPageURL)
+	}
+	if strings.Contains(single.TextContent, "Page two") {
+		t.Error("Multi-page mode is opt-in")
+	}
+
+	ext := New(WithMultiPage(true))
+	article, err := ext.ExtractFromURL(context.Background(), server.URL+"/story")
+	if err != nil {
+		t.Fatalf("ExtractFromURL failed: %v", err)
+	}
+
+	if len(article.Pages) != 3 {
+		t.Fatalf("Expected 3 pages, got %d: %v", len(article.Pages), article.Pages)
+	}
+
+	for _, want := range []string{"Page one", "Page two", "Page three"} {
+		if !strings.Contains(article.TextContent, want) {
+			t.Errorf("Merged content should contain %q", want)
+		}
+	}
+
+	if article.WordCount <= single.WordCount {
+		t.Errorf("Merged word count %d should exceed single page count %d", article.WordCount, single.WordCount)
+	}
+
+	if article.NextPageURL != "" {
+		t.Errorf("Expected loop detection to clear next page, got '%s'", article.NextPageURL)
+	}
+
+	limited, err := New(WithMultiPage(true), WithMaxPages(2)).ExtractFromURL(context.Background(), server.URL+"/story")
+	if err != nil {
+		t.Fatalf("ExtractFromURL failed: %v", err)
+	}
+	if len(limited.Pages) != 2 {
+		t.Errorf("Expected page limit of 2, got %d", len(limited.Pages))
+	}
+}
+
+func TestExtractFromURL_MultiPageFootnotes(t *testing.T) {
+	pages := map[string]string{
+		"/story": `<html><head><title>Linked Story</title></head><body><article>
+			<p>Page one of the linked story cites <a href="https://example.com/report">a report</a> in a paragraph long enough to count.</p>
+			<p>The first page continues with more detail about the events, the people involved, and what happened next.</p>
+		</article><a href="/story?page=2" rel="next">Next</a></body></html>`,
+		"/story?page=2": `<html><body><article>
+			<p>Page two of the linked story cites <a href="https://example.com/study">a study</a> and adds further details and quotes.</p>
+			<p>It links to <a href="https://example.com/report">the report</a> again, which keeps its number from the first page.</p>
+		</article></body></html>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	article, err := New(WithMultiPage(true), WithLinkFootnotes(true)).ExtractFromURL(context.Background(), server.URL+"/story")
+	if err != nil {
+		t.Fatalf("ExtractFromURL failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"a report [1]",
+		"a study [2]",
+		"the report [1]",
+		"[1] https://example.com/report\n[2] https://example.com/study",
+	} {
+		if !strings.Contains(article.TextContent, want) {
+			t.Errorf("Expected %q in the merged text:\n%s", want, article.TextContent)
+		}
+	}
+	if n := strings.Count(article.TextContent, "[1] https://"); n != 1 {
+		t.Errorf("Expected a single footnote list, got %d:\n%s", n, article.TextContent)
+	}
+}
+
+func TestExtractFromURL_MultiPageImages(t *testing.T) {
+	pages := map[string]string{
+		"/story": `<html><head><title>Harbor Story</title></head><body><article>
+			<figure><img src="/photos/harbor.jpg" width="1200" height="800"><figcaption>The harbor at dawn.</figcaption></figure>
+			<p>Page one of the harbor story describes the repairs in a paragraph long enough to count as content.</p>
+			<figure><img src="/photos/crane.jpg" width="800" height="600"><figcaption>A crane on the breakwater.</figcaption></figure>
+		</article><a href="/story?page=2" rel="next">Next</a></body></html>`,
+		"/story?page=2": `<html><body><article>
+			<p>Page two of the harbor story follows the boats back to their moorings, with more details and quotes.</p>
+			<figure><img src="/photos/boats.jpg" width="800" height="600"><figcaption>Boats in the bay.</figcaption></figure>
+		</article></body></html>`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := pages[r.URL.RequestURI()]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	article, err := New(WithMultiPage(true)).ExtractFromURL(context.Background(), server.URL+"/story")
+	if err != nil {
+		t.Fatalf("ExtractFromURL failed: %v", err)
+	}
+
+	want := []string{"/photos/harbor.jpg", "/photos/crane.jpg", "/photos/boats.jpg"}
+	if len(article.Images) != len(want) {
+		t.Fatalf("Images = %+v, want %v", article.Images, want)
+	}
+	for i, img := range article.Images {
+		if img.URL != server.URL+want[i] || img.Position != i+1 {
+			t.Errorf("Images[%d] = %+v, want %s at position %d", i, img, want[i], i+1)
+		}
+	}
+	if lead := article.LeadImage; lead == nil || lead.Position != 1 {
+		t.Errorf("Expected the lead image at position 1, got %+v", article.LeadImage)
+	}
+}
+
+func TestExtractReader(t *testing.T) {
+	// ISO-8859-1 encoded page: "é" is 0xE9
+	html := "<html><head><meta charset=\"iso-8859-1\"><title>Caf\xe9</title></head><body><article>" +
+		"<p>The caf\xe9 on the corner serves coffee to everyone in the neighbourhood, from early morning until late at night.</p>" +
+		"<p>Regulars say the pastries are the best in town, and the owners have run the place for more than thirty years.</p>" +
+		"</article></body></html>"
+
+	ext := New()
+	article, err := ext.ExtractReader(context.Background(), strings.NewReader(html), "https://example.com/cafe")
+	if err != nil {
+		t.Fatalf("ExtractReader failed: %v", err)
+	}
+
+	if article.Title != "Café" {
+		t.Errorf("Expected title 'Café', got '%s'", article.Title)
+	}
+
+	if !strings.Contains(article.TextContent, "The café on the corner") {
+		t.Errorf("Expected decoded content, got '%s'", article.TextContent)
+	}
+}
+
+func TestExtractReader_ContentTooLarge(t *testing.T) {
+	html := "<html><body><article><p>" + strings.Repeat("Lots of words in a very long paragraph. ", 100) + "</p></article></body></html>"
+
+	ext := New(WithMaxContentLength(1024))
+	_, err := ext.ExtractReader(context.Background(), strings.NewReader(html), "")
+	if !errors.Is(err, ErrContentTooLarge) {
+		t.Errorf("Expected ErrContentTooLarge, got %v", err)
+	}
+}
+
+func TestExtractReader_ReadError(t *testing.T) {
+	reset := errors.New("connection reset by peer")
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"reset", reset},
+		{"truncated", io.ErrUnexpectedEOF},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := io.MultiReader(strings.NewReader("<html><body><article><p>The first half of"), iotest.ErrReader(tt.err))
+			_, err := New().ExtractReader(context.Background(), r, "https://example.com/story")
+
+			var extErr *ExtractionError
+			if !errors.As(err, &extErr) || extErr.Op != "read" {
+				t.Fatalf("Expected a read error, got %v", err)
+			}
+			if !errors.Is(err, tt.err) || errors.Is(err, ErrInvalidHTML) {
+				t.Errorf("Expected the read error to wrap %v and not ErrInvalidHTML, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestExtractAll(t *testing.T) {
+	page := `<html><body><article>
+		<p>This batch article has enough content to be extracted, with several sentences of meaningful text.</p>
+		<p>A second paragraph makes sure the content passes the minimum length check without any trouble.</p>
+	</article></body></html>`
+
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	urls := []string{
+		server.URL + "/a",
+		server.URL + "/missing",
+		server.URL + "/b",
+		server.URL + "/c",
+		server.URL + "/d",
+	}
+
+	ext := New(WithMaxConcurrency(4), WithMaxPerHost(2))
+
+	seen := make(map[int]bool)
+	for result := range ext.ExtractAll(context.Background(), urls) {
+		seen[result.Index] = true
+		if result.URL != urls[result.Index] {
+			t.Errorf("Result %d has URL %s, want %s", result.Index, result.URL, urls[result.Index])
+		}
+		if result.Index == 1 {
+			if result.Err == nil {
+				t.Error("Expected error for missing page")
+			}
+			continue
+		}
+		if result.Err != nil {
+			t.Errorf("Unexpected error for %s: %v", result.URL, result.Err)
+		}
+	}
+
+	if len(seen) != len(urls) {
+		t.Errorf("Expected %d results, got %d", len(urls), len(seen))
+	}
+
+	if maxActive > 2 {
+		t.Errorf("Per-host limit exceeded: %d concurrent requests", maxActive)
+	}
+}
+
+func TestExtractAll_WorkerLimit(t *testing.T) {
+	var mu sync.Mutex
+	active, maxActive := 0, 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		maxActive = max(maxActive, active)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+
+	urls := make([]string, 40)
+	for i := range urls {
+		urls[i] = fmt.Sprintf("%s/%d", server.URL, i)
+	}
+
+	count := 0
+	for range New(WithMaxConcurrency(3), WithMaxPerHost(0)).ExtractAll(context.Background(), urls) {
+		count++
+	}
+
+	if count != len(urls) {
+		t.Errorf("Expected %d results, got %d", len(urls), count)
+	}
+	if maxActive > 3 {
+		t.Errorf("Concurrency limit exceeded: %d concurrent requests", maxActive)
+	}
+}
+
+func TestExtractAll_BusyHost(t *testing.T) {
+	page := `<html><body><article>
+		<p>This batch article has enough content to be extracted, with several sentences of meaningful text.</p>
+		<p>A second paragraph makes sure the content passes the minimum length check without any trouble.</p>
+	</article></body></html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+		_, _ = w.Write([]byte(page))
+	}))
+	defer server.Close()
+
+	// The same server under two host names
+	slow := server.URL + "/slow"
+	fast := strings.Replace(server.URL, "127.0.0.1", "localhost", 1) + "/fast"
+	urls := []string{slow, slow, slow, fast}
+
+	var order []int
+	for result := range New(WithMaxConcurrency(2), WithMaxPerHost(1)).ExtractAll(context.Background(), urls) {
+		if result.Err != nil {
+			t.Errorf("Unexpected error for %s: %v", result.URL, result.Err)
+		}
+		order = append(order, result.Index)
+	}
+
+	// URLs queued for the busy host do not hold up the other host
+	if len(order) != 4 || order[0] != 3 {
+		t.Errorf("Expected the other host to finish first, got order %v", order)
+	}
+}
+
+func TestExtractAll_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	count := 0
+	for result := range New().ExtractAll(ctx, []string{"https://example.com/a", "https://example.com/b"}) {
+		count++
+		if !errors.Is(result.Err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", result.Err)
+		}
+	}
+
+	if count != 2 {
+		t.Errorf("Expected 2 results, got %d", count)
+	}
+}
+
+func TestExtract_DebugTrace(t *testing.T) {
+	html := `
+<!DOCTYPE html>
+<html>
+<head>
+	<meta property="og:title" content="Traced Article">
+	<meta name="author" content="Jane Doe">
+</head>
+<body>
+	<nav>Site navigation</nav>
+	<div style="display:none">Hidden promo</div>
+	<div class="sidebar"><a href="/a">Link A</a> <a href="/b">Link B</a></div>
+	<div id="main" class="content">
+		<p>This article is extracted in debug mode, so the extractor records every decision it makes along the way.</p>
+		<p>The trace lists removed elements, scored candidates, sibling decisions, and the source of each field.</p>
+	</div>
+</body>
+</html>`
+
+	article, err := New().Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if article.Trace != nil {
+		t.Error("Trace should only be returned in debug mode")
+	}
+
+	article, err = New(WithDebug(true)).Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	tr := article.Trace
+	if tr == nil {
+		t.Fatal("Expected trace in debug mode")
+	}
+
+	reasons := make(map[string]bool)
+	for _, removal := range tr.Removed {
+		reasons[removal.Reason] = true
+	}
+	for _, want := range []string{"hidden", "unlikely tag", "blacklisted"} {
+		if !reasons[want] {
+			t.Errorf("Expected a removal with reason %q, got %+v", want, tr.Removed)
+		}
+	}
+
+	if len(tr.Candidates) == 0 || !tr.Candidates[0].Selected {
+		t.Errorf("Expected the selected candidate first, got %+v", tr.Candidates)
+	}
+
+	sources := make(map[string]string)
+	for _, field := range tr.Metadata {
+		sources[field.Name] = field.Source
+	}
+	if sources["title"] != "og:title" || sources["author"] != "meta author" {
+		t.Errorf("Unexpected metadata sources: %+v", tr.Metadata)
+	}
+
+	data, err := json.Marshal(article)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Article
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if decoded.Trace == nil || len(decoded.Trace.Removed) != len(tr.Removed) {
+		t.Error("Trace should survive a JSON round trip")
+	}
+}
+
+func TestExtract_PipelineHooks(t *testing.T) {
+	html := `
+<!DOCTYPE html>
+<html>
+<body>
+	<article>
+		<p>The publisher appends a legal disclaimer to every story, which we want to strip without forking the cleaner.</p>
+		<p>This second paragraph is regular article content and has to survive the custom cleanup hook untouched.</p>
+		<p class="legal">Disclaimer: the views expressed here are not those of the publisher or its affiliates.</p>
+	</article>
+</body>
+</html>`
+
+	var calls []string
+	ext := New(
+		WithHook(BeforePreprocess, func(state *State) error {
+			calls = append(calls, "before-preprocess")
+			if state.Candidate != nil {
+				t.Error("Hook should run before scoring")
+			}
+			return nil
+		}),
+		WithHook(AfterCandidate, func(state *State) error {
+			calls = append(calls, "after-candidate")
+			if state.Candidate == nil {
+				t.Error("Candidate should be chosen")
+			}
+			return nil
+		}),
+		WithHook(AfterPostprocess, func(state *State) error {
+			calls = append(calls, "after-postprocess")
+			state.Content.Find("p").Each(func(_ int, p *goquery.Selection) {
+				if strings.HasPrefix(p.Text(), "Disclaimer:") {
+					p.Remove()
+				}
+			})
+			return nil
+		}),
+	)
+
+	article, err := ext.Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	if strings.Join(calls, ",") != "before-preprocess,after-candidate,after-postprocess" {
+		t.Errorf("Unexpected hook order: %v", calls)
+	}
+
+	if strings.Contains(article.TextContent, "Disclaimer") {
+		t.Error("Hook should remove the disclaimer")
+	}
+
+	if !strings.Contains(article.TextContent, "second paragraph") {
+		t.Error("Regular content should be kept")
+	}
+}
+
+func TestExtract_HooksAtSamePoint(t *testing.T) {
+	record := func(calls *[]string, name string) Hook {
+		return func(state *State) error {
+			*calls = append(*calls, name)
+			return nil
+		}
+	}
+
+	var calls []string
+	stages := buildPipeline([]PipelineEdit{
+		hookEdit(AfterCandidate, record(&calls, "first")),
+		hookEdit(AfterCandidate, record(&calls, "second")),
+		hookEdit(AfterCandidate, record(&calls, "third")),
+		hookEdit(HookPoint(99), record(&calls, "unknown")),
+	})
+	var names []string
+	for _, stage := range stages {
+		names = append(names, stage.Name())
+	}
+	want := "metadata,preprocess,score,merge-siblings,after-candidate-hook-1,after-candidate-hook-2,after-candidate-hook-3,postprocess"
+	if !strings.HasPrefix(strings.Join(names, ","), want) {
+		t.Errorf("Unexpected stages: %v", names)
+	}
+	if len(names) != len(DefaultStages())+3 {
+		t.Errorf("Unknown hook point should be ignored: %v", names)
+	}
+
+	// Hooks run in the order they were added and can be targeted by name
+	html := `<html><body><article>
+		<p>The city council approved the new transit plan on Monday after a debate that lasted well into the evening and drew a large crowd of residents.</p>
+		<p>The plan adds three bus lines and extends the light rail to the airport, with construction expected to start next spring.</p>
+	</article></body></html>`
+	calls = nil
+	ext := New(
+		WithHook(AfterCandidate, record(&calls, "first")),
+		WithHook(AfterCandidate, record(&calls, "second")),
+		WithHook(AfterCandidate, record(&calls, "third")),
+		WithPipelineEdit(Replace(HookName(AfterCandidate, 2), NewStage("replaced", record(&calls, "replaced")))),
+		WithPipelineEdit(Remove(HookName(AfterCandidate, 3))),
+	)
+	if _, err := ext.Extract(html); err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if strings.Join(calls, ",") != "first,replaced" {
+		t.Errorf("Unexpected hook calls: %v", calls)
+	}
+}
+
+func TestExtract_CustomStages(t *testing.T) {
+	html := `
+<!DOCTYPE html>
+<html>
+<body>
+	<div class="story">
+		<p>This is the story body that a custom scoring stage selects directly, bypassing the generic scorer.</p>
+		<p>A second paragraph gives the story enough length to pass the minimum content length validation.</p>
+	</div>
+</body>
+</html>`
+
+	pick := NewStage("pick-story", func(state *State) error {
+		state.Candidate = state.Document.Find(".story")
+		return nil
+	})
+
+	ext := New(WithPipelineEdit(Replace(StageScore, pick)))
+	article, err := ext.Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if !strings.Contains(article.TextContent, "custom scoring stage") {
+		t.Error("Content should come from the custom stage")
+	}
+
+	// Reordering and errors
+	var order []string
+	ext = New(WithStages(func(stages []Stage) []Stage {
+		for i, stage := range stages {
+			name := stage.Name()
+			stages[i] = NewStage(name, func(state *State) error {
+				order = append(order, name)
+				return stage.Run(state)
+			})
+		}
+		return stages
+	}), WithPipelineEdit(InsertAfter(StageScore, NewStage("fail", func(state *State) error {
+		return errors.New("boom")
+	}))))
+
+	_, err = ext.Extract(html)
+	var extractionErr *ExtractionError
+	if !errors.As(err, &extractionErr) || extractionErr.Op != "fail" {
+		t.Errorf("Expected stage error wrapped with the stage name, got %v", err)
+	}
+	if strings.Join(order, ",") != "metadata,preprocess,score" {
+		t.Errorf("Unexpected stage order: %v", order)
+	}
+}
+
+func TestExtract_Markdown(t *testing.T) {
+	html := `
+<!DOCTYPE html>
+<html>
+<body>
+	<article>
+		<h2>Getting Started</h2>
+		<p>This guide walks through installing the tool and running it for the first time on <em>any</em> platform.</p>
+		<ul>
+			<li>Download the <a href="/download">latest release</a></li>
+			<li>Unpack the archive into a directory on your path</li>
+		</ul>
+		<pre class="code-block"><code class="language-sh">tool --version</code></pre>
+		<p>The version command prints the installed release, which confirms that everything is working.</p>
+	</article>
+</body>
+</html>`
+
+	article, err := New().Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if article.Markdown != "" {
+		t.Error("Markdown should be empty unless enabled")
+	}
+
+	ext := New(WithMarkdown(true))
+	article, err = ext.ExtractWithURL(html, "https://example.com/guide")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"## Getting Started",
+		"on *any* platform",
+		"- Download the [latest release](https://example.com/download)",
+		"```sh\ntool --version\n```",
+	} {
+		if !strings.Contains(article.Markdown, want) {
+			t.Errorf("Markdown missing %q:\n%s", want, article.Markdown)
+		}
+	}
+}
+
+func TestExtract_Blocks(t *testing.T) {
+	html := `
+<!DOCTYPE html>
+<html>
+<body>
+	<article>
+		<h2>Market Update</h2>
+		<p>Shares rose sharply on <strong>Tuesday</strong> after the company reported <a href="/results">quarterly results</a> ahead of forecasts.</p>
+		<figure><img src="/chart.png" alt="Chart"><figcaption>Share price this week</figcaption></figure>
+		<ul><li>Revenue up 12 percent</li><li>Margins steady at record levels</li></ul>
+		<p>Analysts expect the momentum to continue into the next quarter as demand remains strong.</p>
+	</article>
+</body>
+</html>`
+
+	ext := New(WithBlocks(true))
+	article, err := ext.ExtractWithURL(html, "https://example.com/news/update")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(article.Blocks) == 0 || article.Blocks[0].Type != BlockHeading {
+		t.Fatalf("Expected blocks starting with a heading, got %+v", article.Blocks)
+	}
+
+	var image *Block
+	for i := range article.Blocks {
+		if article.Blocks[i].Type == BlockImage {
+			image = &article.Blocks[i]
+		}
+	}
+	if image == nil || image.URL != "https://example.com/chart.png" || image.Caption[0].Text != "Share price this week" {
+		t.Errorf("Expected captioned image with absolute URL, got %+v", image)
+	}
+
+	// The block tree must survive a JSON round trip
+	data, err := json.Marshal(article.Blocks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []Block
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, article.Blocks) {
+		t.Errorf("Blocks changed in JSON round trip:\n%+v\n%+v", article.Blocks, decoded)
+	}
+}
+
+func TestExtract_EmbedBlocks(t *testing.T) {
+	html := `<html><body><article>
+		<p>The mayor explained the new transit plan at a press conference on Monday, answering questions for nearly an hour.</p>
+		<iframe src="https://video.example.com/embed/press-conference" width="640" height="360"></iframe>
+		<p>The plan adds three bus lines and extends the light rail to the airport, with construction expected to start next spring.</p>
+	</article></body></html>`
+
+	embeds := func(article *Article) []Block {
+		var found []Block
+		for _, block := range article.Blocks {
+			if block.Type == BlockEmbed {
+				found = append(found, block)
+			}
+		}
+		return found
+	}
+
+	// Preprocessing strips iframes unless they are preserved
+	article, err := New(WithBlocks(true)).ExtractWithURL(html, "https://example.com/news/transit")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if found := embeds(article); len(found) != 0 {
+		t.Errorf("Expected no embed blocks by default, got %+v", found)
+	}
+
+	article, err = New(WithBlocks(true), WithPreserveTags("iframe")).ExtractWithURL(html, "https://example.com/news/transit")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	found := embeds(article)
+	if len(found) != 1 || found[0].URL != "https://video.example.com/embed/press-conference" || found[0].Width != 640 {
+		t.Errorf("Expected the preserved iframe as an embed block, got %+v", found)
+	}
+}
+
+func TestExtract_StructuredText(t *testing.T) {
+	html := `
+<!DOCTYPE html>
+<html>
+<body>
+	<article>
+		<h2>What Changed</h2>
+		<p>The city council approved the new transit plan after a long debate on Monday evening.</p>
+		<ul>
+			<li>Two new bus routes</li>
+			<li>Longer weekend service</li>
+		</ul>
+		<p>Details are available on the <a href="https://example.com/plan">council website</a> for residents.</p>
+	</article>
+</body>
+</html>`
+
+	article, err := New().Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"What Changed\n------------\n\n",
+		"\n\n- Two new bus routes\n- Longer weekend service\n\n",
+	} {
+		if !strings.Contains(article.TextContent, want) {
+			t.Errorf("TextContent missing %q:\n%s", want, article.TextContent)
+		}
+	}
+	if article.WordCount != 33 {
+		t.Errorf("Expected markers to be left out of the word count, got %d", article.WordCount)
+	}
+
+	article, err = New(WithLinkFootnotes(true)).Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if !strings.HasSuffix(article.TextContent, "council website [1] for residents.\n\n[1] https://example.com/plan") {
+		t.Errorf("Expected link footnotes, got:\n%s", article.TextContent)
+	}
+}
+
+func TestIsProbablyArticle(t *testing.T) {
+	paragraph := `<p>The committee met on Thursday to review the proposal in detail, hearing from residents, business owners and the transport authority about the expected effects on traffic and trade.</p>`
+	article := `<html><body><article>` + strings.Repeat(paragraph, 5) + `</article></body></html>`
+	listing := `<html><body><ul>` + strings.Repeat(`<li><a href="/story">Another headline from the front page of the site</a></li>`, 40) + `</ul></body></html>`
+
+	if !IsProbablyArticle(article) {
+		t.Error("Expected article page to be detected")
+	}
+	if IsProbablyArticle(listing) {
+		t.Error("Expected listing page to be rejected")
+	}
+}
+
+func TestExtractFromURL_ArticleCheck(t *testing.T) {
+	listing := `<html><body><ul>` + strings.Repeat(`<li><a href="/story">Another headline from the front page of the site</a></li>`, 40) + `</ul></body></html>`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(listing))
+	}))
+	defer server.Close()
+
+	ext := New(WithArticleCheck(true))
+	_, err := ext.ExtractFromURL(context.Background(), server.URL)
+	if !errors.Is(err, ErrNotArticle) {
+		t.Errorf("Expected ErrNotArticle, got %v", err)
+	}
+
+	_, err = New().ExtractFromURL(context.Background(), server.URL)
+	if errors.Is(err, ErrNotArticle) {
+		t.Error("ErrNotArticle should only be returned with the article check enabled")
+	}
+}
+
+func TestExtract_PageType(t *testing.T) {
+	html := `
+<!DOCTYPE html>
+<html>
+<head>
+	<script type="application/ld+json">{"@context":"https://schema.org","@type":"NewsArticle","headline":"Council approves plan"}</script>
+</head>
+<body>
+	<article>
+		<h1>Council approves plan</h1>
+		<p>The city council approved the new transit plan on Monday after a debate that lasted well into the evening and drew a large crowd of residents.</p>
+		<p>Supporters said the plan would cut commute times, while opponents raised concerns about the cost and the pace of construction in older neighborhoods.</p>
+	</article>
+</body>
+</html>`
+
+	article, err := New().ExtractWithURL(html, "https://example.com/news/council-approves-plan")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if article.PageType != PageTypeNews {
+		t.Errorf("Expected page type %q, got %q", PageTypeNews, article.PageType)
+	}
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pageType := ClassifyPage(doc, "https://example.com/news/council-approves-plan"); pageType != PageTypeNews {
+		t.Errorf("ClassifyPage = %q, want %q", pageType, PageTypeNews)
+	}
+	if doc.Find("script").Length() != 1 {
+		t.Error("ClassifyPage should not modify the document")
+	}
+}
+
+func TestFitConfidenceModel(t *testing.T) {
+	paragraph := `<p>The council approved the transit plan after a long debate, and officials said construction would start next spring in the northern districts.</p>`
+	article := `<html><head><title>Transit plan approved</title><meta name="author" content="Jane Doe"></head><body><article><h1>Transit plan approved</h1>` + strings.Repeat(paragraph, 6) + `</article></body></html>`
+	listing := `<html><body>` + strings.Repeat(`<div><h3><a href="/s">Headline</a></h3><p>A short teaser for a story elsewhere on the site, with just enough text to score.</p></div>`, 8) + `</body></html>`
+
+	dir := t.TempDir()
+	var manifest strings.Builder
+	for i := 0; i < 5; i++ {
+		for _, page := range []struct {
+			html    string
+			correct bool
+		}{{article, true}, {listing, false}} {
+			name := fmt.Sprintf("page-%d-%v.html", i, page.correct)
+			if err := os.WriteFile(filepath.Join(dir, name), []byte(page.html), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			fmt.Fprintf(&manifest, `{"file": %q, "correct": %v}`+"\n", name, page.correct)
+		}
+	}
+	// A page without content fails to extract and is reported as skipped
+	if err := os.WriteFile(filepath.Join(dir, "empty.html"), []byte(`<html><body></body></html>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	manifest.WriteString(`{"file": "empty.html", "correct": false}` + "\n")
+	if err := os.WriteFile(filepath.Join(dir, "labels.jsonl"), []byte(manifest.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	model, fit, err := New().FitConfidenceModel(dir)
+	if err != nil {
+		t.Fatalf("FitConfidenceModel failed: %v", err)
+	}
+	if len(fit.Samples) != 10 || len(fit.Skipped) != 1 || fit.Skipped[0] != filepath.Join(dir, "empty.html") {
+		t.Errorf("Expected 10 samples and the empty page skipped, got %d samples, skipped %v", len(fit.Samples), fit.Skipped)
+	}
+	if !fit.Converged || fit.Loss <= 0 || fit.Loss >= math.Log(2) {
+		t.Errorf("Expected training to converge below the chance loss, got %+v", fit)
+	}
+
+	ext := New(WithConfidenceModel(model))
+	good, err := ext.Extract(article)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bad, err := ext.Extract(listing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if good.Confidence <= 0.5 || bad.Confidence >= 0.5 {
+		t.Errorf("Refitted model should separate the corpus, got %.2f and %.2f", good.Confidence, bad.Confidence)
+	}
+
+	// Saved models can be loaded back
+	path := filepath.Join(dir, "model.json")
+	if err := model.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfidenceModel(path); err != nil {
+		t.Errorf("LoadConfidenceModel failed: %v", err)
+	}
+}
+
+func TestExtract_Alternatives(t *testing.T) {
+	html := `
+<!DOCTYPE html>
+<html>
+<body>
+	<div id="story">
+		<p>The city council approved the new transit plan on Monday after a debate that lasted well into the evening and drew a large crowd of residents.</p>
+		<p>Supporters said the plan would cut commute times, while opponents raised concerns about the cost, the pace of construction and the loss of parking.</p>
+		<p>Construction is expected to begin next spring, with the first new bus lanes opening downtown by the end of the year, according to city officials.</p>
+	</div>
+	<div id="interview">
+		<p>In a separate interview, the transit director said the agency had studied similar projects in other cities before drafting its proposal this year.</p>
+		<p>She added that the agency would publish monthly progress reports so residents could follow the work and raise problems early on in the process.</p>
+	</div>
+</body>
+</html>`
+
+	article, err := New().Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if article.Alternatives != nil {
+		t.Error("Alternatives should be nil unless enabled")
+	}
+
+	article, err = New(WithAlternatives(3)).Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(article.Alternatives) != 1 {
+		t.Fatalf("Expected 1 alternative, got %d", len(article.Alternatives))
+	}
+
+	alternative := article.Alternatives[0]
+	if alternative.Element != "div#interview" {
+		t.Errorf("Expected div#interview, got %q", alternative.Element)
+	}
+	if !strings.Contains(alternative.TextContent, "transit director") {
+		t.Errorf("Alternative should contain the interview text, got %q", alternative.TextContent)
+	}
+	if strings.Contains(alternative.Content, "city council") {
+		t.Error("Alternative should not contain the main content")
+	}
+	if alternative.Score > article.Score {
+		t.Errorf("Alternative score %.1f should not exceed the article score %.1f", alternative.Score, article.Score)
+	}
+	if alternative.WordCount == 0 || alternative.Confidence <= 0 || alternative.Confidence >= 1 {
+		t.Errorf("Unexpected alternative measures: %+v", alternative)
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := t.TempDir()
+
+	yamlConfig := `
+minContentLength: 50
+httpTimeout: 5s
+markdown: true
+blacklistKeywords: [sponsored]
+preserveTags: [iframe]
+allowedAttributes:
+  iframe: [src]
+rules:
+  - domain: example.com
+    content: [".story-body"]
+    transforms:
+      - selector: div.paragraph
+        rename: p
+`
+	jsonConfig := `{
+	"minContentLength": 50,
+	"httpTimeout": 5,
+	"markdown": true,
+	"blacklistKeywords": ["sponsored"],
+	"preserveTags": ["iframe"],
+	"allowedAttributes": {"iframe": ["src"]},
+	"rules": [{
+		"domain": "example.com",
+		"content": [".story-body"],
+		"transforms": [{"selector": "div.paragraph", "rename": "p"}]
+	}]
+}`
+
+	for name, data := range map[string]string{"config.yaml": yamlConfig, "config.json": jsonConfig} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(dir, name)
+			if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			cfg, err := LoadConfig(path)
+			if err != nil {
+				t.Fatalf("LoadConfig failed: %v", err)
+			}
+			if cfg.MinContentLength != 50 || cfg.HTTPTimeout != 5*time.Second || !cfg.Markdown {
+				t.Errorf("Settings not loaded: %+v", cfg)
+			}
+			if cfg.MaxPages != DefaultConfig().MaxPages {
+				t.Errorf("Absent settings should keep their defaults, got MaxPages %d", cfg.MaxPages)
+			}
+			if cfg.Blacklist[len(cfg.Blacklist)-1] != "sponsored" || len(cfg.Blacklist) != len(DefaultBlacklistKeywords())+1 ||
+				!reflect.DeepEqual(cfg.AllowedAttributes, map[string][]string{"iframe": {"src"}}) {
+				t.Errorf("Cleaning settings not loaded: %+v", cfg)
+			}
+
+			rule := cfg.Rules.Lookup("https://www.example.com/story")
+			if rule == nil || len(rule.Transforms) != 1 || rule.Content[0] != ".story-body" {
+				t.Errorf("Rule not loaded: %+v", rule)
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		data   string
+		field  string
+	}{
+		{"negative", FormatJSON, `{"minContentLength": -1}`, "minContentLength"},
+		{"wrong type", FormatJSON, `{"maxPages": "ten"}`, "maxPages"},
+		{"wrong nested type", FormatJSON, `{"rules": [{"domain": 5}]}`, "rules[0].domain"},
+		{"unknown field", FormatYAML, "maxPagez: 3", "maxPagez"},
+		{"bad duration", FormatYAML, "httpTimeout: soon", "httpTimeout"},
+		{"missing domain", FormatYAML, "rules:\n  - content: [article]", "rules[0].domain"},
+		{"bad selector", FormatYAML, "rules:\n  - domain: example.com\n    title: [\"h1[\"]", "rules[0].title[0]"},
+		{"bad transform", FormatJSON, `{"rules": [{"domain": "example.com", "transforms": [{"selector": "div"}]}]}`, "rules[0].transforms[0]"},
+		{"empty keyword", FormatYAML, "whitelistKeywords: [story, \"\"]", "whitelistKeywords[1]"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseConfig([]byte(tt.data), tt.format)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+
+			var configErr *ConfigError
+			if !errors.As(err, &configErr) {
+				t.Fatalf("Expected a *ConfigError, got %T: %v", err, err)
+			}
+			if configErr.Field != tt.field {
+				t.Errorf("Field = %q, want %q (%v)", configErr.Field, tt.field, err)
+			}
+			if !errors.Is(err, ErrInvalidConfig) {
+				t.Error("errors.Is(err, ErrInvalidConfig) should be true")
+			}
+		})
+	}
+}
+
+func TestParseConfig_YAML(t *testing.T) {
+	data := `
+defaults: &lists
+  - sponsored
+  - partner
+blacklistKeywords: *lists
+userAgent: >
+  NewsBot/1.0
+  (+https://example.com/bot)
+allowedAttributes: {iframe: [src], video: [poster]}
+rules:
+  - domain: example.com
+    content:
+      - |
+        .story-body
+`
+	_, err := ParseConfig([]byte(data), FormatYAML)
+	var configErr *ConfigError
+	if !errors.As(err, &configErr) || configErr.Field != "defaults" {
+		t.Fatalf("Expected an unknown field error for the anchor holder, got %v", err)
+	}
+
+	cfg, err := ParseConfig([]byte(strings.Replace(data, "defaults: &lists", "blacklist: &lists", 1)), FormatYAML)
+	if err != nil {
+		t.Fatalf("ParseConfig failed: %v", err)
+	}
+	if cfg.UserAgent != "NewsBot/1.0 (+https://example.com/bot)\n" {
+		t.Errorf("Folded scalar not loaded: %q", cfg.UserAgent)
+	}
+	if !reflect.DeepEqual(cfg.Blacklist, []string{"sponsored", "partner", "sponsored", "partner"}) {
+		t.Errorf("Aliased list not loaded: %v", cfg.Blacklist)
+	}
+	if !reflect.DeepEqual(cfg.AllowedAttributes, map[string][]string{"iframe": {"src"}, "video": {"poster"}}) {
+		t.Errorf("Flow mapping not loaded: %v", cfg.AllowedAttributes)
+	}
+	if rule := cfg.Rules.Lookup("example.com"); rule == nil || strings.TrimSpace(rule.Content[0]) != ".story-body" {
+		t.Errorf("Literal scalar not loaded: %+v", rule)
+	}
+
+	if _, err := ParseConfig([]byte("markdown: [true"), FormatYAML); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig for malformed YAML, got %v", err)
+	}
+}
+
+func TestWithConfig_Copy(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.Blacklist = make([]string, 1, 10)
+	cfg.Blacklist[0] = "comment"
+	cfg.AllowedAttributes = map[string][]string{"iframe": {"src"}}
+	cfg.Rules = NewRuleRegistry()
+
+	ext := New(
+		WithConfig(cfg),
+		WithBlacklistKeywords("sponsored"),
+		WithAllowedAttributes("iframe", "title"),
+		WithSiteRules(&SiteRule{Domain: "example.com"}),
+	)
+
+	if got := cfg.Blacklist[:2][1]; got != "" {
+		t.Errorf("Options wrote through to the caller's blacklist: %q", got)
+	}
+	if !reflect.DeepEqual(cfg.AllowedAttributes, map[string][]string{"iframe": {"src"}}) {
+		t.Errorf("Options modified the caller's attributes: %v", cfg.AllowedAttributes)
+	}
+	if cfg.Rules.Len() != 0 {
+		t.Errorf("Options registered rules in the caller's registry")
+	}
+	if ext.config.Rules.Lookup("example.com") == nil || len(ext.config.Blacklist) != 2 {
+		t.Errorf("Options after WithConfig were not applied: %+v", ext.config)
+	}
+
+	// Rules added after a shared registry go to a copy
+	shared := NewRuleRegistry(&SiteRule{Domain: "shared.com"})
+	ext = New(WithRuleRegistry(shared), WithSiteRules(&SiteRule{Domain: "example.com"}))
+	if shared.Len() != 1 || shared.Lookup("example.com") != nil {
+		t.Errorf("WithSiteRules registered rules in the shared registry")
+	}
+	if ext.config.Rules.Lookup("shared.com") == nil || ext.config.Rules.Lookup("example.com") == nil {
+		t.Errorf("Expected the shared and added rules, got %d rules", ext.config.Rules.Len())
+	}
+	if New(WithRuleRegistry(shared)).config.Rules != shared {
+		t.Error("WithRuleRegistry should keep the shared registry")
+	}
+	var none *RuleRegistry
+	if none.Len() != 0 {
+		t.Error("A nil registry should have no rules")
+	}
+
+	if New(WithConfig(nil)).config.MinContentLength != DefaultConfig().MinContentLength {
+		t.Error("A nil config should keep the defaults")
+	}
+}
+
+func TestNewFromFile_Reload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(data string) {
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	html := `<html><body><article>
+		<p>The city council approved the new transit plan on Monday after a debate that lasted well into the evening.</p>
+	</article></body></html>`
+
+	write("minContentLength: 1000\n")
+	ext, err := NewFromFile(path, WithMarkdown(true))
+	if err != nil {
+		t.Fatalf("NewFromFile failed: %v", err)
+	}
+	if _, err := ext.Extract(html); !errors.Is(err, ErrContentTooShort) {
+		t.Fatalf("Expected ErrContentTooShort, got %v", err)
+	}
+
+	write("minContentLength: 50\n")
+	if err := ext.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+	article, err := ext.Extract(html)
+	if err != nil {
+		t.Fatalf("Extract after reload failed: %v", err)
+	}
+	if article.Markdown == "" {
+		t.Error("Options should be reapplied on reload")
+	}
+
+	// An invalid file keeps the previous configuration
+	write("minContentLength: -5\n")
+	if err := ext.Reload(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("Expected ErrInvalidConfig, got %v", err)
+	}
+	if _, err := ext.Extract(html); err != nil {
+		t.Errorf("Extract after failed reload failed: %v", err)
+	}
+
+	if err := New().Reload(); err == nil {
+		t.Error("Reload without a file should fail")
+	}
+}
+
+func TestExtract_KeywordOptions(t *testing.T) {
+	html := `
+<!DOCTYPE html>
+<html>
+<body>
+	<article>
+		<p>The city council approved the new transit plan on Monday after a debate that lasted well into the evening and drew a large crowd of residents.</p>
+		<div class="sidebar-note">Editor's note: updated with the final vote.</div>
+		<p>Supporters said the plan would cut commute times, while opponents raised concerns about the cost and the pace of construction in older neighborhoods.</p>
+	</article>
+</body>
+</html>`
+
+	defaults, err := New().Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if strings.Contains(defaults.TextContent, "Editor's note") {
+		t.Error("Default blacklist should strip the sidebar note")
+	}
+
+	custom, err := New(WithoutBlacklistKeywords("sidebar")).Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if !strings.Contains(custom.TextContent, "Editor's note") {
+		t.Error("Removing the keyword should keep the sidebar note")
+	}
+
+	// Replacing the list affects only the extractor it is given to
+	replaced, err := New(WithBlacklist("legal")).Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if !strings.Contains(replaced.TextContent, "Editor's note") {
+		t.Error("Replaced blacklist should not contain the default keywords")
+	}
+	again, err := New().Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if strings.Contains(again.TextContent, "Editor's note") {
+		t.Error("Other extractors should keep the default blacklist")
+	}
+}
+
+func TestExtractWithURLContext(t *testing.T) {
+	html := `<html><body><article>
+		<p>The city council approved the new transit plan on Monday after a debate that lasted well into the evening and drew a large crowd of residents.</p>
+		<p>Supporters said the plan would cut commute times, while opponents raised concerns about the cost and the pace of construction in older neighborhoods.</p>
+	</article></body></html>`
+
+	article, err := New().ExtractContext(context.Background(), html)
+	if err != nil || article.WordCount == 0 {
+		t.Fatalf("ExtractContext failed: %v", err)
+	}
+
+	// An already cancelled context stops before the first stage
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = New().ExtractWithURLContext(ctx, html, "https://example.com/story")
+
+	var extractionErr *ExtractionError
+	if !errors.As(err, &extractionErr) {
+		t.Fatalf("Expected an *ExtractionError, got %v", err)
+	}
+	if extractionErr.Op != StageMetadata || extractionErr.URL != "https://example.com/story" {
+		t.Errorf("Unexpected error context: %v", err)
+	}
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected ErrTimeout and context.Canceled, got %v", err)
+	}
+
+	// Cancellation during a stage names that stage
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	ext := New(WithHook(AfterCandidate, func(state *State) error {
+		cancel()
+		return nil
+	}))
+	_, err = ext.ExtractContext(ctx, html)
+	if !errors.As(err, &extractionErr) || extractionErr.Op != HookName(AfterCandidate, 1) || !errors.Is(err, ErrTimeout) {
+		t.Errorf("Expected ErrTimeout from the hook stage, got %v", err)
+	}
+}
+
+func TestExtract_ExtractTimeout(t *testing.T) {
+	html := `<html><body><article>
+		<p>The city council approved the new transit plan on Monday after a debate that lasted well into the evening and drew a large crowd of residents.</p>
+	</article></body></html>`
+
+	ext := New(
+		WithExtractTimeout(10*time.Millisecond),
+		WithHook(BeforePreprocess, func(state *State) error {
+			<-state.Context().Done()
+			return nil
+		}),
+	)
+
+	_, err := ext.Extract(html)
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected ErrTimeout and context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestExtractFromURL_HTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/missing", http.StatusMovedPermanently)
+		case "/missing":
+			http.NotFound(w, r)
+		case "/busy":
+			w.Header().Set("Retry-After", "120")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case "/forbidden":
+			w.WriteHeader(http.StatusForbidden)
+		case "/slow":
+			time.Sleep(200 * time.Millisecond)
+		}
+	}))
+	defer server.Close()
+
+	ext := New(WithHTTPTimeout(50 * time.Millisecond))
+
+	_, err := ext.ExtractFromURL(context.Background(), server.URL+"/old")
+	var httpErr *HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatalf("Expected *HTTPError, got %v", err)
+	}
+	if httpErr.StatusCode != http.StatusNotFound || httpErr.URL != server.URL+"/missing" {
+		t.Errorf("Expected 404 at the final URL, got %d at %s", httpErr.StatusCode, httpErr.URL)
+	}
+	if !errors.Is(err, ErrHTTPRequest) || !IsNotFound(err) || IsRetryable(err) || IsBlocked(err) {
+		t.Errorf("404 classified wrongly: %v", err)
+	}
+
+	_, err = ext.ExtractFromURL(context.Background(), server.URL+"/busy")
+	if !errors.As(err, &httpErr) || httpErr.RetryAfter != 2*time.Minute {
+		t.Errorf("Expected a Retry-After of 2m, got %v", err)
+	}
+	if httpErr.Header.Get("Retry-After") != "120" {
+		t.Error("Expected the response headers on the error")
+	}
+	if !IsRetryable(err) || IsNotFound(err) || IsTimeout(err) {
+		t.Errorf("503 classified wrongly: %v", err)
+	}
+
+	_, err = ext.ExtractFromURL(context.Background(), server.URL+"/forbidden")
+	if !IsBlocked(err) || IsRetryable(err) {
+		t.Errorf("403 classified wrongly: %v", err)
+	}
+
+	_, err = ext.ExtractFromURL(context.Background(), server.URL+"/slow")
+	if !errors.Is(err, ErrTimeout) || !errors.Is(err, ErrHTTPRequest) || !IsTimeout(err) || !IsRetryable(err) {
+		t.Errorf("Timeout classified wrongly: %v", err)
+	}
+	if errors.As(err, &httpErr) && httpErr.StatusCode != 0 {
+		t.Errorf("Expected no status code for a timeout, got %d", httpErr.StatusCode)
+	}
+}
+
+func TestExtractFromURL_BodyError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/truncated":
+			// Promise more than is sent, then close the connection
+			w.Header().Set("Content-Length", "100000")
+			_, _ = w.Write([]byte("<html><body><article><p>The first half of the story"))
+		case "/reset":
+			w.Header().Set("Content-Length", "100000")
+			_, _ = w.Write([]byte("<html><body><article><p>The first half of the story"))
+			w.(http.Flusher).Flush()
+			conn, _, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			// Close with a reset instead of a FIN
+			if tcp, ok := conn.(*net.TCPConn); ok {
+				_ = tcp.SetLinger(0)
+			}
+			conn.Close()
+		}
+	}))
+	defer server.Close()
+
+	for _, path := range []string{"/truncated", "/reset"} {
+		t.Run(path, func(t *testing.T) {
+			_, err := New().ExtractFromURL(context.Background(), server.URL+path)
+
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) || httpErr.Err == nil || httpErr.StatusCode != 0 {
+				t.Fatalf("Expected an *HTTPError wrapping the transport error, got %v", err)
+			}
+			if httpErr.URL != server.URL+path {
+				t.Errorf("HTTPError.URL = %s, want %s", httpErr.URL, server.URL+path)
+			}
+			if !IsRetryable(err) || errors.Is(err, ErrInvalidHTML) {
+				t.Errorf("Body failure classified wrongly: %v", err)
+			}
+		})
+	}
+}
+
+func TestHTTPError_Transport(t *testing.T) {
+	dnsErr := &HTTPError{Err: fmt.Errorf("fetching URL: %w", &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true})}
+	if !errors.Is(dnsErr, ErrHTTPRequest) || IsRetryable(dnsErr) || IsNotFound(dnsErr) || IsTimeout(dnsErr) {
+		t.Errorf("DNS failure classified wrongly: %v", dnsErr)
+	}
+
+	refused := &HTTPError{Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}}
+	if !IsRetryable(refused) {
+		t.Error("Expected a refused connection to be retryable")
+	}
+
+	cancelled := &HTTPError{Err: context.Canceled}
+	if IsRetryable(cancelled) || IsTimeout(cancelled) {
+		t.Error("Expected a cancelled request not to be retryable")
+	}
+
+	truncated := &HTTPError{Err: io.ErrUnexpectedEOF}
+	if !IsRetryable(truncated) {
+		t.Error("Expected a truncated body to be retryable")
+	}
+
+	if IsRetryable(ErrNoContent) || IsNotFound(nil) {
+		t.Error("Expected non-HTTP errors not to be classified")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	published := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	corrected := published.Add(2 * time.Hour)
+
+	before := &Article{
+		Title:       "Acme Reports Record Quarter",
+		Author:      "Jane Doe",
+		PublishedAt: &published,
+		Content: `<div><p>Acme reported revenue of $10 million for the quarter.</p>
+<p>The company  expects growth to <a href="https://acme.example/outlook?utm_source=wire">continue</a>.</p>
+<p>Shares rose five percent in early trading.</p></div>`,
+	}
+	after := &Article{
+		Title:       "Acme Reports Record  Quarter",
+		Author:      "John Doe",
+		PublishedAt: &corrected,
+		Content: `<div><p>Acme reported revenue of $12 million for the quarter.</p>
+<p>The company expects growth to <a href="https://acme.example/outlook">continue</a>.</p>
+<p>Analysts had expected a smaller increase.</p>
+<p>Shares rose five percent in early trading.</p></div>`,
+	}
+
+	d := Diff(before, after)
+
+	fields := map[string]FieldChange{}
+	for _, change := range d.Fields {
+		fields[change.Field] = change
+	}
+	if !fields[FieldTitle].Cosmetic || fields[FieldAuthor].Cosmetic || fields[FieldPublishedAt].New != corrected.Format(time.RFC3339) {
+		t.Errorf("Unexpected field changes: %+v", d.Fields)
+	}
+
+	want := []struct {
+		typ      ChangeType
+		old, new int
+		cosmetic bool
+	}{
+		{ChangeEdit, 0, 0, false},
+		{ChangeEdit, 1, 1, true},
+		{ChangeInsert, -1, 2, false},
+	}
+	if len(d.Paragraphs) != len(want) {
+		t.Fatalf("Expected %d paragraph changes, got %+v", len(want), d.Paragraphs)
+	}
+	for i, w := range want {
+		got := d.Paragraphs[i]
+		if got.Type != w.typ || got.OldIndex != w.old || got.NewIndex != w.new || got.Cosmetic != w.cosmetic {
+			t.Errorf("Change %d = %+v, want %+v", i, got, w)
+		}
+	}
+	if d.Paragraphs[0].ChangedWords != 2 || d.ChangedWords != 8 {
+		t.Errorf("Expected 2 and 8 changed words, got %d and %d", d.Paragraphs[0].ChangedWords, d.ChangedWords)
+	}
+	if d.Similarity <= 0.5 || d.Similarity >= 1 {
+		t.Errorf("Expected a similarity between 0.5 and 1, got %v", d.Similarity)
+	}
+	if !d.IsMaterial(DefaultMaterialThresholds()) {
+		t.Error("Expected the correction to be material")
+	}
+
+	if d := Diff(before, before); d.Changed() || d.Similarity != 1 {
+		t.Errorf("Expected no changes between equal articles, got %+v", d)
+	}
+}
+
+func TestArticleDiff_IsMaterial(t *testing.T) {
+	before := &Article{Title: "Storm closes schools", TextContent: "Schools closed on Monday.\n\nBuses will not run."}
+	after := &Article{Title: "Storm closes  schools", TextContent: "Schools  closed on Monday.\n\nBuses will not run."}
+
+	d := Diff(before, after)
+	if !d.Changed() || d.IsMaterial(DefaultMaterialThresholds()) {
+		t.Errorf("Expected a cosmetic change, got %+v", d)
+	}
+
+	after.TextContent = "Schools closed on Tuesday.\n\nBuses will not run."
+	d = Diff(before, after)
+	if !d.IsMaterial(DefaultMaterialThresholds()) {
+		t.Errorf("Expected a changed date in a short article to be material, got %+v", d)
+	}
+	if d.IsMaterial(MaterialThresholds{MinChangedWords: 3}) {
+		t.Error("Expected a two-word edit not to be material with MinChangedWords 3 alone")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	story := `The Senate on Tuesday passed a bill that would fund the government through the
+end of the fiscal year, averting a shutdown that had been set to begin at midnight. The
+measure now goes to the House, where leaders said they expected a vote later in the week.
+Lawmakers from both parties praised the compromise, though several conservatives objected
+to the spending levels and vowed to oppose it when it reaches the floor.`
+
+	html := `<html><body><article>` + "<p>" + story + "</p>" + `</article></body></html>`
+	article, err := New().Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if article.Fingerprint != NewFingerprint(article.TextContent) || article.Fingerprint.IsZero() {
+		t.Errorf("Expected the fingerprint of the text content, got %+v", article.Fingerprint)
+	}
+
+	// Link footnotes are left out, so copies linking elsewhere match
+	linked := func(href string) Fingerprint {
+		html := `<html><body><article><p><a href="` + href + `">` + story + `</a></p></article></body></html>`
+		article, err := New(WithLinkFootnotes(true)).ExtractWithURL(html, "https://example.com/story")
+		if err != nil {
+			t.Fatalf("Extract failed: %v", err)
+		}
+		if !strings.Contains(article.TextContent, href) {
+			t.Errorf("Expected a footnote for %s, got %q", href, article.TextContent)
+		}
+		return article.Fingerprint
+	}
+	if linked("https://a.example/senate") != article.Fingerprint || linked("https://b.example/senate") != article.Fingerprint {
+		t.Error("Expected link footnotes not to change the fingerprint")
+	}
+
+	reformatted := NewFingerprint(strings.ToUpper(strings.Join(strings.Fields(story), "  ")))
+	if reformatted.Hash != article.Fingerprint.Hash {
+		t.Error("Expected case and whitespace not to change the hash")
+	}
+
+	edited := NewFingerprint(strings.Replace(story, "later in the week", "on Thursday", 1))
+	unrelated := NewFingerprint(`The city council voted to expand the bike lane network downtown,
+adding twelve miles of protected lanes over the next two years. Residents were divided.`)
+
+	ix := NewDuplicateIndex()
+	ix.Add("original", article.Fingerprint)
+	ix.Add("edited", edited)
+	ix.Add("unrelated", unrelated)
+	ix.Add("empty", NewFingerprint(""))
+
+	found := ix.Find(reformatted, 0.8)
+	if len(found) != 2 || found[0].ID != "original" || found[0].Similarity != 1 || found[1].ID != "edited" {
+		t.Errorf("Expected the original and the edited copy, got %+v", found)
+	}
+
+	ix.Remove("edited")
+	if ix.Len() != 3 || len(ix.Find(edited, 0.8)) != 1 {
+		t.Errorf("Expected only the original after removing the edited copy, got %+v", ix.Find(edited, 0.8))
+	}
+
+	data, err := json.Marshal(article.Fingerprint)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Fingerprint
+	if err := json.Unmarshal(data, &decoded); err != nil || decoded != article.Fingerprint {
+		t.Errorf("Expected the fingerprint to round-trip through JSON, got %+v (%v)", decoded, err)
+	}
+}
+
+func TestExtract_Language(t *testing.T) {
+	body := `<body><article>
+<p>Der Stadtrat hat am Dienstag einen neuen Haushalt beschlossen, der in den kommenden Jahren mehr Geld für Schulen vorsieht.</p>
+<p>Der Bürgermeister sagte, der Plan sei das Ergebnis monatelanger Verhandlungen mit Bürgern, Unternehmen und Vereinen der Stadt.</p>
+</article></body>`
+
+	tests := []struct {
+		name   string
+		head   string
+		want   string
+		source string
+	}{
+		{"declared", `<html lang="de-DE"><head><meta property="og:locale" content="de_DE"></head>`, "de", "html lang"},
+		{"missing", `<html><head></head>`, "de", "text"},
+		{"contradicting", `<html lang="en"><head><meta property="og:locale" content="de_DE"></head>`, "de", "text"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article, err := New(WithDebug(true)).Extract(tt.head + body + `</html>`)
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			if article.Language != tt.want || article.LanguageConfidence <= 0.5 {
+				t.Errorf("Language = %q (%v), want %q", article.Language, article.LanguageConfidence, tt.want)
+			}
+			sources := map[string]string{}
+			for _, field := range article.Trace.Metadata {
+				sources[field.Name] = field.Source
+			}
+			if sources["language"] != tt.source {
+				t.Errorf("Expected the language from %q, got %q", tt.source, sources["language"])
+			}
+		})
+	}
+}
+
+func TestExtract_CJK(t *testing.T) {
+	paragraph := "市议会周二批准了新的预算，将在未来两年增加公共交通、学校和医疗方面的支出。市长表示，该计划是与居民、企业和社区团体数月谈判的结果。"
+	html := `<html><body>
+<div class="sidebar"><p>热门新闻</p><p>推荐阅读</p></div>
+<article>` + strings.Repeat("<p>"+paragraph+"</p>", 4) + `</article>
+</body></html>`
+
+	article, err := New().Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	han := 0
+	for _, r := range article.TextContent {
+		if unicode.Is(unicode.Han, r) {
+			han++
+		}
+	}
+	if article.WordCount != han {
+		t.Errorf("Expected one word per Han character (%d), got %d", han, article.WordCount)
+	}
+	if article.Language != "zh" {
+		t.Errorf("Language = %q, want zh", article.Language)
+	}
+	if want := time.Duration(float64(han) / 255 * float64(time.Minute)).Round(time.Second); article.ReadingTime != want {
+		t.Errorf("ReadingTime = %v, want %v", article.ReadingTime, want)
+	}
+}
+
+func TestExtract_Summary(t *testing.T) {
+	body := `<article>
+<p>WASHINGTON (AP) — The Senate passed a bill on Tuesday that would expand funding for rural hospitals across the country.</p>
+<p>The rural hospitals bill passed the Senate with support from both parties after weeks of negotiation.</p>
+<p>Several senators had questions about the cost, which budget analysts put at four billion dollars.</p>
+<p>Supporters said rural hospitals have closed at a record pace, leaving patients far from emergency care.</p>
+<p>The House is expected to take up the hospitals bill next month.</p>
+</article>`
+
+	tests := []struct {
+		name        string
+		description string
+		excerpt     string
+	}{
+		{"no description", "", "The Senate passed a bill on Tuesday"},
+		{"matching description", "Senators passed funding for rural hospitals, which have closed at a record pace.", "Senators passed funding"},
+		{"boilerplate description", "Breaking news, analysis and opinion from the nation's capital, updated daily.", "The Senate passed a bill on Tuesday"},
+		{"short description", "Rural hospitals bill passes.", "The Senate passed a bill on Tuesday"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			head := `<title>Senate passes rural hospitals bill</title>`
+			if tt.description != "" {
+				head += `<meta property="og:description" content="` + tt.description + `">`
+			}
+			article, err := New().Extract(`<html><head>` + head + `</head><body>` + body + `</body></html>`)
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			if !strings.HasPrefix(article.Excerpt, tt.excerpt) {
+				t.Errorf("Expected the excerpt to start with %q, got %q", tt.excerpt, article.Excerpt)
+			}
+		})
+	}
+
+	article, err := New(WithSummarySentences(2)).Extract(`<html><body>` + body + `</body></html>`)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	want := "The Senate passed a bill on Tuesday that would expand funding for rural hospitals across the country. " +
+		"The rural hospitals bill passed the Senate with support from both parties after weeks of negotiation."
+	if article.Summary != want {
+		t.Errorf("Summary = %q, want %q", article.Summary, want)
+	}
+
+	// Summaries are opt-in
+	article, err = New().Extract(`<html><body>` + body + `</body></html>`)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if article.Summary != "" {
+		t.Errorf("Expected no summary by default, got %q", article.Summary)
+	}
+}
+
+func TestExtract_Keywords(t *testing.T) {
+	html := `<html><head>
+<title>Council approves transit budget</title>
+<meta name="news_keywords" content="Light rail, Public transport">
+</head><body><article>
+<p>The city council approved a new transit budget on Tuesday after a long debate.</p>
+<h2>Bus routes</h2>
+<p>The transit budget adds bus routes and extends light rail service to the airport.</p>
+<p>Council members said the light rail extension would open in 2027, on schedule.</p>
+<p>Riders have asked for more bus routes for years, and the council's transit budget answers them.</p>
+</article></body></html>`
+
+	article, err := New(WithMaxKeywords(4)).Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if len(article.Keywords) != 4 {
+		t.Fatalf("Expected 4 keywords, got %+v", article.Keywords)
+	}
+
+	found := map[string]Keyword{}
+	for _, kw := range article.Keywords {
+		found[kw.Text] = kw
+	}
+	for _, want := range []string{"transit budget", "light rail", "Public transport"} {
+		if _, ok := found[want]; !ok {
+			t.Errorf("Expected keyword %q, got %+v", want, article.Keywords)
+		}
+	}
+	if !found["light rail"].Tagged || found["transit budget"].Tagged {
+		t.Errorf("Expected only the meta tag keywords to be tagged, got %+v", article.Keywords)
+	}
+	if article.Keywords[0].Score != 1 {
+		t.Errorf("Expected the best keyword to score 1, got %+v", article.Keywords[0])
+	}
+
+	article, err = New(WithMaxKeywords(0)).Extract(html)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if article.Keywords != nil {
+		t.Errorf("Expected no keywords, got %+v", article.Keywords)
+	}
+}
+
+func TestExtract_LeadImageScoring(t *testing.T) {
+	body := `<body>
+<header><img src="/static/logo.png" width="240" height="60"></header>
+<article>
+	<p class="byline"><img src="/authors/jane.jpg" class="avatar" width="80" height="80"> By Jane Doe</p>
+	<figure><img src="/photos/harbor.jpg" width="1200" height="800" alt="The harbor"><figcaption>The harbor at dawn.</figcaption></figure>
+	<p>The harbor reopened on Monday after a month of repairs to the breakwater, officials said.</p>
+	<p>Fishing boats returned to their moorings by the afternoon, and the ferry resumed its schedule.</p>
+	<img src="https://tracker.example.net/pixel.gif" width="1" height="1">
+</article>
+</body>`
+
+	tests := []struct {
+		name   string
+		head   string
+		want   string
+		source string
+	}{
+		{"no meta image", ``, "https://example.com/photos/harbor.jpg", "article image"},
+		{"relative og:image", `<meta property="og:image" content="/photos/og.jpg">`, "https://example.com/photos/og.jpg", "og:image"},
+		{"logo og:image", `<meta property="og:image" content="https://example.com/assets/logo-default.png">`, "https://example.com/photos/harbor.jpg", "article image"},
+		{"twitter:image fallback", `<meta property="og:image" content="javascript:void(0)"><meta name="twitter:image" content="https://cdn.example.com/t.jpg">`, "https://cdn.example.com/t.jpg", "twitter:image"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article, err := New(WithDebug(true)).ExtractWithURL(`<html><head>`+tt.head+`</head>`+body+`</html>`, "https://example.com/news/harbor")
+			if err != nil {
+				t.Fatalf("Extract failed: %v", err)
+			}
+			if article.LeadImage == nil || article.LeadImage.URL != tt.want {
+				t.Fatalf("LeadImage = %+v, want %s", article.LeadImage, tt.want)
+			}
+			sources := map[string]string{}
+			for _, field := range article.Trace.Metadata {
+				sources[field.Name] = field.Source
+			}
+			if sources["leadImage"] != tt.source {
+				t.Errorf("Expected the lead image from %q, got %q", tt.source, sources["leadImage"])
+			}
+		})
+	}
+
+	// Without a page URL a relative og:image cannot be resolved
+	article, err := New().Extract(`<html><head><meta property="og:image" content="/photos/og.jpg"></head>` + body + `</html>`)
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if article.LeadImage == nil || article.LeadImage.URL != "/photos/harbor.jpg" {
+		t.Errorf("Expected the content image, got %+v", article.LeadImage)
+	}
+}
+
+func TestExtract_Images(t *testing.T) {
+	html := `<html><body><article>
+<figure><img src="/photos/harbor.jpg" width="1200" height="800" alt="The harbor"><figcaption>The harbor at dawn. Photo: Reuters</figcaption></figure>
+<p>The harbor reopened on Monday after a month of repairs to the breakwater, officials said.</p>
+<p><img src="data:image/gif;base64,R0lGOD" data-src="/photos/boats.jpg" alt="Boats"> Fishing boats returned to their moorings by the afternoon.</p>
+<figure><img src="https://cdn.example.com/ferry.jpg"><figcaption>The ferry resumed its schedule. <cite>Jane Doe</cite></figcaption></figure>
+<p>The ferry resumed its schedule, and the harbor master thanked the crews for their work.</p>
+<img src="https://tracker.example.net/pixel.gif" width="1" height="1">
+</article></body></html>`
+
+	article, err := New().ExtractWithURL(html, "https://example.com/news/harbor")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+
+	want := []Image{
+		{URL: "https://example.com/photos/harbor.jpg", Width: 1200, Height: 800, Alt: "The harbor", Caption: "The harbor at dawn.", Credit: "Photo: Reuters", Position: 1},
+		{URL: "https://example.com/photos/boats.jpg", Alt: "Boats", Position: 2},
+		{URL: "https://cdn.example.com/ferry.jpg", Caption: "The ferry resumed its schedule.", Credit: "Jane Doe", Position: 3},
+	}
+	if len(article.Images) != len(want) {
+		t.Fatalf("Images = %+v, want %+v", article.Images, want)
+	}
+	for i := range want {
+		if article.Images[i] != want[i] {
+			t.Errorf("Images[%d] = %+v, want %+v", i, article.Images[i], want[i])
+		}
+	}
+
+	if lead := article.LeadImage; lead == nil || *lead != want[0] {
+		t.Errorf("LeadImage = %+v, want %+v", article.LeadImage, want[0])
+	}
+
+	// A lead image from the meta tags matches its content image even when
+	// the URLs differ in scheme, host case or fragment
+	meta := strings.Replace(html, "<body>", `<head><meta property="og:image" content="http://EXAMPLE.com/photos/boats.jpg#main"></head><body>`, 1)
+	article, err = New().ExtractWithURL(meta, "https://example.com/news/harbor")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if lead := article.LeadImage; lead == nil || lead.Position != 2 {
+		t.Errorf("Expected the og:image to match the second content image, got %+v", article.LeadImage)
+	}
+
+	caption := strings.Replace(meta, "/photos/boats.jpg#main", "/photos/harbor.jpg", 1)
+	article, err = New().ExtractWithURL(caption, "https://example.com/news/harbor")
+	if err != nil {
+		t.Fatalf("Extract failed: %v", err)
+	}
+	if lead := article.LeadImage; lead == nil || lead.Position != 1 || lead.Credit != "Photo: Reuters" {
+		t.Errorf("Expected the og:image to get the caption of the first content image, got %+v", article.LeadImage)
+	}
+}
@@ -0,0 +1,111 @@
+package extractor
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/LeadNewswire/article-extractor/internal/fingerprint"
+)
+
+// Fingerprint identifies an article's text for duplicate detection. Text
+// is normalized first: lowercased, with punctuation and whitespace removed.
+type Fingerprint struct {
+	// Hash is the hex-encoded SHA-256 of the normalized text, equal for
+	// exact copies
+	Hash string `json:"hash,omitempty"`
+
+	// SimHash is a 64-bit SimHash of the text's three-word shingles,
+	// differing in few bits for near-duplicates
+	SimHash uint64 `json:"simhash,string"`
+}
+
+// NewFingerprint computes the fingerprint of a text.
+func NewFingerprint(text string) Fingerprint {
+	return Fingerprint{
+		Hash:    fingerprint.Hash(text),
+		SimHash: fingerprint.SimHash(text),
+	}
+}
+
+// IsZero reports whether the fingerprint is of text without words.
+func (f Fingerprint) IsZero() bool {
+	return f.Hash == ""
+}
+
+// Similarity returns the similarity (0-1) of two fingerprints: 1 for exact
+// copies, otherwise the fraction of equal SimHash bits. Unrelated texts
+// score around 0.5 and lightly edited copies typically above 0.9. Zero
+// fingerprints are similar to nothing.
+func (f Fingerprint) Similarity(other Fingerprint) float64 {
+	switch {
+	case f.IsZero() || other.IsZero():
+		return 0
+	case f.Hash == other.Hash:
+		return 1
+	}
+	return fingerprint.Similarity(f.SimHash, other.SimHash)
+}
+
+// Duplicate is a near-duplicate found in a DuplicateIndex.
+type Duplicate struct {
+	// ID is the identifier the fingerprint was added with
+	ID string `json:"id"`
+
+	// Similarity is the similarity (0-1) to the searched fingerprint
+	Similarity float64 `json:"similarity"`
+}
+
+// DuplicateIndex is an in-memory index of fingerprints for finding
+// near-duplicates. It is safe for concurrent use.
+type DuplicateIndex struct {
+	mu      sync.RWMutex
+	entries map[string]Fingerprint
+}
+
+// NewDuplicateIndex creates an empty index.
+func NewDuplicateIndex() *DuplicateIndex {
+	return &DuplicateIndex{entries: make(map[string]Fingerprint)}
+}
+
+// Add adds or replaces the fingerprint with the given ID.
+func (ix *DuplicateIndex) Add(id string, f Fingerprint) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.entries[id] = f
+}
+
+// Remove removes the fingerprint with the given ID.
+func (ix *DuplicateIndex) Remove(id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	delete(ix.entries, id)
+}
+
+// Len returns the number of fingerprints in the index.
+func (ix *DuplicateIndex) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.entries)
+}
+
+// Find returns the fingerprints at least threshold similar to f, most
+// similar first.
+func (ix *DuplicateIndex) Find(f Fingerprint, threshold float64) []Duplicate {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	var found []Duplicate
+	for id, entry := range ix.entries {
+		if similarity := f.Similarity(entry); similarity > 0 && similarity >= threshold {
+			found = append(found, Duplicate{ID: id, Similarity: similarity})
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Similarity != found[j].Similarity {
+			return found[i].Similarity > found[j].Similarity
+		}
+		return found[i].ID < found[j].ID
+	})
+	return found
+}
@@ -6,10 +6,9 @@ toolchain go1.24.12
 
 require (
 	github.com/PuerkitoBio/goquery v1.11.0
+	github.com/andybalholm/cascadia v1.3.3
 	golang.org/x/net v0.49.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
-require (
-	github.com/andybalholm/cascadia v1.3.3 // indirect
-	golang.org/x/text v0.33.0 // indirect
-)
+require golang.org/x/text v0.33.0 // indirect
@@ -0,0 +1,172 @@
+// Package classify decides what kind of page a document is.
+package classify
+
+import (
+	"math"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/LeadNewswire/article-extractor/internal/keywords"
+	"github.com/LeadNewswire/article-extractor/internal/metadata"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// Thresholds used by IsProbablyArticle.
+const (
+	// MinParagraphLength is the text length above which a paragraph counts
+	// towards the paragraph score
+	MinParagraphLength = 140
+
+	// MinParagraphScore is the paragraph score at which a page is
+	// considered an article
+	MinParagraphScore = 20.0
+
+	// MaxLinkDensity is the page link density above which a page is
+	// considered a listing
+	MaxLinkDensity = 0.5
+)
+
+// articleSchemaTypes are the schema.org types of article pages.
+var articleSchemaTypes = map[string]bool{
+	"Article":               true,
+	"NewsArticle":           true,
+	"AnalysisNewsArticle":   true,
+	"BackgroundNewsArticle": true,
+	"OpinionNewsArticle":    true,
+	"ReportageNewsArticle":  true,
+	"ReviewNewsArticle":     true,
+	"BlogPosting":           true,
+	"LiveBlogPosting":       true,
+	"TechArticle":           true,
+	"ScholarlyArticle":      true,
+	"Report":                true,
+}
+
+// listingSchemaTypes are the schema.org types of pages that list or
+// describe other content.
+var listingSchemaTypes = map[string]bool{
+	"CollectionPage":    true,
+	"ItemList":          true,
+	"SearchResultsPage": true,
+	"ProfilePage":       true,
+}
+
+var hiddenStyleRegex = regexp.MustCompile(`(?i)(display\s*:\s*none|visibility\s*:\s*hidden)`)
+
+// IsProbablyArticle makes a quick guess at whether a page is an article,
+// without running the extraction pipeline. It combines og:type, JSON-LD
+// @type, the number and length of visible paragraphs and the page's link
+// density. The document is not modified.
+func IsProbablyArticle(doc *goquery.Document) bool {
+	if PageLinkDensity(doc) > MaxLinkDensity {
+		return false
+	}
+
+	// Pages declared as listings are never articles on paragraph text
+	// alone, pages declared as articles need less text
+	hasArticleType, hasListingType := declaredTypes(doc)
+	if hasListingType && !hasArticleType {
+		return false
+	}
+
+	score := ParagraphScore(doc)
+	if hasArticleType || metadata.ExtractOGType(doc) == "article" {
+		return score >= MinParagraphScore/2
+	}
+	return score >= MinParagraphScore
+}
+
+// declaredTypes reports whether the page's JSON-LD declares an article
+// type and whether it declares a listing type.
+func declaredTypes(doc *goquery.Document) (article, listing bool) {
+	for _, t := range metadata.ExtractSchemaTypes(doc) {
+		article = article || articleSchemaTypes[t]
+		listing = listing || listingSchemaTypes[t]
+	}
+	return article, listing
+}
+
+// ParagraphScore scores the visible, likely-content paragraphs of a page,
+// following Readability's isProbablyReaderable: each p, pre or article
+// element longer than MinParagraphLength characters adds the square root
+// of its excess length. Paragraphs inside list items are ignored.
+func ParagraphScore(doc *goquery.Document) float64 {
+	var score float64
+
+	doc.Find("p, pre, article").Each(func(_ int, sel *goquery.Selection) {
+		if sel.ParentsFiltered("li").Length() > 0 || !isVisible(sel) || isUnlikely(sel) {
+			return
+		}
+
+		length := utf8.RuneCountInString(strings.TrimSpace(sel.Text()))
+		if length < MinParagraphLength {
+			return
+		}
+		score += math.Sqrt(float64(length - MinParagraphLength))
+	})
+
+	return score
+}
+
+// PageLinkDensity returns the ratio of link text to all visible text in
+// the page body, ignoring scripts and styles.
+func PageLinkDensity(doc *goquery.Document) float64 {
+	body := doc.Find("body")
+	if body.Length() == 0 {
+		return 0
+	}
+
+	var total, links int
+	var walk func(n *html.Node, inLink bool)
+	walk = func(n *html.Node, inLink bool) {
+		switch n.Type {
+		case html.TextNode:
+			length := len(strings.TrimSpace(n.Data))
+			total += length
+			if inLink {
+				links += length
+			}
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "noscript", "template":
+				return
+			case "a":
+				inLink = true
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child, inLink)
+		}
+	}
+	walk(body.Nodes[0], false)
+
+	if total == 0 {
+		return 0
+	}
+	return float64(links) / float64(total)
+}
+
+// isVisible reports whether neither an element nor its ancestors are
+// hidden by a style or the hidden attribute.
+func isVisible(sel *goquery.Selection) bool {
+	for n := sel.Nodes[0]; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		for _, attr := range n.Attr {
+			if attr.Key == "hidden" || (attr.Key == "style" && hiddenStyleRegex.MatchString(attr.Val)) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// isUnlikely reports whether an element's class and id suggest it is not
+// content.
+func isUnlikely(sel *goquery.Selection) bool {
+	class, _ := sel.Attr("class")
+	id, _ := sel.Attr("id")
+	match := class + " " + id
+	return keywords.IsBlacklisted(match) && !keywords.IsWhitelisted(match)
+}
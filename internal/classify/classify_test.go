@@ -0,0 +1,189 @@
+package classify
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LeadNewswire/article-extractor/internal/scorer"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// longParagraph is a paragraph well above MinParagraphLength.
+const longParagraph = `<p>The committee met on Thursday to review the proposal in detail, hearing from residents, business owners and the transport authority about the expected effects on traffic, air quality and local trade over the coming years.</p>`
+
+func parse(t *testing.T, html string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestIsProbablyArticle(t *testing.T) {
+	listing := `<ul>` + strings.Repeat(`<li><a href="/story">A headline linking to another story on the site</a></li>`, 30) + `</ul>`
+
+	tests := []struct {
+		name     string
+		html     string
+		expected bool
+	}{
+		{
+			name:     "long paragraphs",
+			html:     `<html><body><article>` + strings.Repeat(longParagraph, 4) + `</article></body></html>`,
+			expected: true,
+		},
+		{
+			name:     "short page",
+			html:     `<html><body><p>Welcome to our site.</p></body></html>`,
+			expected: false,
+		},
+		{
+			name:     "link listing",
+			html:     `<html><body>` + listing + longParagraph + `</body></html>`,
+			expected: false,
+		},
+		{
+			name:     "declared article with little text",
+			html:     `<html><head><meta property="og:type" content="article"></head><body>` + strings.Repeat(longParagraph, 2) + `</body></html>`,
+			expected: true,
+		},
+		{
+			name:     "undeclared page with little text",
+			html:     `<html><body>` + strings.Repeat(longParagraph, 2) + `</body></html>`,
+			expected: false,
+		},
+		{
+			name:     "declared listing with long paragraphs",
+			html:     `<html><head><script type="application/ld+json">{"@type":"CollectionPage"}</script></head><body>` + strings.Repeat(longParagraph, 4) + `</body></html>`,
+			expected: false,
+		},
+		{
+			name:     "short paragraphs in a multi-byte script",
+			html:     `<html><body>` + strings.Repeat(`<p>`+strings.Repeat("委员会审议了提案", 12)+`</p>`, 4) + `</body></html>`,
+			expected: false,
+		},
+		{
+			name:     "hidden and unlikely paragraphs",
+			html:     `<html><body><div style="display:none">` + strings.Repeat(longParagraph, 3) + `</div>` + strings.Replace(strings.Repeat(longParagraph, 3), "<p>", `<p class="comment">`, -1) + `</body></html>`,
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsProbablyArticle(parse(t, tt.html)); result != tt.expected {
+				t.Errorf("IsProbablyArticle = %v, want %v (score %.1f, link density %.2f)",
+					result, tt.expected, ParagraphScore(parse(t, tt.html)), PageLinkDensity(parse(t, tt.html)))
+			}
+		})
+	}
+}
+
+func TestPageLinkDensity(t *testing.T) {
+	doc := parse(t, `<html><body><p>abcdef <a href="#">ghij</a></p><script>var longScriptText = 1;</script></body></html>`)
+
+	if density := PageLinkDensity(doc); density != 0.4 {
+		t.Errorf("PageLinkDensity = %v, want 0.4", density)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	article := `<article>` + strings.Repeat(longParagraph, 4) + `</article>`
+	teasers := strings.Repeat(`<div class="teaser"><h3><a href="/story">Headline</a></h3>`+longParagraph+`</div>`, 8)
+
+	tests := []struct {
+		name     string
+		url      string
+		html     string
+		expected PageType
+	}{
+		{
+			name:     "news article",
+			url:      "https://example.com/news/2024/05/01/council-vote",
+			html:     `<html><head><script type="application/ld+json">{"@type":"NewsArticle"}</script></head><body>` + article + `</body></html>`,
+			expected: PageTypeNews,
+		},
+		{
+			name:     "press release",
+			url:      "https://example.com/releases/acme-launches-widget",
+			html:     `<html><body><article><p>SPRINGFIELD, May 1, 2024 /PRNewswire/ -- Acme today announced a new widget.</p>` + strings.Repeat(longParagraph, 3) + `<p>Media Contact: Jane Doe</p></article></body></html>`,
+			expected: PageTypePressRelease,
+		},
+		{
+			name:     "blog post",
+			url:      "https://example.com/blog/my-first-post",
+			html:     `<html><head><meta property="og:type" content="article"></head><body>` + article + `</body></html>`,
+			expected: PageTypeBlog,
+		},
+		{
+			name:     "video page",
+			url:      "https://example.com/watch/12345",
+			html:     `<html><head><meta property="og:type" content="video.other"></head><body><iframe src="https://www.youtube.com/embed/abc"></iframe><p>Short description.</p></body></html>`,
+			expected: PageTypeVideo,
+		},
+		{
+			name:     "gallery",
+			url:      "https://example.com/photos/festival",
+			html:     `<html><body>` + strings.Repeat(`<figure><img src="a.jpg"><figcaption>Crowd</figcaption></figure>`, 10) + `</body></html>`,
+			expected: PageTypeGallery,
+		},
+		{
+			name:     "listing",
+			url:      "https://example.com/tag/politics",
+			html:     `<html><body>` + teasers + `</body></html>`,
+			expected: PageTypeListing,
+		},
+		{
+			name:     "author page",
+			url:      "https://example.com/author/jane-doe",
+			html:     `<html><head><meta property="og:type" content="profile"></head><body><h1>Jane Doe</h1><p>Jane covers politics.</p></body></html>`,
+			expected: PageTypeAuthor,
+		},
+		{
+			name:     "error page",
+			url:      "https://example.com/missing",
+			html:     `<html><head><title>Page Not Found</title></head><body><h1>Oops</h1><p>We could not find that page.</p></body></html>`,
+			expected: PageTypeError,
+		},
+		{
+			name:     "generic article",
+			url:      "https://example.com/about-our-process",
+			html:     `<html><body>` + article + `</body></html>`,
+			expected: PageTypeArticle,
+		},
+		{
+			name:     "nothing",
+			url:      "",
+			html:     `<html><body><p>Hello.</p></body></html>`,
+			expected: PageTypeOther,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signals := CollectSignals(parse(t, tt.html), tt.url)
+			if result := signals.Classify(nil); result != tt.expected {
+				t.Errorf("Classify = %q, want %q (signals %+v)", result, tt.expected, signals)
+			}
+		})
+	}
+}
+
+func TestClassify_ScoreDistribution(t *testing.T) {
+	scoreMap := scorer.NewScoreMap()
+	doc := parse(t, `<html><body>`+strings.Repeat(`<div>`+longParagraph+`</div>`, 6)+`</body></html>`)
+	doc.Find("div").Each(func(_ int, sel *goquery.Selection) {
+		ns := scorer.NewNodeScore(sel)
+		ns.SetScore(30)
+		scoreMap.Set(sel, ns)
+	})
+
+	signals := &Signals{ParagraphScore: MinParagraphScore}
+	if result := signals.Classify(scoreMap); result != PageTypeListing {
+		t.Errorf("Classify with many similar candidates = %q, want %q", result, PageTypeListing)
+	}
+	if result := signals.Classify(nil); result != PageTypeArticle {
+		t.Errorf("Classify without a score map = %q, want %q", result, PageTypeArticle)
+	}
+}
@@ -0,0 +1,281 @@
+package classify
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/LeadNewswire/article-extractor/internal/metadata"
+	"github.com/LeadNewswire/article-extractor/internal/scorer"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// PageType is the kind of a page.
+type PageType string
+
+// Page types.
+const (
+	PageTypeNews         PageType = "news"
+	PageTypePressRelease PageType = "press_release"
+	PageTypeBlog         PageType = "blog"
+	PageTypeVideo        PageType = "video"
+	PageTypeGallery      PageType = "gallery"
+	PageTypeListing      PageType = "listing"
+	PageTypeAuthor       PageType = "author"
+	PageTypeError        PageType = "error"
+
+	// PageTypeArticle is an article with no more specific type
+	PageTypeArticle PageType = "article"
+
+	// PageTypeOther is a page that matches no type
+	PageTypeOther PageType = "other"
+)
+
+// pageTypeOrder breaks ties between equally scored types.
+var pageTypeOrder = []PageType{
+	PageTypeError,
+	PageTypePressRelease,
+	PageTypeAuthor,
+	PageTypeListing,
+	PageTypeVideo,
+	PageTypeGallery,
+	PageTypeNews,
+	PageTypeBlog,
+	PageTypeArticle,
+}
+
+// Signal weights.
+const (
+	strongSignal = 3.0
+	schemaSignal = 2.0
+	urlSignal    = 1.5
+	weakSignal   = 1.0
+
+	// minTypeScore is the score a type needs to be chosen
+	minTypeScore = 1.0
+)
+
+// Score distribution thresholds.
+const (
+	// similarCandidateRatio is the fraction of the top score at which a
+	// candidate counts as similar to the top candidate
+	similarCandidateRatio = 0.7
+
+	// minSimilarCandidates is the number of similar candidates that
+	// suggests a listing of teasers
+	minSimilarCandidates = 5
+
+	// minGalleryImages is the number of images that suggests a gallery
+	minGalleryImages = 5
+)
+
+// schemaPageTypes maps schema.org types to page types.
+var schemaPageTypes = map[string]PageType{
+	"NewsArticle":           PageTypeNews,
+	"AnalysisNewsArticle":   PageTypeNews,
+	"BackgroundNewsArticle": PageTypeNews,
+	"OpinionNewsArticle":    PageTypeNews,
+	"ReportageNewsArticle":  PageTypeNews,
+	"ReviewNewsArticle":     PageTypeNews,
+	"BlogPosting":           PageTypeBlog,
+	"Blog":                  PageTypeBlog,
+	"PressRelease":          PageTypePressRelease,
+	"VideoObject":           PageTypeVideo,
+	"ImageGallery":          PageTypeGallery,
+	"MediaGallery":          PageTypeGallery,
+	"ProfilePage":           PageTypeAuthor,
+	"CollectionPage":        PageTypeListing,
+	"ItemList":              PageTypeListing,
+	"SearchResultsPage":     PageTypeListing,
+	"Article":               PageTypeArticle,
+}
+
+// urlPatterns maps URL path patterns to page types.
+var urlPatterns = []struct {
+	pattern  *regexp.Regexp
+	pageType PageType
+}{
+	{regexp.MustCompile(`/(press-releases?|news-releases?|pressreleases?|press|pr)/`), PageTypePressRelease},
+	{regexp.MustCompile(`/(authors?|profiles?|people|staff|contributors?)/[^/]+/?$`), PageTypeAuthor},
+	{regexp.MustCompile(`/(tags?|topics?|category|categories|section|archives?|search)(/|$)|/page/\d+/?$`), PageTypeListing},
+	{regexp.MustCompile(`/(videos?|watch|tv)(/|$)`), PageTypeVideo},
+	{regexp.MustCompile(`/(galler(y|ies)|photos|slideshows?|pictures)(/|$)`), PageTypeGallery},
+	{regexp.MustCompile(`/blogs?/|^/\d{4}/\d{2}/[^/]+/?$`), PageTypeBlog},
+	{regexp.MustCompile(`/news/|/\d{4}/\d{1,2}/\d{1,2}/`), PageTypeNews},
+	{regexp.MustCompile(`/(404|not-found|error)(/|\.html?$|$)`), PageTypeError},
+}
+
+// errorTitleRegex matches titles of error pages.
+var errorTitleRegex = regexp.MustCompile(`(?i)\b(404|page not found|not found|page (does not|doesn't) exist|error)\b`)
+
+// pressReleaseRegex matches phrases typical of press releases.
+var pressReleaseRegex = regexp.MustCompile(`(?i)(for immediate release|/prnewswire/|business wire|globe newswire|accesswire|presswire|media contacts?:|press contacts?:|investor contacts?:)`)
+
+// videoHostRegex matches the embed URLs of video hosts.
+var videoHostRegex = regexp.MustCompile(`(?i)(youtube\.com|youtube-nocookie\.com|youtu\.be|vimeo\.com|dailymotion\.com|jwplayer|brightcove)`)
+
+// Signals holds the page-level facts used for page-type classification.
+// They must be collected before preprocessing removes scripts and links.
+type Signals struct {
+	// OGType is the lowercased og:type
+	OGType string
+
+	// SchemaTypes are the JSON-LD @type values
+	SchemaTypes []string
+
+	// Path is the lowercased URL path
+	Path string
+
+	// Title is the title tag and first h1 of the page
+	Title string
+
+	// LinkDensity is the page link density
+	LinkDensity float64
+
+	// ParagraphScore is the Readability-style paragraph score
+	ParagraphScore float64
+
+	// Images is the number of images in the body
+	Images int
+
+	// Videos is the number of video elements and video embeds
+	Videos int
+
+	// PressReleaseMarkers is the number of press release phrases in the body
+	PressReleaseMarkers int
+}
+
+// CollectSignals gathers the classification signals of a page. The
+// document is not modified.
+func CollectSignals(doc *goquery.Document, pageURL string) *Signals {
+	signals := &Signals{
+		OGType:         metadata.ExtractOGType(doc),
+		SchemaTypes:    metadata.ExtractSchemaTypes(doc),
+		Title:          strings.TrimSpace(doc.Find("title").First().Text() + " " + doc.Find("h1").First().Text()),
+		LinkDensity:    PageLinkDensity(doc),
+		ParagraphScore: ParagraphScore(doc),
+		Images:         doc.Find("body img").Length(),
+	}
+
+	if u, err := url.Parse(pageURL); err == nil {
+		signals.Path = strings.ToLower(u.Path)
+	}
+
+	signals.Videos = doc.Find("video").Length()
+	doc.Find("iframe[src], embed[src]").Each(func(_ int, sel *goquery.Selection) {
+		if src, _ := sel.Attr("src"); videoHostRegex.MatchString(src) {
+			signals.Videos++
+		}
+	})
+
+	signals.PressReleaseMarkers = len(pressReleaseRegex.FindAllStringIndex(doc.Find("body").Text(), -1))
+
+	return signals
+}
+
+// Classify determines the page type from the signals and, if scoreMap is
+// not nil, the distribution of content candidate scores.
+func (s *Signals) Classify(scoreMap *scorer.ScoreMap) PageType {
+	scores := make(map[PageType]float64)
+
+	// Declared types
+	for _, t := range s.SchemaTypes {
+		if pageType, ok := schemaPageTypes[t]; ok {
+			scores[pageType] += schemaSignal
+		}
+	}
+	switch {
+	case s.OGType == "article":
+		scores[PageTypeArticle] += weakSignal
+	case s.OGType == "profile":
+		scores[PageTypeAuthor] += schemaSignal
+	case strings.HasPrefix(s.OGType, "video"):
+		scores[PageTypeVideo] += schemaSignal
+	}
+
+	// URL patterns
+	for _, p := range urlPatterns {
+		if p.pattern.MatchString(s.Path) {
+			scores[p.pageType] += urlSignal
+		}
+	}
+
+	// Page content
+	hasText := s.ParagraphScore >= MinParagraphScore/2
+	if errorTitleRegex.MatchString(s.Title) && !hasText {
+		scores[PageTypeError] += strongSignal
+	}
+	if s.PressReleaseMarkers > 0 {
+		scores[PageTypePressRelease] += schemaSignal
+		if s.PressReleaseMarkers > 1 {
+			scores[PageTypePressRelease] += weakSignal
+		}
+	}
+	if s.LinkDensity > MaxLinkDensity {
+		scores[PageTypeListing] += schemaSignal
+	}
+	if s.Videos > 0 && !hasText {
+		scores[PageTypeVideo] += urlSignal
+	}
+	if s.Images >= minGalleryImages && s.ParagraphScore < MinParagraphScore {
+		scores[PageTypeGallery] += urlSignal
+	}
+	if s.ParagraphScore >= MinParagraphScore {
+		scores[PageTypeArticle] += weakSignal
+	}
+
+	// One dominant candidate suggests an article, many similar ones a
+	// listing of teasers
+	if dominant, similar := distribution(scoreMap); similar >= minSimilarCandidates {
+		scores[PageTypeListing] += urlSignal
+	} else if dominant {
+		scores[PageTypeArticle] += weakSignal
+	}
+
+	best, bestScore := PageTypeOther, 0.0
+	for _, pageType := range pageTypeOrder {
+		if scores[pageType] > bestScore {
+			best, bestScore = pageType, scores[pageType]
+		}
+	}
+	if bestScore < minTypeScore {
+		return PageTypeOther
+	}
+
+	// Article pages with news or blog signals take the more specific type
+	if best == PageTypeArticle {
+		switch {
+		case scores[PageTypeNews] > 0 && scores[PageTypeNews] >= scores[PageTypeBlog]:
+			return PageTypeNews
+		case scores[PageTypeBlog] > 0:
+			return PageTypeBlog
+		}
+	}
+
+	return best
+}
+
+// distribution reports whether the top candidate dominates the second and
+// how many candidates score close to the top candidate.
+func distribution(scoreMap *scorer.ScoreMap) (dominant bool, similar int) {
+	if scoreMap == nil {
+		return false, 0
+	}
+
+	candidates := scoreMap.GetCandidatesByScore()
+	if len(candidates) == 0 || candidates[0].GetScore() <= 0 {
+		return false, 0
+	}
+
+	top := candidates[0].GetScore()
+	if len(candidates) == 1 || top > candidates[1].GetScore()*2 {
+		dominant = true
+	}
+	for _, c := range candidates[1:] {
+		if c.GetScore() >= top*similarCandidateRatio {
+			similar++
+		}
+	}
+
+	return dominant, similar
+}
@@ -1,9 +1,11 @@
 package cleaner
 
 import (
+	"context"
 	"strings"
 	"testing"
 
+	"github.com/LeadNewswire/article-extractor/internal/keywords"
 	"github.com/PuerkitoBio/goquery"
 )
 
@@ -212,6 +214,24 @@ func TestCleanAttributes(t *testing.T) {
 	}
 }
 
+func TestCleanAttributes_CodeLanguage(t *testing.T) {
+	html := `<div><pre class="highlight"><code class="hljs language-go">x := 1</code></pre><pre class="wide">plain</pre></div>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	CleanAttributes(doc.Selection)
+
+	if class, _ := doc.Find("code").Attr("class"); class != "language-go" {
+		t.Errorf("Code class = %q, want %q", class, "language-go")
+	}
+	if _, exists := doc.Find("pre").Eq(1).Attr("class"); exists {
+		t.Error("Pre class without a language should be removed")
+	}
+}
+
 func TestConvertRelativeURLs(t *testing.T) {
 	html := `
 <html>
@@ -320,3 +340,108 @@ func TestGetCleanText(t *testing.T) {
 		t.Error("GetCleanText should normalize whitespace")
 	}
 }
+
+func TestPreprocessWithOptions(t *testing.T) {
+	html := `<html><body>
+		<div class="story">Story text that should stay in the document.</div>
+		<div class="sponsored-box">Buy now</div>
+		<div class="partner-widget">Partner offers</div>
+		<iframe src="https://video.example.com/embed/1"></iframe>
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	PreprocessWithOptions(doc, &Options{
+		Keywords: keywords.NewSet(
+			append(keywords.DefaultBlacklist(), "sponsored"),
+			keywords.DefaultWhitelist(),
+			keywords.DefaultWhitelistWeight,
+			keywords.DefaultBlacklistWeight,
+		),
+		WidgetClasses: []string{"partner-widget"},
+		PreserveTags:  map[string]bool{"iframe": true},
+	})
+
+	if doc.Find(".sponsored-box").Length() != 0 {
+		t.Error("Extra blacklist keyword should remove the element")
+	}
+	if doc.Find(".partner-widget").Length() != 0 {
+		t.Error("Extra widget class should remove the element")
+	}
+	if doc.Find("iframe").Length() != 1 {
+		t.Error("Preserved iframe should be kept")
+	}
+	if !strings.Contains(doc.Text(), "Story text") {
+		t.Error("Content should be kept")
+	}
+}
+
+func TestPostprocessWithOptions(t *testing.T) {
+	html := `<div><p>Text</p><iframe src="https://video.example.com/embed/1" allowfullscreen style="border:0"></iframe></div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	content := doc.Find("div")
+	PostprocessWithOptions(content, &Options{
+		PreserveTags:      map[string]bool{"iframe": true},
+		AllowedAttributes: map[string][]string{"iframe": {"src", "allowfullscreen"}},
+	})
+
+	iframe := content.Find("iframe")
+	if iframe.Length() != 1 {
+		t.Fatal("Preserved empty iframe should be kept")
+	}
+	if src, _ := iframe.Attr("src"); src == "" {
+		t.Error("Allowed src attribute should be kept")
+	}
+	if _, ok := iframe.Attr("allowfullscreen"); !ok {
+		t.Error("Allowed allowfullscreen attribute should be kept")
+	}
+	if _, ok := iframe.Attr("style"); ok {
+		t.Error("style attribute should be removed")
+	}
+}
+
+func TestPreprocessWithOptions_Cancelled(t *testing.T) {
+	html := `<html><body><div class="article">Story text</div><div class="sidebar">Links</div></body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	PreprocessWithOptions(doc, &Options{Context: ctx})
+
+	if doc.Find(".sidebar").Length() != 1 {
+		t.Error("A cancelled context should stop the element loops")
+	}
+}
+
+func TestPostprocess_LazyImages(t *testing.T) {
+	html := `<div>
+<img src="data:image/gif;base64,R0lGOD" data-src="/photos/a.jpg">
+<img srcset="/photos/b-480.jpg 480w, /photos/b-960.jpg 960w">
+<img src="/photos/c.jpg" data-src="/photos/c-large.jpg">
+</div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Postprocess(doc.Find("div"))
+
+	imgs := doc.Find("img")
+	for i, want := range []string{"/photos/a.jpg", "/photos/b-480.jpg", "/photos/c.jpg"} {
+		if src := imgs.Eq(i).AttrOr("src", ""); src != want {
+			t.Errorf("image %d: src = %q, want %q", i, src, want)
+		}
+	}
+	if _, ok := imgs.Eq(0).Attr("data-src"); ok {
+		t.Error("data-src attribute should be removed")
+	}
+}
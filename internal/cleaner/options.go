@@ -0,0 +1,89 @@
+package cleaner
+
+import (
+	"context"
+
+	"github.com/LeadNewswire/article-extractor/internal/keywords"
+	"github.com/LeadNewswire/article-extractor/internal/trace"
+)
+
+// Options configures the cleaning steps that make extraction decisions.
+// A nil *Options uses the defaults.
+type Options struct {
+	// Trace records removed elements and sibling decisions (optional)
+	Trace *trace.Trace
+
+	// Context stops the long element loops early once it is done
+	// (optional). The caller checks the context for the error.
+	Context context.Context
+
+	// Keywords holds the class and id keywords and weights (nil uses the
+	// defaults)
+	Keywords *keywords.Set
+
+	// WidgetClasses are extra class names of widgets to remove
+	WidgetClasses []string
+
+	// PreserveTags are tags that cleaning never removes
+	PreserveTags map[string]bool
+
+	// AllowedAttributes are extra attributes kept on content elements,
+	// keyed by tag ("*" for every tag)
+	AllowedAttributes map[string][]string
+}
+
+// trace returns the trace recorder, or nil if tracing is disabled.
+func (o *Options) trace() *trace.Trace {
+	if o == nil {
+		return nil
+	}
+	return o.Trace
+}
+
+// cancelled reports whether the options' context is done.
+func (o *Options) cancelled() bool {
+	return o != nil && o.Context != nil && o.Context.Err() != nil
+}
+
+// keywords returns the keyword set, or nil for the defaults.
+func (o *Options) keywords() *keywords.Set {
+	if o == nil {
+		return nil
+	}
+	return o.Keywords
+}
+
+// widgetClasses returns the built-in and extra widget class names.
+func (o *Options) widgetClasses() []string {
+	if o == nil || len(o.WidgetClasses) == 0 {
+		return widgetExactClasses
+	}
+	return append(append([]string{}, widgetExactClasses...), o.WidgetClasses...)
+}
+
+// preserves reports whether elements with the tag must be kept.
+func (o *Options) preserves(tag string) bool {
+	return o != nil && o.PreserveTags[tag]
+}
+
+// allowsAttribute reports whether an attribute is kept on a tag.
+func (o *Options) allowsAttribute(tag, attr string) bool {
+	for _, list := range [][]string{allowedAttributes[tag], allowedAttributes["*"]} {
+		for _, a := range list {
+			if a == attr {
+				return true
+			}
+		}
+	}
+	if o == nil {
+		return false
+	}
+	for _, list := range [][]string{o.AllowedAttributes[tag], o.AllowedAttributes["*"]} {
+		for _, a := range list {
+			if a == attr {
+				return true
+			}
+		}
+	}
+	return false
+}
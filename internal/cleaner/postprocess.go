@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/LeadNewswire/article-extractor/internal/images"
+	"github.com/LeadNewswire/article-extractor/internal/render"
 	"github.com/PuerkitoBio/goquery"
 )
 
@@ -12,7 +14,11 @@ import (
 var allowedAttributes = map[string][]string{
 	"a":   {"href", "title"},
 	"img": {"src", "alt", "title", "width", "height"},
-	"*":   {}, // Remove all attributes from other elements
+	// Embeds are removed by preprocessing unless preserved
+	"iframe": {"src", "title", "width", "height"},
+	"embed":  {"src", "width", "height"},
+	"object": {"data", "width", "height"},
+	"*":      {}, // Remove all attributes from other elements
 }
 
 // Tags to preserve in output.
@@ -49,22 +55,41 @@ var preserveTags = map[string]bool{
 
 // Postprocess cleans up the extracted content.
 func Postprocess(sel *goquery.Selection) {
+	PostprocessWithOptions(sel, nil)
+}
+
+// PostprocessWithOptions cleans up the extracted content, keeping the
+// options' preserved tags and allowed attributes.
+func PostprocessWithOptions(sel *goquery.Selection, opts *Options) {
 	// Remove unwanted elements
-	RemoveUnwantedFromContent(sel)
+	removeUnwantedFromContent(sel, opts)
+
+	// Move lazy-loaded image sources to src before cleaning drops them
+	promoteLazySources(sel)
 
 	// Clean attributes
-	CleanAttributes(sel)
+	cleanAttributes(sel, opts)
 
 	// Remove empty elements
-	RemoveEmptyElements(sel)
+	removeEmptyElements(sel, opts)
 
 	// Normalize whitespace (done at text extraction level)
 }
 
 // RemoveUnwantedFromContent removes ads, social widgets, etc. from content.
 func RemoveUnwantedFromContent(sel *goquery.Selection) {
+	removeUnwantedFromContent(sel, nil)
+}
+
+// removeUnwantedFromContent removes unwanted elements that are not
+// preserved.
+func removeUnwantedFromContent(sel *goquery.Selection, opts *Options) {
 	// Remove scripts and styles that might have survived
-	sel.Find("script, style, noscript").Remove()
+	for _, tag := range []string{"script", "style", "noscript"} {
+		if !opts.preserves(tag) {
+			sel.Find(tag).Remove()
+		}
+	}
 
 	// Remove elements with certain classes/ids
 	unwantedPatterns := []string{
@@ -93,40 +118,50 @@ func RemoveUnwantedFromContent(sel *goquery.Selection) {
 	})
 }
 
+// promoteLazySources sets the src of lazy-loaded images, whose src is
+// missing or a placeholder data URI, from their data-src or srcset.
+func promoteLazySources(sel *goquery.Selection) {
+	sel.Find("img").Each(func(_ int, img *goquery.Selection) {
+		src := strings.TrimSpace(img.AttrOr("src", ""))
+		if src != "" && !strings.HasPrefix(strings.ToLower(src), "data:") {
+			return
+		}
+		if lazy := images.Source(img); lazy != "" {
+			img.SetAttr("src", lazy)
+		}
+	})
+}
+
 // CleanAttributes removes unnecessary attributes from elements.
 func CleanAttributes(sel *goquery.Selection) {
-	sel.Find("*").Each(func(_ int, el *goquery.Selection) {
+	cleanAttributes(sel, nil)
+}
+
+// cleanAttributes removes attributes that neither the defaults nor the
+// options allow.
+func cleanAttributes(sel *goquery.Selection, opts *Options) {
+	sel.Find("*").EachWithBreak(func(_ int, el *goquery.Selection) bool {
+		if opts.cancelled() {
+			return false
+		}
+
 		tag := dom.GetTagName(el)
-		allowedForTag := allowedAttributes[tag]
-		allowedForAll := allowedAttributes["*"]
 
 		// Get all attributes
 		if len(el.Nodes) == 0 {
-			return
+			return true
 		}
 
 		node := el.Nodes[0]
 		var attrsToRemove []string
 
-		for _, attr := range node.Attr {
-			// Check if attribute is allowed
-			allowed := false
-			for _, a := range allowedForTag {
-				if attr.Key == a {
-					allowed = true
-					break
-				}
-			}
-			if !allowed {
-				for _, a := range allowedForAll {
-					if attr.Key == a {
-						allowed = true
-						break
-					}
-				}
-			}
+		var lang string
+		if tag == "pre" || tag == "code" {
+			lang = render.LanguageClass(dom.GetAttribute(el, "class"))
+		}
 
-			if !allowed {
+		for _, attr := range node.Attr {
+			if !opts.allowsAttribute(tag, attr.Key) {
 				attrsToRemove = append(attrsToRemove, attr.Key)
 			}
 		}
@@ -135,19 +170,34 @@ func CleanAttributes(sel *goquery.Selection) {
 		for _, attr := range attrsToRemove {
 			el.RemoveAttr(attr)
 		}
+
+		// Keep code language hints, normalized to class="language-x"
+		if lang != "" {
+			el.SetAttr("class", "language-"+lang)
+		}
+		return true
 	})
 }
 
 // RemoveEmptyElements removes empty elements from the content.
 func RemoveEmptyElements(sel *goquery.Selection) {
+	removeEmptyElements(sel, nil)
+}
+
+// removeEmptyElements removes empty elements that are not preserved.
+func removeEmptyElements(sel *goquery.Selection, opts *Options) {
 	// Iterate multiple times to handle nested empty elements
-	for i := 0; i < 3; i++ {
-		sel.Find("*").Each(func(_ int, el *goquery.Selection) {
+	for i := 0; i < 3 && !opts.cancelled(); i++ {
+		sel.Find("*").EachWithBreak(func(_ int, el *goquery.Selection) bool {
+			if opts.cancelled() {
+				return false
+			}
+
 			tag := dom.GetTagName(el)
 
-			// Skip self-closing elements
-			if tag == "br" || tag == "hr" || tag == "img" {
-				return
+			// Skip self-closing and preserved elements
+			if tag == "br" || tag == "hr" || tag == "img" || opts.preserves(tag) {
+				return true
 			}
 
 			// Check if empty
@@ -159,6 +209,7 @@ func RemoveEmptyElements(sel *goquery.Selection) {
 			if text == "" && (html == "" || isOnlyWhitespace(html)) {
 				el.Remove()
 			}
+			return true
 		})
 	}
 }
@@ -188,7 +239,7 @@ func ConvertRelativeURLs(sel *goquery.Selection, baseURL string) {
 	// Convert src in images
 	sel.Find("img[src]").Each(func(_ int, img *goquery.Selection) {
 		src, _ := img.Attr("src")
-		if src != "" && !isAbsoluteURL(src) {
+		if src != "" && !isAbsoluteURL(src) && !strings.HasPrefix(src, "data:") {
 			img.SetAttr("src", resolveURL(baseURL, src))
 		}
 	})
@@ -5,7 +5,7 @@ import (
 	"strings"
 
 	"github.com/LeadNewswire/article-extractor/internal/dom"
-	"github.com/LeadNewswire/article-extractor/internal/keywords"
+	"github.com/LeadNewswire/article-extractor/internal/trace"
 	"github.com/PuerkitoBio/goquery"
 )
 
@@ -37,23 +37,29 @@ var hiddenStyleRegex = regexp.MustCompile(`(?i)(display\s*:\s*none|visibility\s*
 
 // Preprocess performs initial cleanup on the document.
 func Preprocess(doc *goquery.Document) {
+	PreprocessWithOptions(doc, nil)
+}
+
+// PreprocessWithOptions performs initial cleanup on the document,
+// recording removed elements in the options' trace.
+func PreprocessWithOptions(doc *goquery.Document, opts *Options) {
 	// Remove script, style, and other non-content tags
-	RemoveUnwantedTags(doc)
+	removeUnwantedTags(doc, opts)
 
 	// Remove hidden elements
-	RemoveHiddenElements(doc)
+	removeHiddenElements(doc, opts)
 
 	// Remove known non-content widgets (AI widgets, chatbots, etc.)
-	RemoveKnownWidgets(doc)
+	removeKnownWidgets(doc, opts)
 
 	// Strip unlikely candidates
-	StripUnlikelyCandidates(doc)
+	stripUnlikelyCandidates(doc, opts)
 
 	// Convert data-articlebody content to paragraphs (for sites like Times of India)
 	ConvertDataArticleBodyToParagraphs(doc)
 
 	// Convert divs to paragraphs where appropriate
-	ConvertToParagraphs(doc)
+	convertToParagraphs(doc, opts)
 }
 
 // ConvertDataArticleBodyToParagraphs converts [data-articlebody] elements to proper article structure.
@@ -128,25 +134,46 @@ func ConvertDataArticleBodyToParagraphs(doc *goquery.Document) {
 
 // RemoveUnwantedTags removes script, style, and other non-content tags.
 func RemoveUnwantedTags(doc *goquery.Document) {
+	removeUnwantedTags(doc, nil)
+}
+
+// removeUnwantedTags removes non-content tags that are not preserved.
+func removeUnwantedTags(doc *goquery.Document, opts *Options) {
 	for _, tag := range removeTagsList {
-		doc.Find(tag).Remove()
+		if !opts.preserves(tag) {
+			doc.Find(tag).Remove()
+		}
 	}
 }
 
 // RemoveHiddenElements removes elements that are hidden via CSS.
 func RemoveHiddenElements(doc *goquery.Document) {
+	removeHiddenElements(doc, nil)
+}
+
+// removeHiddenElements removes hidden elements, recording them in the trace.
+func removeHiddenElements(doc *goquery.Document, opts *Options) {
+	tr := opts.trace()
+
 	doc.Find("[style]").Each(func(_ int, sel *goquery.Selection) {
 		style, _ := sel.Attr("style")
 		if hiddenStyleRegex.MatchString(style) {
+			tr.AddRemoval(sel, trace.ReasonHidden, "style")
 			sel.Remove()
 		}
 	})
 
 	// Remove elements with hidden attribute
-	doc.Find("[hidden]").Remove()
+	doc.Find("[hidden]").Each(func(_ int, sel *goquery.Selection) {
+		tr.AddRemoval(sel, trace.ReasonHidden, "hidden attribute")
+		sel.Remove()
+	})
 
 	// Remove aria-hidden elements
-	doc.Find("[aria-hidden='true']").Remove()
+	doc.Find("[aria-hidden='true']").Each(func(_ int, sel *goquery.Selection) {
+		tr.AddRemoval(sel, trace.ReasonHidden, "aria-hidden")
+		sel.Remove()
+	})
 }
 
 // widgetClassPatterns are CSS class patterns that identify non-content widgets.
@@ -167,61 +194,101 @@ var widgetExactClasses = []string{
 // RemoveKnownWidgets removes known non-content widgets like AI assistants, chatbots, etc.
 // These are removed unconditionally because they never contain article content.
 func RemoveKnownWidgets(doc *goquery.Document) {
+	removeKnownWidgets(doc, nil)
+}
+
+// removeKnownWidgets removes known widgets, recording them in the trace.
+func removeKnownWidgets(doc *goquery.Document, opts *Options) {
+	tr := opts.trace()
+
+	classes := opts.widgetClasses()
+
 	// First, remove elements with exact widget class names
-	for _, className := range widgetExactClasses {
-		doc.Find("." + className).Remove()
+	for _, className := range classes {
+		doc.Find("." + className).Each(func(_ int, sel *goquery.Selection) {
+			tr.AddRemoval(sel, trace.ReasonWidget, className)
+			sel.Remove()
+		})
 	}
 
 	// Then, check for widget patterns but be careful not to remove article elements
-	doc.Find("*").Each(func(_ int, sel *goquery.Selection) {
+	doc.Find("*").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		if opts.cancelled() {
+			return false
+		}
+
 		// Don't remove article, main, or body elements
 		tag := dom.GetTagName(sel)
 		if tag == "article" || tag == "main" || tag == "body" || tag == "html" {
-			return
+			return true
 		}
 
 		class := dom.GetAttribute(sel, "class")
 		id := dom.GetAttribute(sel, "id")
 
 		// Check for exact matches in class list
-		for _, cls := range widgetExactClasses {
+		for _, cls := range classes {
 			if strings.Contains(" "+class+" ", " "+cls+" ") {
+				tr.AddRemoval(sel, trace.ReasonWidget, cls)
 				sel.Remove()
-				return
+				return true
 			}
 		}
 
 		// Check pattern matches on id only (more restrictive)
 		if id != "" && widgetClassPatterns.MatchString(id) {
+			tr.AddRemoval(sel, trace.ReasonWidget, id)
 			sel.Remove()
-			return
 		}
+		return true
 	})
 }
 
 // StripUnlikelyCandidates removes elements unlikely to contain content.
 func StripUnlikelyCandidates(doc *goquery.Document) {
+	stripUnlikelyCandidates(doc, nil)
+}
+
+// StripUnlikelyCandidatesWithOptions removes elements unlikely to contain
+// content, matching class and id values against the options' keywords.
+func StripUnlikelyCandidatesWithOptions(doc *goquery.Document, opts *Options) {
+	stripUnlikelyCandidates(doc, opts)
+}
+
+// stripUnlikelyCandidates removes unlikely candidates, recording them in the trace.
+func stripUnlikelyCandidates(doc *goquery.Document, opts *Options) {
+	tr := opts.trace()
+	kw := opts.keywords()
+
 	// Remove unlikely tags
 	for _, tag := range unlikelyTags {
+		if opts.preserves(tag) {
+			continue
+		}
 		doc.Find(tag).Each(func(_ int, sel *goquery.Selection) {
 			// Check if it has a positive class/id that might indicate content
 			class := dom.GetAttribute(sel, "class")
 			id := dom.GetAttribute(sel, "id")
 
-			if keywords.IsWhitelisted(class) || keywords.IsWhitelisted(id) {
+			if kw.IsWhitelisted(class) || kw.IsWhitelisted(id) {
 				return // Keep this element
 			}
 
+			tr.AddRemoval(sel, trace.ReasonUnlikelyTag, tag)
 			sel.Remove()
 		})
 	}
 
 	// Remove elements with negative class/id patterns
-	doc.Find("*").Each(func(_ int, sel *goquery.Selection) {
+	doc.Find("*").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		if opts.cancelled() {
+			return false
+		}
+
 		// Don't remove body, html, or article-like elements
 		tag := dom.GetTagName(sel)
-		if tag == "body" || tag == "html" || tag == "article" || tag == "main" {
-			return
+		if tag == "body" || tag == "html" || tag == "article" || tag == "main" || opts.preserves(tag) {
+			return true
 		}
 
 		class := dom.GetAttribute(sel, "class")
@@ -229,27 +296,38 @@ func StripUnlikelyCandidates(doc *goquery.Document) {
 		combined := class + " " + id
 
 		// Skip if whitelisted
-		if keywords.IsWhitelisted(combined) {
-			return
+		if kw.IsWhitelisted(combined) {
+			return true
 		}
 
 		// Remove if blacklisted and not containing much text
-		if keywords.IsBlacklisted(combined) {
+		if match := kw.BlacklistMatch(combined); match != "" {
 			textLen := dom.GetTextLength(sel)
 			linkDensity := dom.CalculateLinkDensity(sel)
 
 			// Remove if short text or high link density
 			if textLen < 200 || linkDensity > 0.5 {
+				tr.AddRemoval(sel, trace.ReasonBlacklisted, match)
 				sel.Remove()
 			}
 		}
+		return true
 	})
 }
 
 // ConvertToParagraphs converts div and span elements that look like paragraphs.
 func ConvertToParagraphs(doc *goquery.Document) {
+	convertToParagraphs(doc, nil)
+}
+
+// convertToParagraphs converts paragraph-like elements, stopping early if
+// the options' context is done.
+func convertToParagraphs(doc *goquery.Document, opts *Options) {
 	// Find divs that have no block-level children
-	doc.Find("div, span").Each(func(_ int, sel *goquery.Selection) {
+	doc.Find("div, span").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		if opts.cancelled() {
+			return false
+		}
 		if !hasBlockChild(sel) {
 			// Convert to p if it has meaningful text
 			text := dom.GetText(sel)
@@ -257,8 +335,13 @@ func ConvertToParagraphs(doc *goquery.Document) {
 				convertToP(sel)
 			}
 		}
+		return true
 	})
 
+	if opts.cancelled() {
+		return
+	}
+
 	// Handle br-separated content in divs
 	doc.Find("div").Each(func(_ int, sel *goquery.Selection) {
 		html, _ := sel.Html()
@@ -9,6 +9,12 @@ import (
 
 // MergeSiblings merges qualifying sibling elements into the content.
 func MergeSiblings(topCandidate *goquery.Selection, topScore float64, minParagraphLength int) *goquery.Selection {
+	return MergeSiblingsWithOptions(topCandidate, topScore, minParagraphLength, nil)
+}
+
+// MergeSiblingsWithOptions merges qualifying sibling elements into the
+// content, recording each decision in the options' trace.
+func MergeSiblingsWithOptions(topCandidate *goquery.Selection, topScore float64, minParagraphLength int, opts *Options) *goquery.Selection {
 	if topCandidate == nil || topCandidate.Length() == 0 {
 		return topCandidate
 	}
@@ -41,7 +47,9 @@ func MergeSiblings(topCandidate *goquery.Selection, topScore float64, minParagra
 		}
 
 		// Check if sibling should be merged
-		if shouldMergeSibling(sibling, threshold, minParagraphLength) {
+		merge, reason, score := shouldMergeSibling(sibling, threshold, minParagraphLength, opts.keywords())
+		opts.trace().AddSibling(sibling, merge, reason, score)
+		if merge {
 			html, _ := sibling.Html()
 			mergedContent = append(mergedContent, html)
 		}
@@ -57,8 +65,9 @@ func MergeSiblings(topCandidate *goquery.Selection, topScore float64, minParagra
 	return topCandidate
 }
 
-// shouldMergeSibling determines if a sibling element should be merged.
-func shouldMergeSibling(sibling *goquery.Selection, threshold float64, minParagraphLength int) bool {
+// shouldMergeSibling determines if a sibling element should be merged and
+// returns the reason and, for scored siblings, the score.
+func shouldMergeSibling(sibling *goquery.Selection, threshold float64, minParagraphLength int, kw *keywords.Set) (bool, string, float64) {
 	tag := dom.GetTagName(sibling)
 
 	// Always consider merging paragraphs
@@ -67,33 +76,39 @@ func shouldMergeSibling(sibling *goquery.Selection, threshold float64, minParagr
 		linkDensity := dom.CalculateLinkDensity(sibling)
 
 		// Merge if has substantial text and low link density
-		if textLen >= minParagraphLength && linkDensity < 0.2 {
-			return true
+		if textLen < minParagraphLength {
+			return false, "paragraph too short", 0
 		}
-		return false
+		if linkDensity >= 0.2 {
+			return false, "paragraph link density too high", 0
+		}
+		return true, "paragraph with substantial text", 0
 	}
 
 	// For other elements, check class/id weight
 	class := dom.GetAttribute(sibling, "class")
 	id := dom.GetAttribute(sibling, "id")
-	weight := keywords.GetWeight(class, id)
+	weight := kw.Weight(class, id)
 
 	// Don't merge negatively weighted elements
 	if weight < 0 {
-		return false
+		return false, "negative class/id weight", 0
 	}
 
 	// Check link density
 	linkDensity := dom.CalculateLinkDensity(sibling)
 	if linkDensity > 0.25 {
-		return false
+		return false, "link density too high", 0
 	}
 
 	// Score the sibling's content
 	score := scoreSibling(sibling, minParagraphLength)
 
 	// Merge if score meets threshold
-	return score >= threshold
+	if score >= threshold {
+		return true, "score above threshold", score
+	}
+	return false, "score below threshold", score
 }
 
 // scoreSibling calculates a score for a sibling element.
@@ -0,0 +1,240 @@
+package confidence
+
+import (
+	"bufio"
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultModel(t *testing.T) {
+	model := Default()
+	if len(model.Weights) != len(FeatureNames) {
+		t.Fatalf("Default model has %d weights, want %d", len(model.Weights), len(FeatureNames))
+	}
+
+	good := Features{
+		Score:          80,
+		ScoreRatio:     5,
+		WordCount:      600,
+		Paragraphs:     10,
+		LinkDensity:    0.05,
+		TitleAgreement: 1,
+		HasAuthor:      true,
+		HasDate:        true,
+		RemovedRatio:   0.5,
+		ContentRatio:   0.7,
+	}
+	bad := Features{
+		Score:        15,
+		ScoreRatio:   1.1,
+		WordCount:    60,
+		Paragraphs:   2,
+		LinkDensity:  0.4,
+		RemovedRatio: 0.2,
+		ContentRatio: 0.1,
+	}
+
+	if p := model.Predict(good); p < 0.8 {
+		t.Errorf("Predict(good) = %.2f, want >= 0.8", p)
+	}
+	if p := model.Predict(bad); p > 0.2 {
+		t.Errorf("Predict(bad) = %.2f, want <= 0.2", p)
+	}
+}
+
+func TestFit(t *testing.T) {
+	var samples []Sample
+	for i := 0; i < 20; i++ {
+		samples = append(samples,
+			Sample{Features: Features{Score: 60 + float64(i), WordCount: 400 + 10*i, LinkDensity: 0.05, ContentRatio: 0.8}, Correct: true},
+			Sample{Features: Features{Score: 10 + float64(i), WordCount: 50 + i, LinkDensity: 0.5, ContentRatio: 0.1}, Correct: false},
+		)
+	}
+
+	model, stats, err := Fit(samples)
+	if err != nil {
+		t.Fatalf("Fit failed: %v", err)
+	}
+	if !stats.Converged || stats.Iterations == 0 || stats.Loss >= 0.1 {
+		t.Errorf("Expected training to converge to a low loss, got %+v", stats)
+	}
+	for _, s := range samples {
+		p := model.Predict(s.Features)
+		if s.Correct && p < 0.5 || !s.Correct && p > 0.5 {
+			t.Errorf("Fitted model predicts %.2f for sample labeled %v", p, s.Correct)
+		}
+	}
+
+	if _, _, err := Fit(samples[:1]); err != ErrNotEnoughSamples {
+		t.Errorf("Expected ErrNotEnoughSamples, got %v", err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "model.json")
+	model := Default()
+	model.Bias = -3
+
+	if err := model.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Bias != -3 || loaded.Weights["linkDensity"] != model.Weights["linkDensity"] {
+		t.Errorf("Loaded model differs: %+v", loaded)
+	}
+
+	if err := os.WriteFile(path, []byte(`{"weights": {"unknown": 1}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("Expected an error for an unknown feature")
+	}
+}
+
+func TestLoadCorpus(t *testing.T) {
+	dir := t.TempDir()
+	manifest := "# QA labels\n" +
+		`{"file": "a.html", "url": "https://example.com/a", "correct": true}` + "\n\n" +
+		`{"file": "b.html", "correct": false}` + "\n"
+	if err := os.WriteFile(filepath.Join(dir, ManifestName), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := LoadCorpus(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 || entries[0].File != filepath.Join(dir, "a.html") || !entries[0].Correct || entries[1].Correct {
+		t.Errorf("Unexpected entries: %+v", entries)
+	}
+}
+
+func TestTitleAgreement(t *testing.T) {
+	headings := []string{"Menu", "Council Approves Transit Plan"}
+
+	if a := TitleAgreement("Council approves transit plan", headings); a != 1 {
+		t.Errorf("TitleAgreement = %v, want 1", a)
+	}
+	if a := TitleAgreement("Council approves plan - City News", headings); a <= 0 || a >= 1 {
+		t.Errorf("TitleAgreement = %v, want partial overlap", a)
+	}
+	if a := TitleAgreement("", headings); a != 0 {
+		t.Errorf("TitleAgreement with no title = %v, want 0", a)
+	}
+}
+
+// loadSamples reads the labeled samples of the built-in corpus.
+func loadSamples(t *testing.T) []Sample {
+	t.Helper()
+	f, err := os.Open(filepath.Join("corpus", "samples.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var samples []Sample
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s Sample
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatal(err)
+		}
+		samples = append(samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return samples
+}
+
+func TestDefaultModel_Fitted(t *testing.T) {
+	samples := loadSamples(t)
+	model, stats, err := Fit(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stats.Converged {
+		t.Errorf("Fit did not converge: %+v", stats)
+	}
+
+	// The built-in model is the one fitted to the corpus
+	want := Default()
+	if math.Abs(model.Bias-want.Bias) > 1e-9 {
+		t.Errorf("Fitted bias %v, built-in %v; run go generate", model.Bias, want.Bias)
+	}
+	for _, name := range FeatureNames {
+		if math.Abs(model.Weights[name]-want.Weights[name]) > 1e-9 {
+			t.Errorf("Fitted %s weight %v, built-in %v; run go generate", name, model.Weights[name], want.Weights[name])
+		}
+	}
+}
+
+func TestDefaultModel_Calibration(t *testing.T) {
+	samples := loadSamples(t)
+
+	// Predict each sample with a model fitted to the other folds
+	const folds = 5
+	predictions := make([]float64, len(samples))
+	for fold := 0; fold < folds; fold++ {
+		var train []Sample
+		for i, s := range samples {
+			if i%folds != fold {
+				train = append(train, s)
+			}
+		}
+		model, _, err := Fit(train)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, s := range samples {
+			if i%folds == fold {
+				predictions[i] = model.Predict(s.Features)
+			}
+		}
+	}
+
+	// The Brier score must beat predicting the base rate for every page
+	var positives float64
+	for _, s := range samples {
+		positives += boolValue(s.Correct)
+	}
+	rate := positives / float64(len(samples))
+
+	var brier, baseline float64
+	for i, s := range samples {
+		y := boolValue(s.Correct)
+		brier += (predictions[i] - y) * (predictions[i] - y)
+		baseline += (rate - y) * (rate - y)
+	}
+	brier /= float64(len(samples))
+	baseline /= float64(len(samples))
+	if brier > 0.06 || brier > baseline/3 {
+		t.Errorf("Cross-validated Brier score %.4f, base rate %.4f", brier, baseline)
+	}
+
+	// Reliability: in bins with enough samples, the mean prediction is
+	// close to the share of correct extractions
+	const bins = 5
+	var count, predicted, observed [bins]float64
+	for i, s := range samples {
+		b := min(int(predictions[i]*bins), bins-1)
+		count[b]++
+		predicted[b] += predictions[i]
+		observed[b] += boolValue(s.Correct)
+	}
+	for b := range count {
+		if count[b] < 10 {
+			continue
+		}
+		mean, share := predicted[b]/count[b], observed[b]/count[b]
+		if math.Abs(mean-share) > 0.1 {
+			t.Errorf("Bin %d: %v samples, mean prediction %.2f, share correct %.2f", b, count[b], mean, share)
+		}
+	}
+}
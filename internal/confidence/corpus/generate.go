@@ -0,0 +1,208 @@
+//go:build ignore
+
+// This program labels the confidence corpus and fits the built-in model.
+// It is run by go generate in internal/confidence:
+//
+//	go run ./corpus/generate.go
+//
+// The pages are the Mozilla Readability test pages shipped with
+// go-readability, each a real page saved with the article text a reviewer
+// expected. A page is labeled correct when the extractor's text matches
+// the expected text with a word F1 of at least minF1. The program writes
+// corpus/labels.jsonl and corpus/samples.jsonl and replaces model.json with
+// the model FitConfidenceModel fits to the labels.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	extractor "github.com/LeadNewswire/article-extractor"
+	"github.com/PuerkitoBio/goquery"
+)
+
+const (
+	// module is the go-readability version whose test pages make up the
+	// corpus
+	module = "github.com/go-shiori/go-readability@v0.0.0-20251205110129-5db1dc9836f0"
+
+	// pageURL is the URL the test pages were saved under
+	pageURL = "http://fakehost/test/page.html"
+
+	// minF1 is the word F1 against the expected text of a correct
+	// extraction
+	minF1 = 0.8
+)
+
+func main() {
+	pages, err := testPages()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// FitConfidenceModel reads the pages and labels from one directory
+	dir, err := os.MkdirTemp("", "confidence-corpus")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var labels bytes.Buffer
+	fmt.Fprintf(&labels, "# Mozilla Readability test pages from %s\n", module)
+	fmt.Fprintf(&labels, "# correct: word F1 of TextContent against expected.html >= %.1f\n", minF1)
+
+	ext := extractor.New()
+	correct := 0
+	for _, name := range sortedKeys(pages) {
+		source, err := os.ReadFile(filepath.Join(pages[name], "source.html"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		expected, err := expectedText(filepath.Join(pages[name], "expected.html"))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		ok := false
+		if article, err := ext.ExtractWithURL(string(source), pageURL); err == nil {
+			ok = f1(words(article.TextContent), words(expected)) >= minF1
+		}
+		if ok {
+			correct++
+		}
+
+		file := name + ".html"
+		if err := os.WriteFile(filepath.Join(dir, file), source, 0o644); err != nil {
+			log.Fatal(err)
+		}
+		line, err := json.Marshal(extractor.ConfidenceCorpusEntry{File: file, URL: pageURL, Correct: ok})
+		if err != nil {
+			log.Fatal(err)
+		}
+		labels.Write(append(line, '\n'))
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "labels.jsonl"), labels.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("corpus", "labels.jsonl"), labels.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	model, fit, err := ext.FitConfidenceModel(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if !fit.Converged {
+		log.Fatalf("training did not converge in %d iterations", fit.Iterations)
+	}
+	if err := model.Save("model.json"); err != nil {
+		log.Fatal(err)
+	}
+
+	var samples bytes.Buffer
+	for _, s := range fit.Samples {
+		s.File = filepath.Base(s.File)
+		line, err := json.Marshal(s)
+		if err != nil {
+			log.Fatal(err)
+		}
+		samples.Write(append(line, '\n'))
+	}
+	if err := os.WriteFile(filepath.Join("corpus", "samples.jsonl"), samples.Bytes(), 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	log.Printf("%d pages, %d correct, %d skipped: %v", len(pages), correct, len(fit.Skipped), fit.Skipped)
+	log.Printf("converged after %d iterations, log loss %.4f", fit.Iterations, fit.Loss)
+}
+
+// testPages downloads the go-readability module and returns its test page
+// directories by name.
+func testPages() (map[string]string, error) {
+	out, err := exec.Command("go", "mod", "download", "-json", module).Output()
+	if err != nil {
+		return nil, fmt.Errorf("downloading %s: %w", module, err)
+	}
+	var info struct{ Dir string }
+	if err := json.Unmarshal(out, &info); err != nil {
+		return nil, err
+	}
+
+	root := filepath.Join(info.Dir, "test-pages")
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+	pages := make(map[string]string)
+	for _, entry := range entries {
+		dir := filepath.Join(root, entry.Name())
+		if _, err := os.Stat(filepath.Join(dir, "expected.html")); err == nil {
+			pages[entry.Name()] = dir
+		}
+	}
+	return pages, nil
+}
+
+// expectedText returns the text of a page's expected article.
+func expectedText(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	doc, err := goquery.NewDocumentFromReader(f)
+	if err != nil {
+		return "", err
+	}
+	return doc.Text(), nil
+}
+
+// words counts the lowercase words of text.
+func words(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		counts[w]++
+	}
+	return counts
+}
+
+// f1 returns the F1 score of the words of an extraction against the
+// expected words.
+func f1(got, want map[string]int) float64 {
+	var gotTotal, wantTotal, shared int
+	for w, n := range got {
+		gotTotal += n
+		shared += min(n, want[w])
+	}
+	for _, n := range want {
+		wantTotal += n
+	}
+	if shared == 0 {
+		return 0
+	}
+	precision := float64(shared) / float64(gotTotal)
+	recall := float64(shared) / float64(wantTotal)
+	return 2 * precision * recall / (precision + recall)
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
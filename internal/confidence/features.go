@@ -0,0 +1,62 @@
+package confidence
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TitleAgreement returns the highest word overlap (Jaccard index) between
+// a title and any of the headings, or 0 if there is no title.
+func TitleAgreement(title string, headings []string) float64 {
+	titleWords := wordSet(title)
+	if len(titleWords) == 0 {
+		return 0
+	}
+
+	best := 0.0
+	for _, heading := range headings {
+		words := wordSet(heading)
+		if len(words) == 0 {
+			continue
+		}
+
+		shared := 0
+		for w := range words {
+			if titleWords[w] {
+				shared++
+			}
+		}
+		union := len(titleWords) + len(words) - shared
+		if overlap := float64(shared) / float64(union); overlap > best {
+			best = overlap
+		}
+	}
+	return best
+}
+
+// ScoreRatio returns the ratio of the top score to the second score,
+// capped for a missing or non-positive second score.
+func ScoreRatio(top, second float64) float64 {
+	if top <= 0 {
+		return 0
+	}
+	if second <= 0 {
+		return maxScoreRatio
+	}
+	ratio := top / second
+	if ratio > maxScoreRatio {
+		return maxScoreRatio
+	}
+	return ratio
+}
+
+// wordSet returns the lowercased words of text.
+func wordSet(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, w := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}) {
+		words[w] = true
+	}
+	return words
+}
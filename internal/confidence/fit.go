@@ -0,0 +1,215 @@
+package confidence
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ManifestName is the name of the label file in a corpus directory.
+const ManifestName = "labels.jsonl"
+
+// Default training parameters.
+const (
+	DefaultMaxIterations = 100000
+	DefaultLearningRate  = 0.5
+	DefaultL2            = 0.001
+
+	// DefaultTolerance is the largest gradient component at convergence
+	DefaultTolerance = 1e-6
+)
+
+// ErrNotEnoughSamples is returned when a corpus does not contain both
+// correct and incorrect extractions.
+var ErrNotEnoughSamples = errors.New("corpus needs both correct and incorrect samples")
+
+// Sample is a labeled extraction.
+type Sample struct {
+	// File is the corpus file the sample was extracted from, if any
+	File string `json:"file,omitempty"`
+
+	// Features describes the extraction
+	Features Features `json:"features"`
+
+	// Correct is true if the extraction was judged correct
+	Correct bool `json:"correct"`
+}
+
+// FitStats describes a training run.
+type FitStats struct {
+	// Iterations is the number of gradient descent steps taken
+	Iterations int
+
+	// Loss is the final mean log loss on the samples, without the
+	// regularization term
+	Loss float64
+
+	// Converged is true if the gradient fell below DefaultTolerance
+	// within DefaultMaxIterations steps
+	Converged bool
+}
+
+// Entry is a page in a labeled corpus.
+type Entry struct {
+	// File is the path of the HTML file, relative to the corpus directory
+	// in the manifest and absolute once loaded
+	File string `json:"file"`
+
+	// URL is the original page URL (optional)
+	URL string `json:"url,omitempty"`
+
+	// Correct is true if the extractor's output for the page is correct
+	Correct bool `json:"correct"`
+}
+
+// LoadCorpus reads the manifest of a labeled corpus directory. The
+// manifest, labels.jsonl, holds one JSON Entry per line; blank lines and
+// lines starting with # are ignored.
+func LoadCorpus(dir string) ([]Entry, error) {
+	path := filepath.Join(dir, ManifestName)
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal([]byte(text), &entry); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		if entry.File == "" {
+			return nil, fmt.Errorf("%s:%d: missing file", path, line)
+		}
+		entry.File = filepath.Join(dir, entry.File)
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return entries, nil
+}
+
+// Fit trains a model on labeled samples by batch gradient descent on the
+// L2-regularized log loss. The features are standardized for training, and
+// the weights are scaled back to the raw features. Training stops when
+// every component of the gradient is below DefaultTolerance, or after
+// DefaultMaxIterations steps. Training is deterministic.
+func Fit(samples []Sample) (*Model, FitStats, error) {
+	var positives, negatives int
+	for _, s := range samples {
+		if s.Correct {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+	if positives == 0 || negatives == 0 {
+		return nil, FitStats{}, ErrNotEnoughSamples
+	}
+
+	n := float64(len(samples))
+	vectors := make([][]float64, len(samples))
+	for i, s := range samples {
+		vectors[i] = s.Features.Vector()
+	}
+
+	// Standardize each feature to zero mean and unit variance; constant
+	// features are only centered
+	means := make([]float64, len(FeatureNames))
+	scales := make([]float64, len(FeatureNames))
+	for j := range means {
+		for _, x := range vectors {
+			means[j] += x[j] / n
+		}
+		var variance float64
+		for _, x := range vectors {
+			variance += (x[j] - means[j]) * (x[j] - means[j]) / n
+		}
+		scales[j] = math.Sqrt(variance)
+		if scales[j] == 0 {
+			scales[j] = 1
+		}
+	}
+	standardized := make([][]float64, len(vectors))
+	for i, x := range vectors {
+		standardized[i] = make([]float64, len(x))
+		for j := range x {
+			standardized[i][j] = (x[j] - means[j]) / scales[j]
+		}
+	}
+
+	weights := make([]float64, len(FeatureNames))
+	var bias float64
+	var stats FitStats
+
+	for stats.Iterations < DefaultMaxIterations {
+		gradients := make([]float64, len(weights))
+		var biasGradient float64
+
+		for i, x := range standardized {
+			diff := sigmoid(weightedSum(bias, weights, x)) - boolValue(samples[i].Correct)
+			biasGradient += diff
+			for j := range gradients {
+				gradients[j] += diff * x[j]
+			}
+		}
+
+		biasGradient /= n
+		largest := math.Abs(biasGradient)
+		for j := range gradients {
+			gradients[j] = gradients[j]/n + DefaultL2*weights[j]
+			largest = math.Max(largest, math.Abs(gradients[j]))
+		}
+		if largest < DefaultTolerance {
+			stats.Converged = true
+			break
+		}
+
+		bias -= DefaultLearningRate * biasGradient
+		for j := range weights {
+			weights[j] -= DefaultLearningRate * gradients[j]
+		}
+		stats.Iterations++
+	}
+
+	for i, x := range standardized {
+		p := sigmoid(weightedSum(bias, weights, x))
+		if samples[i].Correct {
+			stats.Loss -= math.Log(p) / n
+		} else {
+			stats.Loss -= math.Log(1-p) / n
+		}
+	}
+
+	model := &Model{Bias: bias, Weights: make(map[string]float64, len(weights))}
+	for j, name := range FeatureNames {
+		model.Weights[name] = weights[j] / scales[j]
+		model.Bias -= weights[j] * means[j] / scales[j]
+	}
+	return model, stats, nil
+}
+
+// weightedSum returns the bias plus the weighted sum of a feature vector.
+func weightedSum(bias float64, weights, x []float64) float64 {
+	z := bias
+	for j, w := range weights {
+		z += w * x[j]
+	}
+	return z
+}
@@ -0,0 +1,171 @@
+// Package confidence estimates how likely an extraction is to be correct.
+package confidence
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+)
+
+// maxScoreRatio caps the ratio of the top to the second candidate score.
+const maxScoreRatio = 10.0
+
+// FeatureNames lists the model inputs in the order of Features.Vector.
+var FeatureNames = []string{
+	"logScore",
+	"scoreRatio",
+	"logWords",
+	"logParagraphs",
+	"linkDensity",
+	"titleAgreement",
+	"hasAuthor",
+	"hasDate",
+	"removedRatio",
+	"contentRatio",
+}
+
+// Features describes an extraction for the confidence model.
+type Features struct {
+	// Score is the top candidate's content score
+	Score float64 `json:"score"`
+
+	// ScoreRatio is the top candidate score divided by the second best
+	ScoreRatio float64 `json:"scoreRatio"`
+
+	// WordCount is the number of words in the content
+	WordCount int `json:"wordCount"`
+
+	// Paragraphs is the number of paragraphs in the content
+	Paragraphs int `json:"paragraphs"`
+
+	// LinkDensity is the top candidate's link density
+	LinkDensity float64 `json:"linkDensity"`
+
+	// TitleAgreement is the word overlap (0-1) between the metadata title
+	// and the best matching page heading
+	TitleAgreement float64 `json:"titleAgreement"`
+
+	// HasAuthor is true if an author was found
+	HasAuthor bool `json:"hasAuthor"`
+
+	// HasDate is true if a publication date was found
+	HasDate bool `json:"hasDate"`
+
+	// RemovedRatio is the fraction of the page text removed during
+	// preprocessing
+	RemovedRatio float64 `json:"removedRatio"`
+
+	// ContentRatio is the fraction of the remaining page text that is in
+	// the content
+	ContentRatio float64 `json:"contentRatio"`
+}
+
+// Vector returns the model inputs in FeatureNames order.
+func (f Features) Vector() []float64 {
+	return []float64{
+		math.Log1p(math.Max(f.Score, 0)),
+		math.Min(f.ScoreRatio, maxScoreRatio) / maxScoreRatio,
+		math.Log1p(float64(f.WordCount)),
+		math.Log1p(float64(f.Paragraphs)),
+		f.LinkDensity,
+		f.TitleAgreement,
+		boolValue(f.HasAuthor),
+		boolValue(f.HasDate),
+		f.RemovedRatio,
+		f.ContentRatio,
+	}
+}
+
+// Model is a logistic regression over Features.
+type Model struct {
+	// Bias is the intercept
+	Bias float64 `json:"bias"`
+
+	// Weights maps feature names to their weights; missing features
+	// have weight 0
+	Weights map[string]float64 `json:"weights"`
+}
+
+// defaultModelJSON holds the built-in weights, fitted to the labeled
+// corpus in corpus/ (see corpus/README.md).
+//
+//go:generate go run ./corpus/generate.go
+//go:embed model.json
+var defaultModelJSON []byte
+
+// Default returns a copy of the built-in model.
+func Default() *Model {
+	var m Model
+	if err := json.Unmarshal(defaultModelJSON, &m); err != nil {
+		panic("confidence: invalid embedded model: " + err.Error())
+	}
+	return &m
+}
+
+// Predict returns the estimated probability (0-1) that an extraction with
+// the given features is correct.
+func (m *Model) Predict(f Features) float64 {
+	return sigmoid(m.linear(f.Vector()))
+}
+
+// linear returns the weighted sum of a feature vector.
+func (m *Model) linear(x []float64) float64 {
+	z := m.Bias
+	for i, name := range FeatureNames {
+		z += m.Weights[name] * x[i]
+	}
+	return z
+}
+
+// Load reads a model from a JSON file.
+func Load(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("confidence model %s: %w", path, err)
+	}
+	for name := range m.Weights {
+		if !isFeature(name) {
+			return nil, fmt.Errorf("confidence model %s: unknown feature %q", path, name)
+		}
+	}
+	return &m, nil
+}
+
+// Save writes the model to a JSON file.
+func (m *Model) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o644)
+}
+
+// isFeature reports whether name is a model feature.
+func isFeature(name string) bool {
+	for _, n := range FeatureNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sigmoid is the logistic function.
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
+
+// boolValue converts a bool to 0 or 1.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,101 @@
+// Package diff compares sequences of strings, such as paragraphs or words,
+// using their longest common subsequence.
+package diff
+
+// Kind is the kind of a diff operation.
+type Kind int
+
+// Diff operation kinds.
+const (
+	// Equal keeps an element present in both sequences
+	Equal Kind = iota
+
+	// Insert adds an element of the new sequence
+	Insert
+
+	// Delete removes an element of the old sequence
+	Delete
+)
+
+// Op is a single diff operation. A is the index in the old sequence and B
+// the index in the new sequence, or -1 if the operation has none.
+type Op struct {
+	Kind Kind
+	A    int
+	B    int
+}
+
+// Compare returns the operations turning a into b, in order. Deletions
+// come before insertions between two equal elements.
+func Compare(a, b []string) []Op {
+	// lengths[i][j] is the LCS length of a[i:] and b[j:]
+	lengths := make([][]int, len(a)+1)
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]Op, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, Op{Kind: Equal, A: i, B: j})
+			i++
+			j++
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			ops = append(ops, Op{Kind: Delete, A: i, B: -1})
+			i++
+		default:
+			ops = append(ops, Op{Kind: Insert, A: -1, B: j})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, Op{Kind: Delete, A: i, B: -1})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, Op{Kind: Insert, A: -1, B: j})
+	}
+	return ops
+}
+
+// LCSLength returns the length of the longest common subsequence of a and
+// b, using memory linear in the length of b.
+func LCSLength(a, b []string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for i := range a {
+		for j := range b {
+			if a[i] == b[j] {
+				curr[j+1] = prev[j] + 1
+			} else {
+				curr[j+1] = max(prev[j+1], curr[j])
+			}
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// Similarity returns 2*LCS/(len(a)+len(b)), from 0 for sequences with
+// nothing in common to 1 for equal sequences. Two empty sequences are equal.
+func Similarity(a, b []string) float64 {
+	if len(a)+len(b) == 0 {
+		return 1
+	}
+	return 2 * float64(LCSLength(a, b)) / float64(len(a)+len(b))
+}
+
+// Changed returns the number of elements deleted from a or inserted into b.
+func Changed(a, b []string) int {
+	return len(a) + len(b) - 2*LCSLength(a, b)
+}
@@ -0,0 +1,56 @@
+package diff
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	a := []string{"a", "b", "c", "d"}
+	b := []string{"a", "x", "c", "d", "e"}
+
+	got := Compare(a, b)
+	want := []Op{
+		{Kind: Equal, A: 0, B: 0},
+		{Kind: Delete, A: 1, B: -1},
+		{Kind: Insert, A: -1, B: 1},
+		{Kind: Equal, A: 2, B: 2},
+		{Kind: Equal, A: 3, B: 3},
+		{Kind: Insert, A: -1, B: 4},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Compare() = %+v, want %+v", got, want)
+	}
+
+	if got := Compare(nil, []string{"a"}); len(got) != 1 || got[0].Kind != Insert {
+		t.Errorf("Compare(nil, [a]) = %+v", got)
+	}
+	if got := Compare(nil, nil); len(got) != 0 {
+		t.Errorf("Compare(nil, nil) = %+v", got)
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	words := strings.Fields
+
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"", "", 1},
+		{"one two three", "one two three", 1},
+		{"one two three", "four five six", 0},
+		{"one two three four", "one two four", 2 * 3.0 / 7},
+	}
+
+	for _, tt := range tests {
+		if got := Similarity(words(tt.a), words(tt.b)); got != tt.want {
+			t.Errorf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+
+	if got := Changed(words("the quick fox"), words("the slow fox jumps")); got != 3 {
+		t.Errorf("Changed() = %d, want 3", got)
+	}
+}
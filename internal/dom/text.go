@@ -7,6 +7,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
 )
 
 var (
@@ -28,6 +29,32 @@ func GetTextLength(sel *goquery.Selection) int {
 	return utf8.RuneCountInString(GetText(sel))
 }
 
+// GetVisibleTextLength returns the length of normalized text, ignoring the
+// contents of script, style, noscript and template elements.
+func GetVisibleTextLength(sel *goquery.Selection) int {
+	var b strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			b.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			switch n.Data {
+			case "script", "style", "noscript", "template":
+				return
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, n := range sel.Nodes {
+		walk(n)
+	}
+	return utf8.RuneCountInString(NormalizeText(b.String()))
+}
+
 // NormalizeText normalizes whitespace in text.
 func NormalizeText(text string) string {
 	// Replace multiple whitespace with single space
@@ -50,7 +77,9 @@ func NormalizeTextPreserveNewlines(text string) string {
 	return strings.TrimSpace(text)
 }
 
-// CountWords counts the number of words in text.
+// CountWords counts the number of words in text. Chinese and Japanese
+// are not written with spaces, so each Han, hiragana and katakana
+// character counts as a word, and CJK punctuation separates words.
 func CountWords(text string) int {
 	text = NormalizeText(text)
 	if text == "" {
@@ -61,12 +90,19 @@ func CountWords(text string) int {
 	inWord := false
 
 	for _, r := range text {
-		if unicode.IsSpace(r) {
+		switch {
+		case IsCJK(r):
+			if inWord {
+				count++
+				inWord = false
+			}
+			count++
+		case unicode.IsSpace(r), isCJKPunct(r):
 			if inWord {
 				count++
 				inWord = false
 			}
-		} else {
+		default:
 			inWord = true
 		}
 	}
@@ -78,11 +114,44 @@ func CountWords(text string) int {
 	return count
 }
 
-// CountCommas counts the number of commas in text.
+// IsCJK reports whether r is a Han, hiragana or katakana character.
+func IsCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana)
+}
+
+// isCJKPunct reports whether r is CJK or full-width punctuation, such as
+// "，" or "「".
+func isCJKPunct(r rune) bool {
+	return unicode.IsPunct(r) && ((r >= 0x3000 && r <= 0x303f) || (r >= 0xff00 && r <= 0xffef))
+}
+
+// CJKRatio returns the fraction of the letters in text that are CJK
+// characters.
+func CJKRatio(text string) float64 {
+	letters, cjk := 0, 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		if IsCJK(r) {
+			cjk++
+		}
+	}
+	if letters == 0 {
+		return 0
+	}
+	return float64(cjk) / float64(letters)
+}
+
+// CountCommas counts the number of commas in text, including the
+// full-width comma, the ideographic comma and the ideographic full stop,
+// which separate clauses in Chinese and Japanese.
 func CountCommas(text string) int {
 	count := 0
 	for _, r := range text {
-		if r == ',' || r == '，' { // Include Chinese comma
+		switch r {
+		case ',', '，', '、', '。':
 			count++
 		}
 	}
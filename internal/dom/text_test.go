@@ -41,6 +41,12 @@ func TestCountWords(t *testing.T) {
 		{"   ", 0},
 		{"word", 1},
 		{"  multiple   spaces   between  words  ", 4},
+		{"中文文章", 4},
+		{"東京で会議", 5},
+		{"東京、大阪。", 4},
+		{"「Go」言語", 3},
+		{"Apple发布了iPhone", 5},
+		{"한국어 기사입니다", 2},
 	}
 
 	for _, tt := range tests {
@@ -64,6 +70,7 @@ func TestCountCommas(t *testing.T) {
 		{"", 0},
 		{"中文，逗號", 1}, // Chinese comma
 		{"mixed, commas，here", 2},
+		{"東京、大阪。名古屋", 2}, // Ideographic comma and full stop
 	}
 
 	for _, tt := range tests {
@@ -161,6 +168,18 @@ func TestGetText(t *testing.T) {
 	}
 }
 
+func TestGetVisibleTextLength(t *testing.T) {
+	html := `<div>  Hello <script>var x = 1;</script><style>p {}</style>  <span>World</span></div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if length := GetVisibleTextLength(doc.Find("div")); length != 11 {
+		t.Errorf("GetVisibleTextLength = %d, want %d", length, 11)
+	}
+}
+
 func TestIsWhitespaceOnly(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -8,8 +8,6 @@ import (
 	"net/http"
 	"strings"
 	"time"
-
-	"golang.org/x/net/html/charset"
 )
 
 // Client is an HTTP client for fetching web pages.
@@ -36,51 +34,46 @@ func NewClient(timeout time.Duration, userAgent string, maxSize int) *Client {
 	}
 }
 
-// Fetch fetches a URL and returns the HTML content.
-func (c *Client) Fetch(ctx context.Context, url string) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
-	}
+// Response is a fetched page whose body is streamed.
+type Response struct {
+	// Body is the decompressed, size-limited response body
+	Body io.Reader
 
-	// Set headers
-	req.Header.Set("User-Agent", c.userAgent)
-	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
-	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
-	req.Header.Set("Accept-Encoding", "gzip")
-	req.Header.Set("Connection", "keep-alive")
+	// ContentType is the Content-Type header of the response
+	ContentType string
 
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return "", fmt.Errorf("fetching URL: %w", err)
-	}
-	defer resp.Body.Close()
+	// URL is the final URL after redirects
+	URL string
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	closers []io.Closer
+}
 
-	// Handle gzip encoding
-	var reader io.Reader = resp.Body
-	if resp.Header.Get("Content-Encoding") == "gzip" {
-		gzReader, err := gzip.NewReader(resp.Body)
-		if err != nil {
-			return "", fmt.Errorf("creating gzip reader: %w", err)
+// Close closes the response body.
+func (r *Response) Close() error {
+	var err error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		if cerr := r.closers[i].Close(); cerr != nil && err == nil {
+			err = cerr
 		}
-		defer gzReader.Close()
-		reader = gzReader
 	}
+	return err
+}
 
-	// Limit reader to max size
-	limitReader := io.LimitReader(reader, int64(c.maxSize))
+// Fetch fetches a URL and returns the HTML content.
+func (c *Client) Fetch(ctx context.Context, url string) (string, error) {
+	return c.FetchWithHeaders(ctx, url, nil)
+}
 
-	// Detect and convert charset
-	contentType := resp.Header.Get("Content-Type")
-	utf8Reader, err := charset.NewReader(limitReader, contentType)
+// FetchWithHeaders fetches a URL with custom headers.
+func (c *Client) FetchWithHeaders(ctx context.Context, url string, headers map[string]string) (string, error) {
+	resp, err := c.FetchReaderWithHeaders(ctx, url, headers)
 	if err != nil {
-		// Fall back to raw reader if charset detection fails
-		utf8Reader = limitReader
+		return "", err
 	}
+	defer resp.Close()
+
+	// Detect and convert charset
+	utf8Reader := DecodeReader(resp.Body, resp.ContentType)
 
 	// Read all content
 	body, err := io.ReadAll(utf8Reader)
@@ -91,11 +84,18 @@ func (c *Client) Fetch(ctx context.Context, url string) (string, error) {
 	return string(body), nil
 }
 
-// FetchWithHeaders fetches a URL with custom headers.
-func (c *Client) FetchWithHeaders(ctx context.Context, url string, headers map[string]string) (string, error) {
+// FetchReader fetches a URL and returns the response with a streaming body.
+// The body is not charset-decoded; the caller must close the response.
+func (c *Client) FetchReader(ctx context.Context, url string) (*Response, error) {
+	return c.FetchReaderWithHeaders(ctx, url, nil)
+}
+
+// FetchReaderWithHeaders fetches a URL with custom headers and returns the
+// response with a streaming body. The caller must close the response.
+func (c *Client) FetchReaderWithHeaders(ctx context.Context, url string, headers map[string]string) (*Response, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return "", fmt.Errorf("creating request: %w", err)
+		return nil, fmt.Errorf("creating request: %w", err)
 	}
 
 	// Set default headers
@@ -103,6 +103,7 @@ func (c *Client) FetchWithHeaders(ctx context.Context, url string, headers map[s
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Connection", "keep-alive")
 
 	// Override with custom headers
 	for key, value := range headers {
@@ -111,12 +112,18 @@ func (c *Client) FetchWithHeaders(ctx context.Context, url string, headers map[s
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("fetching URL: %w", err)
+		return nil, fmt.Errorf("fetching URL: %w", err)
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		resp.Body.Close()
+		return nil, newStatusError(resp)
+	}
+
+	result := &Response{
+		ContentType: resp.Header.Get("Content-Type"),
+		URL:         resp.Request.URL.String(),
+		closers:     []io.Closer{resp.Body},
 	}
 
 	// Handle gzip encoding
@@ -124,28 +131,17 @@ func (c *Client) FetchWithHeaders(ctx context.Context, url string, headers map[s
 	if resp.Header.Get("Content-Encoding") == "gzip" {
 		gzReader, err := gzip.NewReader(resp.Body)
 		if err != nil {
-			return "", fmt.Errorf("creating gzip reader: %w", err)
+			resp.Body.Close()
+			return nil, fmt.Errorf("creating gzip reader: %w", err)
 		}
-		defer gzReader.Close()
+		result.closers = append(result.closers, gzReader)
 		reader = gzReader
 	}
 
-	// Limit reader to max size
-	limitReader := io.LimitReader(reader, int64(c.maxSize))
-
-	// Detect and convert charset
-	contentType := resp.Header.Get("Content-Type")
-	utf8Reader, err := charset.NewReader(limitReader, contentType)
-	if err != nil {
-		utf8Reader = limitReader
-	}
-
-	body, err := io.ReadAll(utf8Reader)
-	if err != nil {
-		return "", fmt.Errorf("reading body: %w", err)
-	}
+	// Fail rather than truncate when the body exceeds the max size
+	result.Body = NewLimitedReader(reader, c.maxSize)
 
-	return string(body), nil
+	return result, nil
 }
 
 // IsValidURL checks if a URL is valid and fetchable.
@@ -0,0 +1,62 @@
+package fetcher
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// StatusError is returned when a response has a status other than 200 OK.
+type StatusError struct {
+	// StatusCode is the HTTP status code of the response
+	StatusCode int
+
+	// URL is the final URL after redirects
+	URL string
+
+	// RetryAfter is the delay requested by the Retry-After header, or 0
+	RetryAfter time.Duration
+
+	// Header holds the response headers
+	Header http.Header
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// newStatusError creates a StatusError from a response.
+func newStatusError(resp *http.Response) *StatusError {
+	return &StatusError{
+		StatusCode: resp.StatusCode,
+		URL:        resp.Request.URL.String(),
+		RetryAfter: ParseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		Header:     resp.Header.Clone(),
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header given as delay seconds or
+// an HTTP date relative to now. It returns 0 if the value is missing,
+// invalid or in the past.
+func ParseRetryAfter(value string, now time.Time) time.Duration {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := date.Sub(now); delay > 0 {
+			return delay.Round(time.Second)
+		}
+	}
+	return 0
+}
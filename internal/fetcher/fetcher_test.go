@@ -0,0 +1,128 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		value string
+		want  time.Duration
+	}{
+		{"empty", "", 0},
+		{"seconds", "120", 2 * time.Minute},
+		{"zero seconds", "0", 0},
+		{"padded seconds", "  30 ", 30 * time.Second},
+		{"negative seconds", "-5", 0},
+		{"fractional seconds", "1.5", 0},
+		{"http date", "Fri, 01 Mar 2024 12:01:30 GMT", 90 * time.Second},
+		{"rfc 850 date", "Friday, 01-Mar-24 12:00:10 GMT", 10 * time.Second},
+		{"asctime date", "Fri Mar  1 12:05:00 2024", 5 * time.Minute},
+		{"past date", "Fri, 01 Mar 2024 11:59:00 GMT", 0},
+		{"current date", "Fri, 01 Mar 2024 12:00:00 GMT", 0},
+		{"garbage", "soon", 0},
+		{"date without zone", "2024-03-01 12:01:00", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseRetryAfter(tt.value, now); got != tt.want {
+				t.Errorf("ParseRetryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchReader_StatusError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/old":
+			http.Redirect(w, r, "/busy?page=2", http.StatusMovedPermanently)
+		case "/busy":
+			w.Header().Set("Retry-After", "45")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(5*time.Second, "test", 0)
+	tests := []struct {
+		path       string
+		status     int
+		url        string
+		retryAfter time.Duration
+	}{
+		{"/old", http.StatusTooManyRequests, server.URL + "/busy?page=2", 45 * time.Second},
+		{"/busy", http.StatusTooManyRequests, server.URL + "/busy", 45 * time.Second},
+		{"/missing", http.StatusNotFound, server.URL + "/missing", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := client.FetchReader(context.Background(), server.URL+tt.path)
+
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) {
+				t.Fatalf("Expected a *StatusError, got %T: %v", err, err)
+			}
+			if statusErr.StatusCode != tt.status || statusErr.URL != tt.url || statusErr.RetryAfter != tt.retryAfter {
+				t.Errorf("StatusError = %+v, want status %d, URL %s, retry after %v", statusErr, tt.status, tt.url, tt.retryAfter)
+			}
+			if tt.retryAfter > 0 && statusErr.Header.Get("Retry-After") == "" {
+				t.Error("Expected the response headers to be kept")
+			}
+		})
+	}
+}
+
+func TestLimitedReader(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		max     int
+		wantErr error
+	}{
+		{"under the limit", "hello", 10, nil},
+		{"at the limit", "hello", 5, nil},
+		{"over the limit", "hello!", 5, ErrTooLarge},
+		{"no limit", strings.Repeat("x", 100), 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := io.ReadAll(NewLimitedReader(strings.NewReader(tt.input), tt.max))
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && string(got) != tt.input {
+				t.Errorf("read %q, want %q", got, tt.input)
+			}
+		})
+	}
+}
+
+func TestContextReader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := NewContextReader(ctx, strings.NewReader("hello"))
+
+	buf := make([]byte, 2)
+	if n, err := r.Read(buf); n != 2 || err != nil {
+		t.Fatalf("Read = %d, %v before cancel", n, err)
+	}
+
+	cancel()
+	if _, err := r.Read(buf); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled after cancel, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package fetcher
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"golang.org/x/net/html/charset"
+)
+
+// ErrTooLarge is returned by a limited reader when the content exceeds
+// the maximum size.
+var ErrTooLarge = errors.New("content exceeds maximum size")
+
+// LimitedReader reads from R and fails with ErrTooLarge once more than N
+// bytes are available, instead of silently truncating like io.LimitReader.
+type LimitedReader struct {
+	R io.Reader // Underlying reader
+	N int64     // Maximum bytes remaining
+}
+
+// NewLimitedReader returns a reader that fails when r holds more than max
+// bytes. A max of zero or less disables the limit.
+func NewLimitedReader(r io.Reader, max int) io.Reader {
+	if max <= 0 {
+		return r
+	}
+	return &LimitedReader{R: r, N: int64(max)}
+}
+
+// Read implements io.Reader.
+func (l *LimitedReader) Read(p []byte) (int, error) {
+	if l.N <= 0 {
+		// Probe for more data beyond the limit
+		var probe [1]byte
+		for {
+			n, err := l.R.Read(probe[:])
+			if n > 0 {
+				return 0, ErrTooLarge
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if int64(len(p)) > l.N {
+		p = p[:l.N]
+	}
+	n, err := l.R.Read(p)
+	l.N -= int64(n)
+	return n, err
+}
+
+// ErrorReader records the first error other than io.EOF returned by R, so
+// that a failure to read the content can be told apart from a failure to
+// parse it.
+type ErrorReader struct {
+	R   io.Reader // Underlying reader
+	Err error     // First read error, or nil
+}
+
+// Read implements io.Reader.
+func (r *ErrorReader) Read(p []byte) (int, error) {
+	n, err := r.R.Read(p)
+	if err != nil && err != io.EOF && r.Err == nil {
+		r.Err = err
+	}
+	return n, err
+}
+
+// contextReader stops reading once its context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// NewContextReader returns a reader that fails with the context error once
+// ctx is done.
+func NewContextReader(ctx context.Context, r io.Reader) io.Reader {
+	return &contextReader{ctx: ctx, r: r}
+}
+
+// Read implements io.Reader.
+func (c *contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// DecodeReader converts r to UTF-8 based on the Content-Type header, or by
+// sniffing the content when contentType is empty. It falls back to the raw
+// reader if charset detection fails.
+func DecodeReader(r io.Reader, contentType string) io.Reader {
+	utf8Reader, err := charset.NewReader(r, contentType)
+	if err != nil {
+		return r
+	}
+	return utf8Reader
+}
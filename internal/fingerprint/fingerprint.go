@@ -0,0 +1,96 @@
+// Package fingerprint computes exact and locality-sensitive fingerprints
+// of text for duplicate and near-duplicate detection.
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"hash/fnv"
+	"math/bits"
+	"strings"
+	"unicode"
+)
+
+// ShingleSize is the number of consecutive words in a SimHash feature.
+const ShingleSize = 3
+
+// Normalize lowercases text and reduces it to its words, dropping
+// punctuation, symbols and extra whitespace, so that formatting changes do
+// not affect a fingerprint.
+func Normalize(text string) string {
+	return strings.Join(Words(text), " ")
+}
+
+// Words returns the lowercase words of text. Letters and digits form
+// words; everything else separates them.
+func Words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r) && !unicode.Is(unicode.Mn, r)
+	})
+}
+
+// Hash returns the hex-encoded SHA-256 of the normalized text, or "" if
+// the text has no words.
+func Hash(text string) string {
+	normalized := Normalize(text)
+	if normalized == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// SimHash returns the 64-bit SimHash of the text's word shingles. Texts
+// sharing most shingles have hashes differing in few bits. Text with no
+// words hashes to 0.
+func SimHash(text string) uint64 {
+	words := Words(text)
+	if len(words) == 0 {
+		return 0
+	}
+
+	var counts [64]int
+	add := func(shingle []string) {
+		h := fnv.New64a()
+		for i, word := range shingle {
+			if i > 0 {
+				h.Write([]byte{' '})
+			}
+			h.Write([]byte(word))
+		}
+		sum := h.Sum64()
+		for bit := range counts {
+			if sum&(1<<bit) != 0 {
+				counts[bit]++
+			} else {
+				counts[bit]--
+			}
+		}
+	}
+
+	if len(words) < ShingleSize {
+		add(words)
+	}
+	for i := 0; i+ShingleSize <= len(words); i++ {
+		add(words[i : i+ShingleSize])
+	}
+
+	var hash uint64
+	for bit, count := range counts {
+		if count > 0 {
+			hash |= 1 << bit
+		}
+	}
+	return hash
+}
+
+// Distance returns the number of bits in which two SimHashes differ.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// Similarity returns the fraction (0-1) of equal bits in two SimHashes.
+// Unrelated texts score around 0.5.
+func Similarity(a, b uint64) float64 {
+	return 1 - float64(Distance(a, b))/64
+}
@@ -0,0 +1,64 @@
+package fingerprint
+
+import (
+	"strings"
+	"testing"
+)
+
+const story = `WASHINGTON (AP) — The Senate on Tuesday passed a bill that would fund the
+government through the end of the fiscal year, averting a shutdown that had been set to
+begin at midnight. The measure now goes to the House, where leaders said they expected
+a vote later in the week. Lawmakers from both parties praised the compromise, though
+several conservatives objected to the spending levels and vowed to oppose it.`
+
+func TestNormalize(t *testing.T) {
+	got := Normalize("  Hello,   World!\n\n- It's 2024 — café. ")
+	want := "hello world it s 2024 café"
+	if got != want {
+		t.Errorf("Normalize() = %q, want %q", got, want)
+	}
+}
+
+func TestHash(t *testing.T) {
+	if Hash("Hello, world!") != Hash("hello   WORLD") {
+		t.Error("Expected formatting not to change the hash")
+	}
+	if Hash("hello world") == Hash("hello there") {
+		t.Error("Expected different text to change the hash")
+	}
+	if Hash(" ... ") != "" {
+		t.Error("Expected no hash for text without words")
+	}
+}
+
+func TestSimHash(t *testing.T) {
+	edited := strings.Replace(story, "later in the week", "on Thursday", 1)
+	edited = strings.Replace(edited, "WASHINGTON (AP) —", "By The Associated Press", 1)
+	unrelated := `The city council voted to expand the bike lane network downtown, adding
+twelve miles of protected lanes over the next two years. Residents at the meeting were
+divided, with some business owners worried about the loss of parking spaces.`
+
+	original := SimHash(story)
+	if original == 0 {
+		t.Fatal("Expected a non-zero SimHash")
+	}
+	if got := Similarity(original, SimHash(story)); got != 1 {
+		t.Errorf("Similarity with itself = %v, want 1", got)
+	}
+
+	near := Similarity(original, SimHash(edited))
+	far := Similarity(original, SimHash(unrelated))
+	if near < 0.8 || near <= far {
+		t.Errorf("Expected the edited copy (%v) to be near and above the unrelated text (%v)", near, far)
+	}
+	if far > 0.75 {
+		t.Errorf("Expected unrelated text to be dissimilar, got %v", far)
+	}
+
+	if SimHash("") != 0 || SimHash("two words") == 0 {
+		t.Error("Expected short text to be hashed and empty text not to be")
+	}
+	if Distance(0, 0xff) != 8 {
+		t.Errorf("Distance() = %d, want 8", Distance(0, 0xff))
+	}
+}
@@ -0,0 +1,294 @@
+// Package images finds the lead image of an article by scoring the images
+// in and around its content.
+package images
+
+import (
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Candidate is a scored image.
+type Candidate struct {
+	// URL is the image source, absolute when the page URL is known
+	URL string
+
+	// Width and Height are the declared dimensions in pixels, 0 if unknown
+	Width, Height int
+
+	// Alt is the alternative text
+	Alt string
+
+	// Score is the lead image score; only positive scores are used
+	Score int
+}
+
+const (
+	// minDimension is the declared width or height below which an image
+	// is an icon, a spacer or a tracking pixel
+	minDimension = 50
+
+	// maxAspectRatio is the ratio of the long to the short side above
+	// which an image is a banner or a divider
+	maxAspectRatio = 3.0
+)
+
+var (
+	// unlikelyPattern matches the file names, classes and ids of images
+	// that are not content, such as logos and tracking pixels
+	unlikelyPattern = regexp.MustCompile(`(?:^|[^a-z])(?:logo|sprite|avatar|icon|pixel|ads?|advert|banner|spacer|badge|emoji|gravatar)s?(?:[^a-z]|$)`)
+
+	// unlikelyMetaPattern is unlikelyPattern without bare "ad" and "ads":
+	// publishers choose their meta images, whose names are often built
+	// from the headline
+	unlikelyMetaPattern = regexp.MustCompile(`(?:^|[^a-z])(?:logo|sprite|avatar|icon|pixel|advert|banner|spacer|badge|emoji|gravatar)s?(?:[^a-z]|$)`)
+
+	// captionPattern matches the classes of caption elements
+	captionPattern = regexp.MustCompile(`(?i)caption`)
+)
+
+// Lead returns the best lead image in and around content, or nil if no
+// image scores above zero. Images in content's parent but outside content
+// are considered too, since lead images often sit just above the body.
+// Relative sources are resolved against pageURL when it is known.
+func Lead(content *goquery.Selection, pageURL string) *Candidate {
+	if content == nil || content.Length() == 0 {
+		return nil
+	}
+	region := content
+	if parent := content.Parent(); parent.Length() > 0 {
+		region = parent
+	}
+
+	// Walk the region in document order, counting the content paragraphs
+	// above each image
+	var best *Candidate
+	paragraphs := 0
+	region.Find("p, img").Each(func(_ int, sel *goquery.Selection) {
+		if goquery.NodeName(sel) == "p" {
+			if isWithin(sel, content) {
+				paragraphs++
+			}
+			return
+		}
+
+		c := Score(sel, isWithin(sel, content), paragraphs)
+		if c == nil || c.Score <= 0 || (best != nil && c.Score <= best.Score) {
+			return
+		}
+		if c.URL = Resolve(pageURL, c.URL); c.URL != "" {
+			best = c
+		}
+	})
+	return best
+}
+
+// Score scores an img element as a lead image. inContent reports whether
+// it is part of the article content, and paragraphs is the number of
+// content paragraphs above it. It returns nil for images without a source
+// and for images too small to be content.
+func Score(img *goquery.Selection, inContent bool, paragraphs int) *Candidate {
+	src := Source(img)
+	if src == "" {
+		return nil
+	}
+
+	c := &Candidate{
+		URL:    src,
+		Width:  parseDimension(img.AttrOr("width", "")),
+		Height: parseDimension(img.AttrOr("height", "")),
+		Alt:    strings.TrimSpace(img.AttrOr("alt", "")),
+	}
+	if (c.Width > 0 && c.Width < minDimension) || (c.Height > 0 && c.Height < minDimension) {
+		return nil
+	}
+
+	// Size: large images are photos, small ones thumbnails
+	switch size := max(c.Width, c.Height); {
+	case size >= 600:
+		c.Score += 20
+	case size >= 300:
+		c.Score += 10
+	case size > 0 && size < 150:
+		c.Score -= 20
+	}
+
+	// Shape: photos are landscape, banners and dividers are long and thin
+	if c.Width > 0 && c.Height > 0 {
+		ratio := float64(c.Width) / float64(c.Height)
+		switch {
+		case ratio > maxAspectRatio || ratio < 1/maxAspectRatio:
+			c.Score -= 20
+		case ratio >= 1.2 && ratio <= 2:
+			c.Score += 5
+		}
+	}
+
+	// Position: the lead image comes before or with the first paragraphs
+	if inContent {
+		c.Score += 10
+	}
+	c.Score += max(0, 20-5*paragraphs)
+
+	figure := img.Closest("figure")
+	if figure.Length() > 0 {
+		c.Score += 15
+	}
+
+	if Unlikely(src) || unlikelyPattern.MatchString(strings.ToLower(img.AttrOr("class", "")+" "+img.AttrOr("id", ""))) {
+		c.Score -= 50
+	}
+	if strings.HasSuffix(strings.ToLower(fileName(src)), ".gif") {
+		c.Score -= 10
+	}
+
+	if c.Alt != "" {
+		c.Score += 5
+	}
+	if hasCaption(img, figure) {
+		c.Score += 10
+	}
+	return c
+}
+
+// Source returns the source URL of an img element, including lazy-loaded
+// images, or "" if it has none. Inline data URIs are ignored.
+func Source(img *goquery.Selection) string {
+	for _, attr := range []string{"src", "data-src", "data-lazy-src", "data-original"} {
+		src := strings.TrimSpace(img.AttrOr(attr, ""))
+		if src != "" && !strings.HasPrefix(strings.ToLower(src), "data:") {
+			return src
+		}
+	}
+
+	// The first srcset candidate
+	if srcset := strings.TrimSpace(img.AttrOr("srcset", "")); srcset != "" {
+		first, _, _ := strings.Cut(srcset, ",")
+		if fields := strings.Fields(first); len(fields) > 0 && !strings.HasPrefix(strings.ToLower(fields[0]), "data:") {
+			return fields[0]
+		}
+	}
+	return ""
+}
+
+// Unlikely reports whether an image's file name suggests it is not
+// content, such as a logo, avatar, icon, sprite, ad or tracking pixel.
+// Directories are not matched, so "/ads/2024/harbor.jpg" is likely.
+func Unlikely(src string) bool {
+	return unlikelyPattern.MatchString(strings.ToLower(fileName(src)))
+}
+
+// UnlikelyMeta is Unlikely for og:image and twitter:image URLs, which
+// may contain "ad" or "ads" as words.
+func UnlikelyMeta(src string) bool {
+	return unlikelyMetaPattern.MatchString(strings.ToLower(fileName(src)))
+}
+
+// Resolve resolves an image source against the page URL. It returns ""
+// if the source cannot be made an absolute http(s) URL, except that
+// relative sources are returned as they are when the page URL is unknown.
+func Resolve(pageURL, src string) string {
+	ref, err := url.Parse(strings.TrimSpace(src))
+	if err != nil {
+		return ""
+	}
+	if pageURL == "" && !ref.IsAbs() && ref.Host == "" {
+		return src
+	}
+	return Absolute(pageURL, src)
+}
+
+// Absolute resolves an image source against the page URL, returning ""
+// unless the result is an absolute http(s) URL.
+func Absolute(pageURL, src string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	ref, err := url.Parse(strings.TrimSpace(src))
+	if err != nil {
+		return ""
+	}
+
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme == "" && resolved.Host != "" {
+		// Protocol-relative source without a page URL
+		resolved.Scheme = "https"
+	}
+	if (resolved.Scheme != "http" && resolved.Scheme != "https") || resolved.Host == "" {
+		return ""
+	}
+	return resolved.String()
+}
+
+// Key returns the form of an image URL used to tell whether two sources
+// are the same image: resolved against the page URL as by Resolve, without
+// the scheme and fragment, and with the host lowercased. It returns "" for
+// sources Resolve rejects.
+func Key(pageURL, src string) string {
+	resolved := Resolve(pageURL, src)
+	u, err := url.Parse(resolved)
+	if err != nil || resolved == "" {
+		return ""
+	}
+	u.Scheme = ""
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	return u.String()
+}
+
+// fileName returns the last path segment of a URL.
+func fileName(src string) string {
+	if u, err := url.Parse(src); err == nil {
+		src = u.Path
+	}
+	return path.Base(src)
+}
+
+// hasCaption reports whether an image has a caption: a figcaption in its
+// figure, or a caption element next to it.
+func hasCaption(img, figure *goquery.Selection) bool {
+	if figure.Length() > 0 && strings.TrimSpace(figure.Find("figcaption").Text()) != "" {
+		return true
+	}
+	found := false
+	img.Parent().Children().EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		if captionPattern.MatchString(sel.AttrOr("class", "")) && strings.TrimSpace(sel.Text()) != "" {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// isWithin reports whether sel is inside container.
+func isWithin(sel, container *goquery.Selection) bool {
+	for _, node := range container.Nodes {
+		for n := sel.Get(0); n != nil; n = n.Parent {
+			if n == node {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseDimension parses a width or height attribute such as "640" or
+// "640px", returning 0 for percentages and invalid values.
+func parseDimension(value string) int {
+	value = strings.TrimSpace(value)
+	n := 0
+	for i, c := range value {
+		if c < '0' || c > '9' {
+			if rest := value[i:]; rest != "px" || i == 0 {
+				return 0
+			}
+			break
+		}
+		n = n*10 + int(c-'0')
+	}
+	return n
+}
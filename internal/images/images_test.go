@@ -0,0 +1,200 @@
+package images
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func parse(t *testing.T, html string) *goquery.Document {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestLead(t *testing.T) {
+	doc := parse(t, `<html><body><div id="page">
+<img src="/static/site-logo.png" width="300" height="80">
+<div id="story">
+	<p class="byline"><img src="/authors/jane.jpg" class="author-avatar" width="120" height="120"> By Jane Doe</p>
+	<img src="/ads/leaderboard.jpg" width="728" height="90">
+	<p>First paragraph of the story.</p>
+	<figure><img src="/photos/harbor.jpg" width="1200" height="800" alt="The harbor at dawn"><figcaption>The harbor at dawn.</figcaption></figure>
+	<p>Second paragraph of the story.</p>
+	<img src="/track?id=1" width="1" height="1">
+	<p>Third paragraph of the story.</p>
+	<img src="/photos/later.jpg">
+</div>
+</div></body></html>`)
+
+	lead := Lead(doc.Find("#story"), "https://example.com/news/story")
+	if lead == nil {
+		t.Fatal("Expected a lead image")
+	}
+	if lead.URL != "https://example.com/photos/harbor.jpg" {
+		t.Errorf("Lead = %s, want the figure photo", lead.URL)
+	}
+	if lead.Width != 1200 || lead.Height != 800 || lead.Alt != "The harbor at dawn" {
+		t.Errorf("Unexpected lead image %+v", lead)
+	}
+
+	// Without a page URL, sources stay relative
+	if lead := Lead(doc.Find("#story"), ""); lead == nil || lead.URL != "/photos/harbor.jpg" {
+		t.Errorf("Expected the relative source, got %+v", lead)
+	}
+
+	doc = parse(t, `<html><body><div id="story"><img src="/img/share-icon.svg"><p>Text.</p></div></body></html>`)
+	if lead := Lead(doc.Find("#story"), "https://example.com/"); lead != nil {
+		t.Errorf("Expected no lead image, got %+v", lead)
+	}
+}
+
+func TestScore(t *testing.T) {
+	doc := parse(t, `<html><body>
+<img id="pixel" src="/p.gif" width="1" height="1">
+<img id="banner" src="/a.jpg" width="900" height="100">
+<img id="photo" src="/b.jpg" width="900" height="600">
+<img id="lazy" data-src="/c.jpg" src="data:image/gif;base64,R0lGOD">
+<img id="srcset" srcset="/d-small.jpg 480w, /d-large.jpg 1080w">
+</body></html>`)
+	score := func(id string) *Candidate {
+		return Score(doc.Find("#"+id), true, 0)
+	}
+
+	if c := score("pixel"); c != nil {
+		t.Errorf("Expected tracking pixels to be rejected, got %+v", c)
+	}
+	if banner, photo := score("banner"), score("photo"); banner.Score >= photo.Score {
+		t.Errorf("Expected the banner (%d) to score below the photo (%d)", banner.Score, photo.Score)
+	}
+	if c := score("lazy"); c == nil || c.URL != "/c.jpg" {
+		t.Errorf("Expected the lazy-loaded source, got %+v", c)
+	}
+	if c := score("srcset"); c == nil || c.URL != "/d-small.jpg" {
+		t.Errorf("Expected the first srcset source, got %+v", c)
+	}
+	if later := Score(doc.Find("#photo"), true, 5); later.Score >= score("photo").Score {
+		t.Errorf("Expected images further down to score less")
+	}
+}
+
+func TestUnlikely(t *testing.T) {
+	for src, want := range map[string]bool{
+		"https://example.com/logo.png":             true,
+		"https://example.com/img/site_logo@2x.png": true,
+		"https://example.com/sprites/icons.png":    true,
+		"https://example.com/ad-300x250.jpg":       true,
+		"https://example.com/u/avatar.jpg?s=64":    true,
+		"https://example.com/photos/harbor.jpg":    false,
+		"https://example.com/photos/headlines.jpg": false,
+		"https://example.com/uploads/harbor.jpg":   false,
+		"https://example.com/icons/share.svg":      false,
+		"https://example.com/ads/2024/harbor.jpg":  false,
+	} {
+		if got := Unlikely(src); got != want {
+			t.Errorf("Unlikely(%s) = %v, want %v", src, got, want)
+		}
+	}
+
+	for src, want := range map[string]bool{
+		"https://example.com/logo.png":                true,
+		"https://example.com/site-icon-512.png":       true,
+		"https://example.com/ad-300x250.jpg":          false,
+		"https://example.com/2024/ads-revenue-up.jpg": false,
+	} {
+		if got := UnlikelyMeta(src); got != want {
+			t.Errorf("UnlikelyMeta(%s) = %v, want %v", src, got, want)
+		}
+	}
+}
+
+func TestAbsolute(t *testing.T) {
+	tests := []struct {
+		page, src, want string
+	}{
+		{"https://example.com/a/b", "img.jpg", "https://example.com/a/img.jpg"},
+		{"https://example.com/a/b", "/img.jpg", "https://example.com/img.jpg"},
+		{"", "https://cdn.example.com/img.jpg", "https://cdn.example.com/img.jpg"},
+		{"", "//cdn.example.com/img.jpg", "https://cdn.example.com/img.jpg"},
+		{"", "/img.jpg", ""},
+		{"https://example.com/", "data:image/png;base64,AAAA", ""},
+	}
+	for _, tt := range tests {
+		if got := Absolute(tt.page, tt.src); got != tt.want {
+			t.Errorf("Absolute(%q, %q) = %q, want %q", tt.page, tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestKey(t *testing.T) {
+	tests := []struct {
+		page, src, want string
+	}{
+		{"https://example.com/a/b", "img.jpg", "//example.com/a/img.jpg"},
+		{"https://example.com/a/b", "https://EXAMPLE.com/a/img.jpg", "//example.com/a/img.jpg"},
+		{"https://example.com/a/b", "http://example.com/a/img.jpg#top", "//example.com/a/img.jpg"},
+		{"https://example.com/a/b", "//example.com/a/img.jpg?w=640", "//example.com/a/img.jpg?w=640"},
+		{"", "/a/img.jpg", "/a/img.jpg"},
+		{"https://example.com/", "data:image/png;base64,AAAA", ""},
+	}
+	for _, tt := range tests {
+		if got := Key(tt.page, tt.src); got != tt.want {
+			t.Errorf("Key(%q, %q) = %q, want %q", tt.page, tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestSplitCredit(t *testing.T) {
+	tests := []struct {
+		in, caption, credit string
+	}{
+		{"The harbor at dawn. Photo: Reuters", "The harbor at dawn.", "Photo: Reuters"},
+		{"The harbor at dawn Photo: Reuters", "The harbor at dawn", "Photo: Reuters"},
+		{"Photo: Reuters", "", "Photo: Reuters"},
+		{"Crowds gather on Monday. (AP Photo/Jane Doe)", "Crowds gather on Monday.", "AP Photo/Jane Doe"},
+		{"Crowds gather on Monday (Getty Images)", "Crowds gather on Monday", "Getty Images"},
+		{"The comet over the desert. Image credit: NASA/JPL", "The comet over the desert.", "Image credit: NASA/JPL"},
+		{"The old mill. Photograph by John Smith", "The old mill.", "Photograph by John Smith"},
+		{"The old mill | Courtesy of the museum", "The old mill", "Courtesy of the museum"},
+		{"The old mill. © 2024 Jane Doe", "The old mill.", "© 2024 Jane Doe"},
+		{"Voters line up in Ohio. JANE DOE/THE NEW YORK TIMES", "Voters line up in Ohio.", "JANE DOE/THE NEW YORK TIMES"},
+		{"Voters line up in Ohio. Jane Doe/Getty Images", "Voters line up in Ohio.", "Jane Doe/Getty Images"},
+		{"Visitors take a photo by the fountain", "Visitors take a photo by the fountain", ""},
+		{"Flights between Paris and London/Berlin", "Flights between Paris and London/Berlin", ""},
+	}
+	for _, tt := range tests {
+		caption, credit := SplitCredit(tt.in)
+		if caption != tt.caption || credit != tt.credit {
+			t.Errorf("SplitCredit(%q) = %q, %q, want %q, %q", tt.in, caption, credit, tt.caption, tt.credit)
+		}
+	}
+}
+
+func TestCollect(t *testing.T) {
+	doc := parse(t, `<html><body><div id="story">
+<figure><img src="https://example.com/a.jpg" width="1200" height="800" alt="Harbor"><figcaption>The harbor at dawn. <small>Photo: Reuters</small></figcaption></figure>
+<p>Text <img src="https://example.com/pixel.gif" width="1" height="1"></p>
+<img src="data:image/png;base64,AAAA">
+<figure><img src="https://example.com/b.jpg"><figcaption>Boats in the bay (AP Photo/Jane Doe)</figcaption></figure>
+<p><img src="https://example.com/c.jpg" alt="Map"></p>
+</div></body></html>`)
+
+	infos := Collect(doc.Find("#story"))
+	want := []Info{
+		{URL: "https://example.com/a.jpg", Width: 1200, Height: 800, Alt: "Harbor", Caption: "The harbor at dawn.", Credit: "Photo: Reuters"},
+		{URL: "https://example.com/b.jpg", Caption: "Boats in the bay", Credit: "AP Photo/Jane Doe"},
+		{URL: "https://example.com/c.jpg", Alt: "Map"},
+	}
+	if len(infos) != len(want) {
+		t.Fatalf("Collect = %+v, want %+v", infos, want)
+	}
+	for i := range want {
+		if infos[i] != want[i] {
+			t.Errorf("image %d = %+v, want %+v", i, infos[i], want[i])
+		}
+	}
+}
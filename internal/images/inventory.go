@@ -0,0 +1,111 @@
+package images
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Info is an image of the article content.
+type Info struct {
+	// URL is the image source
+	URL string
+
+	// Width and Height are the declared dimensions in pixels, 0 if unknown
+	Width, Height int
+
+	// Alt is the alternative text
+	Alt string
+
+	// Caption is the text of the image's figcaption without the credit
+	Caption string
+
+	// Credit is the credit line, e.g. "Photo: Reuters"
+	Credit string
+}
+
+// creditPatterns find a credit line in a caption; the first group is the
+// credit and the text before the match is the caption.
+var creditPatterns = []*regexp.Regexp{
+	// "(AP Photo/Jane Doe)", "(Getty Images)"
+	regexp.MustCompile(`(?i)\(\s*([^()]*\b(?:photo|photograph|image|images|credit|courtesy|getty|reuters|afp|ap|epa|dpa|shutterstock)\b[^()]*?)\s*\)\s*$`),
+
+	// "Photo: Reuters", "Image credit: NASA"
+	regexp.MustCompile(`(?i)(?:^|[\s.;|—–(])((?:photo(?:graph)?s?|images?|pictures?|illustrations?|image credit|credits?|source|foto|bild|crédit)\s*:\s*\S.*)$`),
+
+	// "Photo by Jane Doe", "Courtesy of the museum"
+	regexp.MustCompile(`(?i)(?:^|[.;|—–]\s*)((?:photo(?:graph)?s?|images?|pictures?|illustrations?)\s+by\s+\S.*|courtesy(?:\s+of)?\s+\S.*)$`),
+
+	// "© Reuters", "Copyright 2024 Jane Doe"
+	regexp.MustCompile(`(?i)(?:^|\s)((?:©|\(c\)|copyright\b)\s*\S.*)$`),
+
+	// "JANE DOE/THE TIMES" and, after a sentence, "Jane Doe/Getty Images"
+	regexp.MustCompile(`(?:^|\s)(\p{Lu}[\p{Lu}.'’&-]*(?: \p{Lu}[\p{Lu}.'’&-]*)*/\p{Lu}[\p{Lu}.'’& -]*[\p{Lu}.])$`),
+	regexp.MustCompile(`(?:^|[.!?)]\s+)(\p{Lu}[\p{L}.'’-]*(?: \p{Lu}[\p{L}.'’-]*)*/\p{Lu}[\p{L}.'’&-]*(?: \p{Lu}[\p{L}.'’&-]*)*)$`),
+}
+
+// Collect lists the images of the content in document order, with their
+// figcaption split into caption and credit. Images without a usable source
+// or too small to be content (icons and tracking pixels) are skipped.
+func Collect(content *goquery.Selection) []Info {
+	var infos []Info
+	content.Find("img").Each(func(_ int, img *goquery.Selection) {
+		src := strings.TrimSpace(img.AttrOr("src", ""))
+		if src == "" || strings.HasPrefix(strings.ToLower(src), "data:") {
+			return
+		}
+
+		info := Info{
+			URL:    src,
+			Width:  parseDimension(img.AttrOr("width", "")),
+			Height: parseDimension(img.AttrOr("height", "")),
+			Alt:    strings.TrimSpace(img.AttrOr("alt", "")),
+		}
+		if (info.Width > 0 && info.Width < minDimension) || (info.Height > 0 && info.Height < minDimension) {
+			return
+		}
+
+		if caption := img.Closest("figure").Find("figcaption").First(); caption.Length() > 0 {
+			info.Caption, info.Credit = splitCaption(caption)
+		}
+		infos = append(infos, info)
+	})
+	return infos
+}
+
+// splitCaption returns the caption and credit of a figcaption. A cite or
+// small element holds the credit; otherwise the credit is found in the
+// text by SplitCredit.
+func splitCaption(figcaption *goquery.Selection) (string, string) {
+	credit := figcaption.Find("cite, small").Last()
+	if text := dom.NormalizeText(credit.Text()); text != "" {
+		clone := figcaption.Clone()
+		clone.Find("cite, small").Last().Remove()
+		return trimCaption(dom.NormalizeText(clone.Text())), text
+	}
+	return SplitCredit(dom.NormalizeText(figcaption.Text()))
+}
+
+// SplitCredit splits a caption into the caption text and its credit line,
+// such as "Photo: Reuters", "(AP Photo/Jane Doe)", "© Reuters" or
+// "JANE DOE/THE TIMES". The credit is "" if none is found.
+func SplitCredit(caption string) (string, string) {
+	caption = strings.TrimSpace(caption)
+	for _, pattern := range creditPatterns {
+		loc := pattern.FindStringSubmatchIndex(caption)
+		if loc == nil {
+			continue
+		}
+		credit := strings.TrimSpace(caption[loc[2]:loc[3]])
+		return trimCaption(caption[:loc[2]]), credit
+	}
+	return caption, ""
+}
+
+// trimCaption trims the whitespace and separators left between a caption
+// and its credit.
+func trimCaption(caption string) string {
+	return strings.TrimRight(strings.TrimSpace(caption), " |/—–-(")
+}
@@ -0,0 +1,389 @@
+// Package keyphrase extracts the key phrases of a text with RAKE (Rapid
+// Automatic Keyword Extraction). Stopwords and punctuation split the text
+// into runs of content words, and each word is scored by deg(w)/freq(w):
+// the summed length of the runs it occurs in over the number of times it
+// occurs, which favors words that occur within longer phrases. RAKE ranks
+// whole runs by the sum of their word scores; here every phrase of up to
+// MaxWords words within a run is a candidate, scored by the sum of its
+// word scores for each time it occurs, so that a short phrase repeated
+// across runs beats a long run that occurs once.
+package keyphrase
+
+import (
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/LeadNewswire/article-extractor/internal/stopwords"
+)
+
+// Phrase is a ranked key phrase.
+type Phrase struct {
+	// Text is the phrase in its most common form in the text
+	Text string
+
+	// Score is the relevance of the phrase, from 0 to 1
+	Score float64
+
+	// Tagged reports whether the page's keyword meta tags list the phrase
+	Tagged bool
+}
+
+// Input is the text and page signals key phrases are extracted from.
+type Input struct {
+	// Text is the article text
+	Text string
+
+	// Language is the ISO 639-1 code of the text's language ("" if
+	// unknown, which uses English stopwords)
+	Language string
+
+	// Title is the article title
+	Title string
+
+	// Emphasis holds the text of headings and bold text
+	Emphasis []string
+
+	// Tags holds keywords from the page's meta tags
+	Tags []string
+}
+
+const (
+	// MaxWords is the number of words in the longest candidate phrase
+	MaxWords = 3
+
+	// TitleWeight multiplies the score of words in the title
+	TitleWeight = 2.0
+
+	// EmphasisWeight multiplies the score of words in headings and bold
+	// text
+	EmphasisWeight = 1.5
+
+	// TagScore is the score of a tag that does not occur in the text,
+	// relative to the best text phrase; tags that do occur add it to their
+	// text score
+	TagScore = 1.0
+)
+
+// footnoteLinePattern matches link footnote lines such as "[3] https://..."
+var footnoteLinePattern = regexp.MustCompile(`(?m)^\[\d+\]\s+\S+$`)
+
+// Extract returns up to n key phrases of the input, best first. Text in
+// languages without a stopword list yields no phrases, but tags are still
+// returned.
+func Extract(in Input, n int) []Phrase {
+	if n <= 0 {
+		return nil
+	}
+
+	lang := in.Language
+	if lang == "" {
+		lang = "en"
+	}
+	stop := stopwords.For(lang)
+
+	var phrases []*phrase
+	byKey := make(map[string]*phrase)
+	if stop != nil {
+		phrases = rank(footnoteLinePattern.ReplaceAllString(in.Text, ""), stop, weights(in, stop))
+		for _, p := range phrases {
+			byKey[p.key] = p
+		}
+	}
+
+	// Tags are chosen by editors, so they rank with the best text phrases
+	for _, tag := range in.Tags {
+		tagWords := words(tag)
+		key := strings.Join(tagWords, " ")
+		if key == "" {
+			continue
+		}
+		if p := byKey[key]; p != nil {
+			if !p.tagged {
+				p.score += TagScore
+				p.tagged = true
+			}
+			continue
+		}
+		p := &phrase{key: key, words: tagWords, text: strings.TrimSpace(tag), score: TagScore, tagged: true}
+		byKey[key] = p
+		phrases = append(phrases, p)
+	}
+	if len(phrases) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(phrases, func(i, j int) bool {
+		if phrases[i].score != phrases[j].score {
+			return phrases[i].score > phrases[j].score
+		}
+		return phrases[i].key < phrases[j].key
+	})
+
+	// Skip phrases that are part of a better phrase or extend one
+	best := phrases[0].score
+	var result []Phrase
+	var chosen []*phrase
+	for _, p := range phrases {
+		if len(result) == n {
+			break
+		}
+		if !p.tagged && redundant(p, chosen) {
+			continue
+		}
+		chosen = append(chosen, p)
+		result = append(result, Phrase{Text: p.text, Score: p.score / best, Tagged: p.tagged})
+	}
+	return result
+}
+
+// phrase is a candidate phrase.
+type phrase struct {
+	key    string
+	words  []string
+	text   string
+	forms  map[string]int
+	count  int
+	score  float64
+	tagged bool
+}
+
+// rank scores the phrases of up to MaxWords words in the candidate runs
+// of text, normalized so that the best scores 1.
+func rank(text string, stop map[string]bool, weight map[string]float64) []*phrase {
+	byKey := make(map[string]*phrase)
+	var phrases []*phrase
+
+	// deg is the RAKE word degree: the number of words in the runs a word
+	// occurs in, itself included
+	deg := make(map[string]int)
+	freq := make(map[string]int)
+
+	for _, c := range candidates(text, stop) {
+		for _, w := range c.words {
+			deg[w] += len(c.words)
+			freq[w]++
+		}
+
+		for n := 1; n <= MaxWords; n++ {
+			for i := 0; i+n <= len(c.words); i++ {
+				key := strings.Join(c.words[i:i+n], " ")
+				p := byKey[key]
+				if p == nil {
+					p = &phrase{key: key, words: c.words[i : i+n], forms: make(map[string]int)}
+					byKey[key] = p
+					phrases = append(phrases, p)
+				}
+				form := strings.Join(c.forms[i:i+n], " ")
+				p.count++
+				p.forms[form]++
+				if p.text == "" {
+					p.text = form
+				}
+			}
+		}
+	}
+
+	var best float64
+	for _, p := range phrases {
+		p.score = float64(p.count) * wordScore(p.words, deg, freq, weight)
+		p.text = commonForm(p)
+		best = math.Max(best, p.score)
+	}
+	for _, p := range phrases {
+		p.score /= best
+	}
+	return phrases
+}
+
+// wordScore returns the sum of the weighted RAKE scores, deg(w)/freq(w),
+// of a phrase's words.
+func wordScore(words []string, deg, freq map[string]int, weight map[string]float64) float64 {
+	var sum float64
+	for _, w := range words {
+		wt := weight[w]
+		if wt == 0 {
+			wt = 1
+		}
+		sum += float64(deg[w]) / float64(freq[w]) * wt
+	}
+	return sum
+}
+
+// commonForm returns the most frequent form of a phrase. Ties go to the
+// form with fewer capitals, so that a capitalized sentence start loses,
+// then to the first in sort order.
+func commonForm(p *phrase) string {
+	best := p.text
+	for form, count := range p.forms {
+		switch {
+		case count > p.forms[best],
+			count == p.forms[best] && capitals(form) < capitals(best),
+			count == p.forms[best] && capitals(form) == capitals(best) && form < best:
+			best = form
+		}
+	}
+	return best
+}
+
+// capitals counts the uppercase letters of s.
+func capitals(s string) int {
+	n := 0
+	for _, r := range s {
+		if unicode.IsUpper(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// redundant reports whether the words of a phrase are all words of one
+// of the chosen phrases, or include all the words of one.
+func redundant(p *phrase, chosen []*phrase) bool {
+	for _, c := range chosen {
+		if subset(p.words, c.words) || subset(c.words, p.words) {
+			return true
+		}
+	}
+	return false
+}
+
+// subset reports whether the words of a are all words of b.
+func subset(a, b []string) bool {
+	for _, w := range a {
+		if !slices.Contains(b, w) {
+			return false
+		}
+	}
+	return true
+}
+
+// weights returns the score multipliers of the words of the title and of
+// the emphasized text.
+func weights(in Input, stop map[string]bool) map[string]float64 {
+	weight := make(map[string]float64)
+	for _, text := range in.Emphasis {
+		for _, c := range candidates(text, stop) {
+			for _, w := range c.words {
+				weight[w] = EmphasisWeight
+			}
+		}
+	}
+	for _, c := range candidates(in.Title, stop) {
+		for _, w := range c.words {
+			weight[w] = TitleWeight
+		}
+	}
+	return weight
+}
+
+// candidate is a run of words between phrase breaks.
+type candidate struct {
+	words []string
+	forms []string
+}
+
+// candidates splits text into runs of words at punctuation, line breaks,
+// stopwords, words without letters and possessives, as RAKE does.
+func candidates(text string, stop map[string]bool) []candidate {
+	var result []candidate
+	var words, forms []string
+	flush := func() {
+		if len(words) > 0 {
+			result = append(result, candidate{words: words, forms: forms})
+		}
+		words, forms = nil, nil
+	}
+
+	for _, t := range tokenize(text) {
+		if t.brk {
+			flush()
+			continue
+		}
+
+		word := strings.ToLower(t.text)
+		form := t.text
+
+		// Possessives end a phrase: "the council's budget"
+		possessive := strings.HasSuffix(word, "'s")
+		if possessive {
+			word, form = word[:len(word)-2], form[:len(form)-2]
+		} else if i := strings.IndexByte(form, '\''); i > 0 && stop[strings.ToLower(form[:i])] {
+			// Elisions start one: "l'économie"
+			flush()
+			word, form = strings.ToLower(form[i+1:]), form[i+1:]
+		}
+
+		if stop[word] || !hasLetter(word) || utf8.RuneCountInString(word) < 2 {
+			flush()
+			continue
+		}
+		words = append(words, word)
+		forms = append(forms, form)
+		if possessive {
+			flush()
+		}
+	}
+	flush()
+	return result
+}
+
+// token is a word, or a phrase break.
+type token struct {
+	text string
+	brk  bool
+}
+
+// tokenize splits text into words and phrase breaks. Words are letters,
+// digits and marks, with inner hyphens and apostrophes; spaces separate
+// words, and line breaks and other characters break phrases.
+func tokenize(text string) []token {
+	runes := []rune(strings.ReplaceAll(text, "’", "'"))
+	var tokens []token
+	start := -1
+	isWord := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r)
+	}
+
+	for i, r := range runes {
+		inner := (r == '-' || r == '\'') && start >= 0 && i+1 < len(runes) && isWord(runes[i+1])
+		if isWord(r) || inner {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, token{text: string(runes[start:i])})
+			start = -1
+		}
+		if !unicode.IsSpace(r) || r == '\n' || r == '\r' {
+			tokens = append(tokens, token{brk: true})
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, token{text: string(runes[start:])})
+	}
+	return tokens
+}
+
+// hasLetter reports whether s contains a letter.
+func hasLetter(s string) bool {
+	return strings.IndexFunc(s, unicode.IsLetter) >= 0
+}
+
+// words returns the lowercase words of a tag, for matching tags to
+// candidate phrases.
+func words(tag string) []string {
+	var result []string
+	for _, t := range tokenize(tag) {
+		if !t.brk {
+			result = append(result, strings.ToLower(t.text))
+		}
+	}
+	return result
+}
@@ -0,0 +1,145 @@
+package keyphrase
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/LeadNewswire/article-extractor/internal/stopwords"
+)
+
+const story = `The city council approved a new transit budget on Tuesday.
+
+The transit budget adds bus routes and extends light rail service to the airport.
+
+Council members said the light rail extension would open in 2027.
+
+Riders have asked for more bus routes for years, and the council's transit budget answers them.`
+
+func phraseTexts(phrases []Phrase) []string {
+	texts := make([]string, len(phrases))
+	for i, p := range phrases {
+		texts[i] = p.Text
+	}
+	return texts
+}
+
+func TestExtract(t *testing.T) {
+	phrases := Extract(Input{Text: story, Title: "Council approves transit budget"}, 5)
+	if len(phrases) != 5 {
+		t.Fatalf("Expected 5 phrases, got %v", phrases)
+	}
+	if phrases[0].Text != "transit budget" || phrases[0].Score != 1 {
+		t.Errorf("Expected %q to rank first with score 1, got %+v", "transit budget", phrases[0])
+	}
+	for i := 1; i < len(phrases); i++ {
+		if phrases[i].Score > phrases[i-1].Score || phrases[i].Score <= 0 {
+			t.Errorf("Scores are not ranked: %+v", phrases)
+		}
+	}
+
+	texts := strings.Join(phraseTexts(phrases), ",")
+	for _, want := range []string{"light rail", "bus routes"} {
+		if !strings.Contains(texts, want) {
+			t.Errorf("Expected %q in %s", want, texts)
+		}
+	}
+	// Numbers and words covered by better phrases are not phrases
+	for _, p := range phrases {
+		if p.Text == "transit" || p.Text == "budget" || p.Text == "2027" {
+			t.Errorf("Unexpected phrase %q", p.Text)
+		}
+	}
+
+	if got := Extract(Input{Text: story}, 0); got != nil {
+		t.Errorf("Expected no phrases for n = 0, got %v", got)
+	}
+}
+
+func TestRank(t *testing.T) {
+	// Runs: "solar panels", "solar power", "power". deg/freq is 4/2 for
+	// solar, 2/1 for panels and 3/2 for power
+	phrases := rank("Solar panels. Solar power. Power.", stopwords.For("en"), nil)
+
+	want := map[string]float64{
+		"solar panels": 1,     // 2 + 2
+		"solar":        1,     // 2 occurrences of 2
+		"solar power":  0.875, // 2 + 1.5
+		"power":        0.75,  // 2 occurrences of 1.5
+		"panels":       0.5,   // 2
+	}
+	if len(phrases) != len(want) {
+		t.Fatalf("Expected %d phrases, got %d", len(want), len(phrases))
+	}
+	for _, p := range phrases {
+		if math.Abs(p.score-want[p.key]) > 1e-9 {
+			t.Errorf("score of %q = %v, want %v", p.key, p.score, want[p.key])
+		}
+	}
+}
+
+func TestExtract_Emphasis(t *testing.T) {
+	text := "Apples grow in orchards. Pears grow in orchards. Apples ripen. Pears ripen."
+	first := func(in Input) string {
+		return Extract(in, 1)[0].Text
+	}
+
+	if got := first(Input{Text: text, Title: "Pears"}); got != "Pears" {
+		t.Errorf("Expected the title word to rank first, got %q", got)
+	}
+	if got := first(Input{Text: text, Emphasis: []string{"Apples"}}); got != "Apples" {
+		t.Errorf("Expected the emphasized word to rank first, got %q", got)
+	}
+}
+
+func TestExtract_Tags(t *testing.T) {
+	phrases := Extract(Input{Text: story, Tags: []string{"Light Rail", "Public Transport", "light rail"}}, 10)
+
+	tagged := map[string]float64{}
+	for _, p := range phrases {
+		if p.Tagged {
+			tagged[p.Text] = p.Score
+		}
+	}
+	if len(tagged) != 2 {
+		t.Fatalf("Expected 2 tagged phrases, got %+v", phrases)
+	}
+	if tagged["light rail"] != 1 {
+		t.Errorf("Expected the tag found in the text to rank first, got %+v", phrases)
+	}
+	if _, ok := tagged["Public Transport"]; !ok {
+		t.Errorf("Expected the tag missing from the text to be kept, got %+v", phrases)
+	}
+}
+
+func TestExtract_Language(t *testing.T) {
+	text := "Der Stadtrat hat den neuen Haushalt beschlossen. Der neue Haushalt bringt mehr Busse und eine neue Stadtbahn."
+	texts := phraseTexts(Extract(Input{Text: text, Language: "de"}, 3))
+	if texts[0] != "Haushalt" {
+		t.Errorf("Expected German stopwords to split phrases, got %v", texts)
+	}
+
+	if got := Extract(Input{Text: "市议会周二批准了新的预算。", Language: "zh", Tags: []string{"预算"}}, 3); len(got) != 1 || got[0].Text != "预算" {
+		t.Errorf("Expected only the tag without a stopword list, got %v", got)
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	stop := stopwords.For("en")
+	var got []string
+	for _, c := range candidates("The company's chief executive, Jane Doe, said on well-known TV: \"Sales grew 5%.\"", stop) {
+		got = append(got, strings.Join(c.forms, " "))
+	}
+	want := "company,chief executive,Jane Doe,well-known TV,Sales grew"
+	if strings.Join(got, ",") != want {
+		t.Errorf("candidates = %q, want %s", got, want)
+	}
+
+	got = got[:0]
+	for _, c := range candidates("Le gouvernement réforme l'économie nationale", stopwords.For("fr")) {
+		got = append(got, strings.Join(c.forms, " "))
+	}
+	if strings.Join(got, ",") != "gouvernement réforme,économie nationale" {
+		t.Errorf("Expected elisions to split phrases, got %q", got)
+	}
+}
@@ -1,7 +1,5 @@
 package keywords
 
-import "regexp"
-
 // Blacklist keywords that indicate non-content elements.
 // These are patterns that suggest an element is unlikely to be the main content.
 var blacklistKeywords = []string{
@@ -61,40 +59,3 @@ var blacklistKeywords = []string{
 	"ai-assistant",   // AI assistant widgets
 	"ai-answer",      // AI answer engines
 }
-
-var blacklistPattern *regexp.Regexp
-
-func init() {
-	// Build the combined pattern
-	pattern := buildPattern(blacklistKeywords)
-	blacklistPattern = regexp.MustCompile(pattern)
-}
-
-// IsBlacklisted checks if a string matches any blacklist keyword.
-func IsBlacklisted(s string) bool {
-	if s == "" {
-		return false
-	}
-	return blacklistPattern.MatchString(s)
-}
-
-// GetBlacklistPattern returns the compiled blacklist pattern.
-func GetBlacklistPattern() *regexp.Regexp {
-	return blacklistPattern
-}
-
-// buildPattern builds a regex pattern from keywords.
-func buildPattern(keywords []string) string {
-	if len(keywords) == 0 {
-		return "(?!)" // Never matches
-	}
-	pattern := "(?i)("
-	for i, kw := range keywords {
-		if i > 0 {
-			pattern += "|"
-		}
-		pattern += regexp.QuoteMeta(kw)
-	}
-	pattern += ")"
-	return pattern
-}
@@ -0,0 +1,159 @@
+// Package keywords matches class and id values against keyword lists that
+// indicate likely and unlikely content.
+package keywords
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Default weights of a class or id match.
+const (
+	// DefaultWhitelistWeight is added for a class or id matching the whitelist
+	DefaultWhitelistWeight = 25
+
+	// DefaultBlacklistWeight is subtracted for a class or id matching the
+	// blacklist
+	DefaultBlacklistWeight = 25
+)
+
+// Set is a blacklist and a whitelist of keywords with their weights. A Set
+// is immutable and safe for concurrent use. A nil *Set uses the defaults.
+type Set struct {
+	blacklist        []string
+	whitelist        []string
+	blacklistPattern *regexp.Regexp
+	whitelistPattern *regexp.Regexp
+	whitelistWeight  int
+	blacklistWeight  int
+}
+
+// defaultSet holds the built-in keywords and weights.
+var defaultSet = NewSet(blacklistKeywords, whitelistKeywords, DefaultWhitelistWeight, DefaultBlacklistWeight)
+
+// NewSet creates a Set from keyword lists and weights.
+func NewSet(blacklist, whitelist []string, whitelistWeight, blacklistWeight int) *Set {
+	return &Set{
+		blacklist:        append([]string(nil), blacklist...),
+		whitelist:        append([]string(nil), whitelist...),
+		blacklistPattern: compile(blacklist),
+		whitelistPattern: compile(whitelist),
+		whitelistWeight:  whitelistWeight,
+		blacklistWeight:  blacklistWeight,
+	}
+}
+
+// Default returns the Set of built-in keywords and weights.
+func Default() *Set {
+	return defaultSet
+}
+
+// DefaultBlacklist returns a copy of the built-in blacklist keywords.
+func DefaultBlacklist() []string {
+	return append([]string(nil), blacklistKeywords...)
+}
+
+// DefaultWhitelist returns a copy of the built-in whitelist keywords.
+func DefaultWhitelist() []string {
+	return append([]string(nil), whitelistKeywords...)
+}
+
+// orDefault returns s, or the default set if s is nil.
+func (s *Set) orDefault() *Set {
+	if s == nil {
+		return defaultSet
+	}
+	return s
+}
+
+// Blacklist returns a copy of the blacklist keywords.
+func (s *Set) Blacklist() []string {
+	return append([]string(nil), s.orDefault().blacklist...)
+}
+
+// Whitelist returns a copy of the whitelist keywords.
+func (s *Set) Whitelist() []string {
+	return append([]string(nil), s.orDefault().whitelist...)
+}
+
+// IsBlacklisted checks if a string matches any blacklist keyword.
+func (s *Set) IsBlacklisted(str string) bool {
+	return s.BlacklistMatch(str) != ""
+}
+
+// IsWhitelisted checks if a string matches any whitelist keyword.
+func (s *Set) IsWhitelisted(str string) bool {
+	pattern := s.orDefault().whitelistPattern
+	return str != "" && pattern != nil && pattern.MatchString(str)
+}
+
+// BlacklistMatch returns the first blacklist keyword found in a string, or
+// "" if there is none.
+func (s *Set) BlacklistMatch(str string) string {
+	pattern := s.orDefault().blacklistPattern
+	if str == "" || pattern == nil {
+		return ""
+	}
+	return pattern.FindString(str)
+}
+
+// Weight returns the weight for a given class/id combination.
+// Positive weight indicates likely content, negative indicates likely non-content.
+func (s *Set) Weight(class, id string) int {
+	s = s.orDefault()
+	weight := 0
+
+	for _, value := range []string{class, id} {
+		if value == "" {
+			continue
+		}
+		if s.IsWhitelisted(value) {
+			weight += s.whitelistWeight
+		}
+		if s.IsBlacklisted(value) {
+			weight -= s.blacklistWeight
+		}
+	}
+
+	return weight
+}
+
+// IsBlacklisted checks if a string matches any default blacklist keyword.
+func IsBlacklisted(s string) bool {
+	return defaultSet.IsBlacklisted(s)
+}
+
+// IsWhitelisted checks if a string matches any default whitelist keyword.
+func IsWhitelisted(s string) bool {
+	return defaultSet.IsWhitelisted(s)
+}
+
+// GetWeight returns the default weight for a given class/id combination.
+func GetWeight(class, id string) int {
+	return defaultSet.Weight(class, id)
+}
+
+// GetBlacklistPattern returns the compiled default blacklist pattern.
+func GetBlacklistPattern() *regexp.Regexp {
+	return defaultSet.blacklistPattern
+}
+
+// GetWhitelistPattern returns the compiled default whitelist pattern.
+func GetWhitelistPattern() *regexp.Regexp {
+	return defaultSet.whitelistPattern
+}
+
+// compile builds a case-insensitive pattern matching any of the keywords.
+// It returns nil, which matches nothing, if there are no keywords.
+func compile(keywords []string) *regexp.Regexp {
+	var quoted []string
+	for _, kw := range keywords {
+		if kw != "" {
+			quoted = append(quoted, regexp.QuoteMeta(kw))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	return regexp.MustCompile("(?i)(" + strings.Join(quoted, "|") + ")")
+}
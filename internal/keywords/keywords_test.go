@@ -142,3 +142,50 @@ func TestGetWhitelistPattern(t *testing.T) {
 		t.Error("Pattern should not match 'sidebar'")
 	}
 }
+
+func TestSet(t *testing.T) {
+	set := NewSet([]string{"legal"}, []string{"story"}, 10, 40)
+
+	if !set.IsBlacklisted("legal-notice") || set.IsBlacklisted("sidebar") {
+		t.Error("Set should use its own blacklist")
+	}
+	if !set.IsWhitelisted("story-body") || set.IsWhitelisted("article") {
+		t.Error("Set should use its own whitelist")
+	}
+	if got := set.BlacklistMatch("LEGAL-notice"); got != "LEGAL" {
+		t.Errorf("BlacklistMatch = %q, want %q", got, "LEGAL")
+	}
+	if got := set.Weight("story", "legal"); got != -30 {
+		t.Errorf("Weight = %d, want -30", got)
+	}
+
+	// The package functions keep the defaults
+	if !IsBlacklisted("sidebar") || IsBlacklisted("legal") {
+		t.Error("Default keywords should not be affected by other sets")
+	}
+}
+
+func TestSet_Empty(t *testing.T) {
+	set := NewSet(nil, []string{""}, DefaultWhitelistWeight, DefaultBlacklistWeight)
+
+	if set.IsBlacklisted("sidebar") || set.IsWhitelisted("article") {
+		t.Error("Empty lists should match nothing")
+	}
+	if got := set.Weight("sidebar", "article"); got != 0 {
+		t.Errorf("Weight = %d, want 0", got)
+	}
+}
+
+func TestSet_Nil(t *testing.T) {
+	var set *Set
+
+	if !set.IsBlacklisted("sidebar") || !set.IsWhitelisted("article") {
+		t.Error("A nil set should use the defaults")
+	}
+	if got, want := set.Weight("article", "sidebar"), GetWeight("article", "sidebar"); got != want {
+		t.Errorf("Weight = %d, want %d", got, want)
+	}
+	if len(set.Blacklist()) != len(DefaultBlacklist()) {
+		t.Error("A nil set should list the default blacklist")
+	}
+}
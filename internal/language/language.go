@@ -0,0 +1,474 @@
+// Package language detects the language of a document from its declared
+// language tags and, when those are missing or contradict each other, from
+// its text.
+//
+// Text detection first finds the dominant script. Scripts used by a
+// single supported language, such as Hangul or Greek, decide the language
+// directly. Latin and Cyrillic text is classified with a character trigram
+// model built from the frequency profiles in profiles/, which are derived
+// from news corpora of a million sentences per language. Text in a
+// language without a profile gets a low confidence rather than the near
+// certainty of its closest supported relative.
+package language
+
+import (
+	"embed"
+	"maps"
+	"math"
+	"path"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Result is a detected language.
+type Result struct {
+	// Code is the ISO 639-1 language code, or "" if unknown
+	Code string
+
+	// Confidence is the estimated probability (0-1) that Code is correct
+	Confidence float64
+
+	// Source names the signal the language came from, e.g. "html lang"
+	// or "text"
+	Source string
+}
+
+// Hint is a language declared by the document.
+type Hint struct {
+	// Code is the ISO 639-1 language code
+	Code string
+
+	// Source names where the hint was found, e.g. "og:locale"
+	Source string
+}
+
+// SourceText is the Result source for languages detected from text.
+const SourceText = "text"
+
+const (
+	// minLetters is the number of letters needed to detect a language
+	minLetters = 20
+
+	// maxRunes is the number of runes of text examined
+	maxRunes = 10000
+
+	// maxEvidence is the number of trigrams whose evidence counts fully
+	// toward the confidence, so that long texts are not overconfident
+	maxEvidence = 40
+
+	// fitTolerance is how far, in nats per trigram, the mean trigram
+	// log-probability of a text may fall below the expected value of its
+	// language before the confidence is reduced
+	fitTolerance = 1.0
+
+	// fitSlope is how fast the confidence falls beyond fitTolerance
+	fitSlope = 3.0
+)
+
+// Resolve returns the declared language if all hints agree. Otherwise,
+// including when there are no hints, it detects the language from text,
+// with more confidence when one of the hints agrees. If the text is too
+// short, the first hint is returned with low confidence.
+func Resolve(hints []Hint, text string) Result {
+	var agreed string
+	contradicted := false
+	for _, hint := range hints {
+		switch {
+		case agreed == "":
+			agreed = hint.Code
+		case hint.Code != agreed:
+			contradicted = true
+		}
+	}
+
+	if agreed != "" && !contradicted {
+		// Each agreeing hint leaves a fifth of the remaining doubt
+		return Result{
+			Code:       agreed,
+			Confidence: 1 - math.Pow(0.2, float64(len(hints))),
+			Source:     hints[0].Source,
+		}
+	}
+
+	detected := Detect(text)
+	if detected.Code == "" {
+		if len(hints) > 0 {
+			return Result{Code: hints[0].Code, Confidence: 0.5 / float64(len(hints)), Source: hints[0].Source}
+		}
+		return Result{}
+	}
+
+	for _, hint := range hints {
+		if hint.Code == detected.Code {
+			detected.Confidence = 1 - (1-detected.Confidence)/2
+			break
+		}
+	}
+	return detected
+}
+
+// Detect detects the language of text. It returns an empty Result if the
+// text has too few letters.
+func Detect(text string) Result {
+	counts, total := countScripts(text)
+	if total < minLetters {
+		return Result{}
+	}
+
+	script, count := dominantScript(counts)
+	share := float64(count) / float64(total)
+
+	var code string
+	confidence := share
+	switch script {
+	case scriptHangul:
+		code = "ko"
+	case scriptKana:
+		code = "ja"
+	case scriptHan:
+		// Japanese mixes kana into Han text
+		if kana := counts[scriptKana]; kana*10 >= count {
+			code = "ja"
+			confidence = float64(count+kana) / float64(total)
+		} else {
+			code = "zh"
+		}
+	case scriptGreek:
+		code = "el"
+	case scriptHebrew:
+		code = "he"
+	case scriptThai:
+		code = "th"
+	case scriptDevanagari:
+		code = "hi"
+	case scriptArabic:
+		code = arabicLanguage(text)
+	case scriptLatin, scriptCyrillic:
+		var p, fit float64
+		code, p, fit = models[script].classify(text)
+		confidence *= p * fit
+	}
+
+	if code == "" {
+		return Result{}
+	}
+	return Result{Code: code, Confidence: confidence, Source: SourceText}
+}
+
+// Normalize converts a language tag such as "en-US", "pt_BR" or "EN" to
+// an ISO 639-1 code. It returns "" for tags that are not two-letter codes.
+func Normalize(tag string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if len(tag) != 2 || tag[0] < 'a' || tag[0] > 'z' || tag[1] < 'a' || tag[1] > 'z' {
+		return ""
+	}
+
+	// Deprecated codes
+	switch tag {
+	case "iw":
+		return "he"
+	case "in":
+		return "id"
+	case "ji":
+		return "yi"
+	case "nb":
+		// Norwegian Bokmål is detected as Norwegian
+		return "no"
+	}
+	return tag
+}
+
+// Supported returns the codes of the detectable languages, sorted.
+func Supported() []string {
+	codes := []string{"ar", "el", "fa", "he", "hi", "ja", "ko", "th", "zh"}
+	for _, m := range models {
+		for _, lang := range m.languages {
+			codes = append(codes, lang.code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// script identifies a writing system.
+type script int
+
+const (
+	scriptOther script = iota
+	scriptLatin
+	scriptCyrillic
+	scriptGreek
+	scriptArabic
+	scriptHebrew
+	scriptHan
+	scriptKana
+	scriptHangul
+	scriptThai
+	scriptDevanagari
+)
+
+// scriptTables maps scripts to their Unicode tables.
+var scriptTables = []struct {
+	script script
+	table  *unicode.RangeTable
+}{
+	{scriptLatin, unicode.Latin},
+	{scriptCyrillic, unicode.Cyrillic},
+	{scriptGreek, unicode.Greek},
+	{scriptArabic, unicode.Arabic},
+	{scriptHebrew, unicode.Hebrew},
+	{scriptHan, unicode.Han},
+	{scriptKana, unicode.Hiragana},
+	{scriptKana, unicode.Katakana},
+	{scriptHangul, unicode.Hangul},
+	{scriptThai, unicode.Thai},
+	{scriptDevanagari, unicode.Devanagari},
+}
+
+// countScripts counts the letters of each script in the first maxRunes
+// runes of text.
+func countScripts(text string) (map[script]int, int) {
+	counts := make(map[script]int)
+	total, n := 0, 0
+	for _, r := range text {
+		if n++; n > maxRunes {
+			break
+		}
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		total++
+		counts[scriptOf(r)]++
+	}
+	return counts, total
+}
+
+// scriptOf returns the script of a letter.
+func scriptOf(r rune) script {
+	for _, st := range scriptTables {
+		if unicode.Is(st.table, r) {
+			return st.script
+		}
+	}
+	return scriptOther
+}
+
+// dominantScript returns the known script with the most letters.
+func dominantScript(counts map[script]int) (script, int) {
+	best, bestCount := scriptOther, 0
+	for s, count := range counts {
+		if s != scriptOther && (count > bestCount || (count == bestCount && s < best)) {
+			best, bestCount = s, count
+		}
+	}
+	return best, bestCount
+}
+
+// arabicLanguage tells Persian from Arabic by the letters only Persian
+// uses and the Arabic forms of yeh and kaf.
+func arabicLanguage(text string) string {
+	persian, arabic := 0, 0
+	for _, r := range text {
+		switch r {
+		case 'پ', 'چ', 'ژ', 'گ', 'ی', 'ک':
+			persian++
+		case 'ي', 'ك', 'ة':
+			arabic++
+		}
+	}
+	if persian > arabic {
+		return "fa"
+	}
+	return "ar"
+}
+
+//go:generate go run ./profiles/generate.go
+
+//go:embed profiles/*.txt
+var profileFiles embed.FS
+
+// profileScale is the number of trigrams the profile counts are out of
+const profileScale = 1_000_000
+
+// models holds the trigram models of the scripts shared by several
+// languages.
+var models = loadModels()
+
+// loadModels builds a trigram model per script from the language
+// profiles. Each profile line holds a trigram and its count per million
+// trigrams of the language.
+func loadModels() map[script]*model {
+	entries, err := profileFiles.ReadDir("profiles")
+	if err != nil {
+		panic("language: reading profiles: " + err.Error())
+	}
+
+	models := make(map[script]*model)
+	for _, entry := range entries {
+		data, err := profileFiles.ReadFile(path.Join("profiles", entry.Name()))
+		if err != nil {
+			panic("language: reading profiles: " + err.Error())
+		}
+
+		p := &profile{code: strings.TrimSuffix(entry.Name(), ".txt"), counts: make(map[string]int)}
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			gram, count, ok := strings.Cut(line, " ")
+			n, err := strconv.Atoi(count)
+			if !ok || err != nil {
+				panic("language: invalid profile line in " + entry.Name() + ": " + line)
+			}
+			p.counts[foldDiacritics.Replace(gram)] += n
+			p.total += n
+		}
+
+		counts, _ := countScripts(strings.Join(slices.Collect(maps.Keys(p.counts)), " "))
+		s, _ := dominantScript(counts)
+		if models[s] == nil {
+			models[s] = &model{}
+		}
+		models[s].languages = append(models[s].languages, p)
+	}
+	for _, m := range models {
+		m.finish()
+	}
+	return models
+}
+
+// model is a naive Bayes classifier over character trigrams.
+type model struct {
+	languages  []*profile
+	vocabulary map[string]bool
+}
+
+// profile holds a language's trigram log-probabilities.
+type profile struct {
+	code   string
+	counts map[string]int
+	total  int
+	logp   map[string]float64
+	unseen float64
+
+	// expected is the mean trigram log-probability of text in the
+	// language, the cross-entropy of the profile with itself
+	expected float64
+}
+
+// finish computes the log-probabilities with add-one smoothing over the
+// vocabulary of all the model's languages.
+func (m *model) finish() {
+	sort.Slice(m.languages, func(i, j int) bool { return m.languages[i].code < m.languages[j].code })
+
+	m.vocabulary = make(map[string]bool)
+	for _, p := range m.languages {
+		for g := range p.counts {
+			m.vocabulary[g] = true
+		}
+	}
+
+	v := float64(len(m.vocabulary))
+	for _, p := range m.languages {
+		denom := float64(profileScale) + v
+		p.logp = make(map[string]float64, len(p.counts))
+		for g, c := range p.counts {
+			p.logp[g] = math.Log((float64(c) + 1) / denom)
+			p.expected += float64(c) / profileScale * p.logp[g]
+		}
+		p.unseen = math.Log(1 / denom)
+		p.expected += math.Max(0, 1-float64(p.total)/profileScale) * p.unseen
+	}
+}
+
+// classify returns the most likely language of text, its posterior
+// probability among the model's languages with the evidence capped at
+// maxEvidence trigrams, and how well the language's profile fits the
+// text (see fit).
+func (m *model) classify(text string) (string, float64, float64) {
+	all := trigrams(text)
+	var grams []string
+	for _, g := range all {
+		// Trigrams no language has seen carry no evidence
+		if m.vocabulary[g] {
+			grams = append(grams, g)
+		}
+	}
+	if len(grams) == 0 || len(m.languages) == 0 {
+		return "", 0, 0
+	}
+
+	scores := make([]float64, len(m.languages))
+	for i, p := range m.languages {
+		for _, g := range grams {
+			scores[i] += p.logProb(g)
+		}
+	}
+
+	scale := math.Min(1, maxEvidence/float64(len(grams)))
+	best := 0
+	for i := range scores {
+		scores[i] *= scale
+		if scores[i] > scores[best] {
+			best = i
+		}
+	}
+
+	var sum float64
+	for _, score := range scores {
+		sum += math.Exp(score - scores[best])
+	}
+	p := m.languages[best]
+	return p.code, 1 / sum, p.fit(all)
+}
+
+// logProb returns the log-probability of a trigram.
+func (p *profile) logProb(g string) float64 {
+	if lp, ok := p.logp[g]; ok {
+		return lp
+	}
+	return p.unseen
+}
+
+// fit compares the mean log-probability of the trigrams under the
+// profile with the profile's expected value, returning 1 for text that
+// fits as well as typical text in the language and less the worse it
+// fits. The posterior only compares the supported languages, so text in
+// another language would otherwise be classified with near certainty as
+// its closest supported relative.
+func (p *profile) fit(grams []string) float64 {
+	var sum float64
+	for _, g := range grams {
+		sum += p.logProb(g)
+	}
+	deficit := p.expected - sum/float64(len(grams))
+	return math.Exp(-fitSlope * math.Max(0, deficit-fitTolerance))
+}
+
+// foldDiacritics replaces the Romanian comma-below letters with the
+// cedilla forms that older text, and much of the training data, uses.
+var foldDiacritics = strings.NewReplacer("ș", "ş", "ț", "ţ")
+
+// trigrams returns the character trigrams within the lowercase words of
+// text, in the first maxRunes runes.
+func trigrams(text string) []string {
+	if runes := []rune(text); len(runes) > maxRunes {
+		text = string(runes[:maxRunes])
+	}
+
+	words := strings.FieldsFunc(foldDiacritics.Replace(strings.ToLower(text)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.Is(unicode.Mn, r)
+	})
+
+	var grams []string
+	for _, word := range words {
+		runes := []rune(word)
+		for i := 0; i+3 <= len(runes); i++ {
+			grams = append(grams, string(runes[i:i+3]))
+		}
+	}
+	return grams
+}
@@ -0,0 +1,221 @@
+package language
+
+import (
+	"bufio"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		want string
+		text string
+	}{
+		{"en", "The president met with foreign leaders on Friday to discuss the war and the economy."},
+		{"de", "Die Polizei hat am Freitag einen Mann festgenommen, der in der Nacht mehrere Autos beschädigt haben soll."},
+		{"fr", "La police a arrêté vendredi un homme soupçonné d'avoir endommagé plusieurs voitures pendant la nuit."},
+		{"es", "La policía detuvo el viernes a un hombre sospechoso de haber dañado varios coches durante la noche."},
+		{"it", "La polizia ha arrestato venerdì un uomo sospettato di aver danneggiato diverse auto durante la notte."},
+		{"pt", "A polícia prendeu na sexta-feira um homem suspeito de ter danificado vários carros durante a noite."},
+		{"nl", "De politie heeft vrijdag een man aangehouden die ervan wordt verdacht 's nachts meerdere auto's te hebben beschadigd."},
+		{"sv", "Polisen grep på fredagen en man som misstänks för att ha skadat flera bilar under natten."},
+		{"da", "Politiet anholdt fredag en mand, som er mistænkt for at have beskadiget flere biler i løbet af natten."},
+		{"pl", "Policja zatrzymała w piątek mężczyznę podejrzanego o uszkodzenie w nocy kilku samochodów."},
+		{"cs", "Policie v pátek zadržela muže, který je podezřelý z toho, že v noci poškodil několik aut."},
+		{"tr", "Polis cuma günü gece boyunca birkaç arabaya zarar verdiğinden şüphelenilen bir adamı gözaltına aldı."},
+		{"id", "Polisi pada hari Jumat menangkap seorang pria yang diduga merusak beberapa mobil pada malam hari."},
+		{"ro", "Poliția a arestat vineri un bărbat bănuit că a avariat mai multe mașini în timpul nopții."},
+		{"fi", "Poliisi pidätti perjantaina miehen, jonka epäillään vahingoittaneen useita autoja yön aikana."},
+		{"hu", "A rendőrség pénteken őrizetbe vett egy férfit, akit azzal gyanúsítanak, hogy az éjszaka több autót megrongált."},
+		{"vi", "Cảnh sát hôm thứ Sáu đã bắt giữ một người đàn ông bị nghi làm hư hỏng nhiều xe ô tô trong đêm."},
+		{"ru", "Полиция в пятницу задержала мужчину, подозреваемого в том, что ночью он повредил несколько автомобилей."},
+		{"uk", "Поліція в пʼятницю затримала чоловіка, якого підозрюють у тому, що вночі він пошкодив кілька автомобілів."},
+		{"bg", "Полицията задържа в петък мъж, заподозрян, че през нощта е повредил няколко автомобила."},
+		{"ca", "La policia va detenir divendres un home sospitós d'haver fet malbé diversos cotxes durant la nit."},
+		{"no", "Politiet pågrep fredag en mann som er mistenkt for å ha skadet flere biler i løpet av natten."},
+		{"is", "Lögreglan handtók á föstudag mann sem grunaður er um að hafa skemmt nokkra bíla um nóttina."},
+		{"sk", "Polícia v piatok zadržala muža, ktorý je podozrivý z toho, že v noci poškodil niekoľko áut."},
+		{"sl", "Policija je v petek prijela moškega, ki je osumljen, da je ponoči poškodoval več avtomobilov."},
+		{"hr", "Policija je u petak uhitila muškarca koji je osumnjičen da je tijekom noći oštetio nekoliko automobila."},
+		{"et", "Politsei pidas reedel kinni mehe, keda kahtlustatakse mitme auto lõhkumises öö jooksul."},
+		{"lv", "Policija piektdien aizturēja vīrieti, kurš tiek turēts aizdomās par vairāku automašīnu bojāšanu naktī."},
+		{"lt", "Policija penktadienį sulaikė vyrą, įtariamą naktį apgadinus kelis automobilius."},
+		{"sr", "Полиција је у петак ухапсила мушкарца који је осумњичен да је током ноћи оштетио неколико аутомобила."},
+		{"mk", "Полицијата во петокот уапси маж кој е осомничен дека во текот на ноќта оштетил неколку автомобили."},
+		{"be", "Паліцыя ў пятніцу затрымала мужчыну, якога падазраюць у тым, што ўначы ён пашкодзіў некалькі аўтамабіляў."},
+		{"zh", "警方周五逮捕了一名男子，他涉嫌在夜间损坏了多辆汽车，案件仍在调查之中。"},
+		{"ja", "警察は金曜日、夜間に複数の車を傷つけた疑いで男を逮捕したと発表しました。"},
+		{"ko", "경찰은 금요일 밤사이 여러 대의 차량을 파손한 혐의로 한 남성을 체포했다고 밝혔다."},
+		{"ar", "ألقت الشرطة يوم الجمعة القبض على رجل يشتبه في أنه ألحق أضرارا بعدة سيارات خلال الليل."},
+		{"fa", "پلیس روز جمعه مردی را که گمان می‌رود شب گذشته چند خودرو را تخریب کرده بود بازداشت کرد."},
+		{"he", "המשטרה עצרה ביום שישי אדם החשוד שפגע בכמה מכוניות במהלך הלילה."},
+		{"el", "Η αστυνομία συνέλαβε την Παρασκευή έναν άνδρα που είναι ύποπτος ότι προκάλεσε ζημιές σε αυτοκίνητα."},
+		{"th", "ตำรวจจับกุมชายคนหนึ่งเมื่อวันศุกร์ซึ่งต้องสงสัยว่าทำให้รถยนต์หลายคันเสียหายในช่วงกลางคืน"},
+		{"hi", "पुलिस ने शुक्रवार को एक व्यक्ति को गिरफ्तार किया जिस पर रात में कई कारों को नुकसान पहुंचाने का संदेह है।"},
+	}
+
+	for _, tt := range tests {
+		got := Detect(tt.text)
+		if got.Code != tt.want {
+			t.Errorf("Detect(%q) = %q, want %q", tt.text, got.Code, tt.want)
+		}
+		if got.Confidence <= 0 || got.Confidence > 1 || got.Source != SourceText {
+			t.Errorf("Detect(%q) = %+v, want a confidence in (0, 1] from text", tt.text, got)
+		}
+	}
+
+	if got := Detect("OK 123 !!!"); got.Code != "" {
+		t.Errorf("Expected no language for short text, got %+v", got)
+	}
+}
+
+func TestDetect_CloseLanguages(t *testing.T) {
+	tests := []struct {
+		want string
+		text string
+	}{
+		{"da", "Byrådet vedtog tirsdag et nyt budget efter en lang debat om den offentlige transport, boliger og reparationen af de gamle broer."},
+		{"no", "Bystyret vedtok tirsdag et nytt budsjett etter en lang debatt om kollektivtransport, boliger og reparasjon av de gamle bruene."},
+		{"es", "El ayuntamiento aprobó el martes un nuevo presupuesto tras un largo debate sobre el transporte público, la vivienda y los puentes."},
+		{"ca", "L'ajuntament va aprovar dimarts un nou pressupost després d'un llarg debat sobre el transport públic, l'habitatge i els ponts."},
+		{"cs", "Městské zastupitelstvo v úterý po dlouhé debatě o veřejné dopravě, bydlení a opravách starých mostů schválilo nový rozpočet."},
+		{"sk", "Mestské zastupiteľstvo v utorok po dlhej diskusii o verejnej doprave, bývaní a oprave starých mostov schválilo nový rozpočet."},
+	}
+
+	for _, tt := range tests {
+		if got := Detect(tt.text); got.Code != tt.want || got.Confidence < 0.9 {
+			t.Errorf("Detect(%q) = %+v, want %q with high confidence", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestDetect_Unsupported(t *testing.T) {
+	for lang, text := range map[string]string{
+		"cy": "Cymeradwyodd cyngor y ddinas gyllideb newydd ddydd Mawrth ar ôl dadl hir am drafnidiaeth gyhoeddus, tai a thrwsio hen bontydd.",
+		"sw": "Baraza la jiji liliidhinisha bajeti mpya siku ya Jumanne baada ya mjadala mrefu kuhusu usafiri wa umma, makazi na ukarabati wa madaraja.",
+		"eu": "Udalbatzak asteartean aurrekontu berria onartu zuen garraio publikoari, etxebizitzari eta zubi zaharren konponketari buruzko eztabaidaren ondoren.",
+		"az": "Şəhər şurası çərşənbə axşamı ictimai nəqliyyat, mənzil və köhnə körpülərin təmiri barədə uzun müzakirədən sonra yeni büdcəni təsdiqlədi.",
+		"kk": "Қалалық кеңес сейсенбі күні қоғамдық көлік, тұрғын үй және ескі көпірлерді жөндеу туралы ұзақ пікірталастан кейін жаңа бюджетті бекітті.",
+	} {
+		if got := Detect(text); got.Confidence > 0.2 {
+			t.Errorf("Expected low confidence for unsupported language %s, got %+v", lang, got)
+		}
+	}
+}
+
+// TestDetect_Evaluation runs the evaluation set in testdata/eval.txt, one
+// "code|paragraph" line per sample, and checks the results listed in
+// profiles/README.md.
+func TestDetect_Evaluation(t *testing.T) {
+	// Close relatives without a profile are reported as their neighbor
+	neighbors := map[string]string{"nn": "no", "ms": "id", "af": "nl", "gl": "es", "la": "ca"}
+	// Other languages without a profile get a reduced confidence
+	reduced := map[string]bool{"tl": true, "eo": true}
+
+	f, err := os.Open("testdata/eval.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var samples int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		lang, text, ok := strings.Cut(scanner.Text(), "|")
+		if !ok {
+			continue
+		}
+		samples++
+		got := Detect(text)
+
+		switch {
+		case slices.Contains(Supported(), lang):
+			if got.Code != lang || got.Confidence < 0.9 {
+				t.Errorf("%s: got %+v, want %s with high confidence", lang, got, lang)
+			}
+		case neighbors[lang] != "":
+			if got.Code != neighbors[lang] {
+				t.Errorf("%s: got %+v, want its neighbor %s", lang, got, neighbors[lang])
+			}
+		case reduced[lang]:
+			if got.Confidence < 0.3 || got.Confidence > 0.7 {
+				t.Errorf("%s: got %+v, want a confidence of 0.3-0.7", lang, got)
+			}
+		default:
+			if got.Confidence >= 0.1 {
+				t.Errorf("%s: got %+v, want a confidence below 0.1", lang, got)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if samples == 0 {
+		t.Fatal("Empty evaluation set")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"en-US":     "en",
+		"pt_BR":     "pt",
+		" FR ":      "fr",
+		"iw":        "he",
+		"nb-NO":     "no",
+		"zh-Hant":   "zh",
+		"eng":       "",
+		"":          "",
+		"x-klingon": "",
+	}
+	for tag, want := range tests {
+		if got := Normalize(tag); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", tag, got, want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	german := "Die Polizei hat am Freitag einen Mann festgenommen, der in der Nacht mehrere Autos beschädigt haben soll."
+
+	got := Resolve([]Hint{{Code: "en", Source: "html lang"}, {Code: "en", Source: "og:locale"}}, german)
+	if got.Code != "en" || got.Source != "html lang" || got.Confidence < 0.95 {
+		t.Errorf("Expected agreeing hints to win, got %+v", got)
+	}
+
+	got = Resolve([]Hint{{Code: "en", Source: "html lang"}, {Code: "de", Source: "og:locale"}}, german)
+	if got.Code != "de" || got.Source != SourceText {
+		t.Errorf("Expected text detection for contradicting hints, got %+v", got)
+	}
+
+	got = Resolve(nil, german)
+	if got.Code != "de" || got.Source != SourceText {
+		t.Errorf("Expected text detection without hints, got %+v", got)
+	}
+
+	got = Resolve([]Hint{{Code: "en", Source: "html lang"}, {Code: "de", Source: "og:locale"}}, "")
+	if got.Code != "en" || got.Confidence >= 0.5 {
+		t.Errorf("Expected the first hint with low confidence for empty text, got %+v", got)
+	}
+
+	if got := Resolve(nil, ""); got.Code != "" {
+		t.Errorf("Expected no language, got %+v", got)
+	}
+}
+
+func TestReadingTime(t *testing.T) {
+	if got := ReadingTime(456, "en"); got != 2*time.Minute {
+		t.Errorf("ReadingTime(456, en) = %v, want 2m", got)
+	}
+	if got := ReadingTime(357, "ja"); got != time.Minute {
+		t.Errorf("ReadingTime(357, ja) = %v, want 1m", got)
+	}
+	if got := ReadingTime(100, "xx"); got != 30*time.Second {
+		t.Errorf("ReadingTime(100, xx) = %v, want 30s", got)
+	}
+	if got := ReadingTime(0, "en"); got != 0 {
+		t.Errorf("ReadingTime(0, en) = %v, want 0", got)
+	}
+}
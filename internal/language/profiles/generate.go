@@ -0,0 +1,239 @@
+//go:build ignore
+
+// This program rebuilds the trigram profiles from the lingua-go language
+// models. It is run by go generate in internal/language:
+//
+//	go run ./profiles/generate.go
+//
+// lingua-go stores the conditional probabilities P(a), P(b|a) and P(c|ab)
+// of its unigrams, bigrams and trigrams. The joint frequency of a trigram
+// abc is their product, normalized over the language's trigrams; each
+// profile keeps the most frequent trigrams as counts per million.
+package main
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+)
+
+const (
+	// module is the lingua-go version whose models the profiles come from
+	module = "github.com/pemistahl/lingua-go@v1.4.0"
+
+	// profileSize is the number of trigrams kept per language
+	profileSize = 2000
+)
+
+// languages maps the lingua-go model directories to the profile codes.
+// Norwegian comes from the Bokmål model.
+var languages = map[string]string{
+	"be": "be", "bg": "bg", "ca": "ca", "cs": "cs", "da": "da", "de": "de",
+	"en": "en", "es": "es", "et": "et", "fi": "fi", "fr": "fr", "hr": "hr",
+	"hu": "hu", "id": "id", "is": "is", "it": "it", "lt": "lt", "lv": "lv",
+	"mk": "mk", "nb": "no", "nl": "nl", "pl": "pl", "pt": "pt", "ro": "ro",
+	"ru": "ru", "sk": "sk", "sl": "sl", "sr": "sr", "sv": "sv", "tr": "tr",
+	"uk": "uk", "vi": "vi",
+}
+
+func main() {
+	dir, err := moduleDir()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for model, code := range languages {
+		root := filepath.Join(dir, "language-models", model)
+		unigrams, err := loadModel(filepath.Join(root, "unigrams.pb.bin.zip"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		bigrams, err := loadModel(filepath.Join(root, "bigrams.pb.bin.zip"))
+		if err != nil {
+			log.Fatal(err)
+		}
+		trigrams, err := loadModel(filepath.Join(root, "trigrams.pb.bin.zip"))
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if err := writeProfile(filepath.Join("profiles", code+".txt"), unigrams, bigrams, trigrams); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	license, err := os.ReadFile(filepath.Join(dir, "LICENSE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join("profiles", "LICENSE"), license, 0o644); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("wrote %d profiles from %s", len(languages), module)
+}
+
+// moduleDir downloads lingua-go and returns its directory.
+func moduleDir() (string, error) {
+	out, err := exec.Command("go", "mod", "download", "-json", module).Output()
+	if err != nil {
+		return "", fmt.Errorf("downloading %s: %w", module, err)
+	}
+	var info struct{ Dir string }
+	if err := json.Unmarshal(out, &info); err != nil {
+		return "", err
+	}
+	return info.Dir, nil
+}
+
+// writeProfile writes the most frequent trigrams of a language with their
+// counts per million.
+func writeProfile(path string, unigrams, bigrams, trigrams map[string]float64) error {
+	type gram struct {
+		text string
+		p    float64
+	}
+	var grams []gram
+	var sum float64
+	for text, p := range trigrams {
+		r := []rune(text)
+		p *= unigrams[string(r[:1])] * bigrams[string(r[:2])]
+		grams = append(grams, gram{text, p})
+		sum += p
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if grams[i].p != grams[j].p {
+			return grams[i].p > grams[j].p
+		}
+		return grams[i].text < grams[j].text
+	})
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	for _, g := range grams[:min(profileSize, len(grams))] {
+		count := math.RoundToEven(g.p / sum * 1e6)
+		if count < 1 {
+			break
+		}
+		fmt.Fprintf(w, "%s %d\n", g.text, int(count))
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadModel reads a zipped lingua-go model and returns its n-gram
+// probabilities.
+func loadModel(path string) (map[string]float64, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	if len(zr.File) == 0 {
+		return nil, fmt.Errorf("%s: empty archive", path)
+	}
+	f, err := zr.File[0].Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// The model is a SerializableLanguageModel protocol buffer, whose
+	// field 4 holds SerializableNgramSets of a probability (field 1) and
+	// the n-grams that share it (field 2)
+	probabilities := make(map[string]float64)
+	err = readFields(data, func(field int, value []byte, _ uint64) error {
+		if field != 4 {
+			return nil
+		}
+		var p float64
+		var ngrams []string
+		err := readFields(value, func(field int, value []byte, bits uint64) error {
+			switch field {
+			case 1:
+				p = math.Float64frombits(bits)
+			case 2:
+				ngrams = append(ngrams, string(value))
+			}
+			return nil
+		})
+		for _, ngram := range ngrams {
+			probabilities[ngram] = p
+		}
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return probabilities, nil
+}
+
+// readFields calls fn for each field of a protocol buffer message with
+// the field number and its length-delimited bytes or fixed or varint
+// value.
+func readFields(data []byte, fn func(field int, value []byte, bits uint64) error) error {
+	errTruncated := errors.New("truncated message")
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return errTruncated
+		}
+		data = data[n:]
+
+		var value []byte
+		var bits uint64
+		switch key & 7 {
+		case 0: // varint
+			bits, n = binary.Uvarint(data)
+			if n <= 0 {
+				return errTruncated
+			}
+			data = data[n:]
+		case 1: // 64-bit
+			if len(data) < 8 {
+				return errTruncated
+			}
+			bits = binary.LittleEndian.Uint64(data)
+			data = data[8:]
+		case 2: // length-delimited
+			length, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < length {
+				return errTruncated
+			}
+			value = data[n : n+int(length)]
+			data = data[n+int(length):]
+		case 5: // 32-bit
+			if len(data) < 4 {
+				return errTruncated
+			}
+			bits = uint64(binary.LittleEndian.Uint32(data))
+			data = data[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d", key&7)
+		}
+
+		if err := fn(int(key>>3), value, bits); err != nil {
+			return err
+		}
+	}
+	return nil
+}
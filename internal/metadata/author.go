@@ -15,32 +15,39 @@ var (
 
 // ExtractAuthor extracts the article author from a document.
 func ExtractAuthor(doc *goquery.Document) string {
+	value, _ := ExtractAuthorWithSource(doc)
+	return value
+}
+
+// ExtractAuthorWithSource extracts the article author from a document
+// and returns the name of the source it came from.
+func ExtractAuthorWithSource(doc *goquery.Document) (string, string) {
 	// Try meta author
 	if author := getMetaContent(doc, "author"); author != "" {
-		return cleanAuthor(author)
+		return cleanAuthor(author), "meta author"
 	}
 
 	// Try og:article:author
 	if author := getMetaContent(doc, "article:author"); author != "" {
-		return cleanAuthor(author)
+		return cleanAuthor(author), "article:author"
 	}
 
 	// Try schema.org author
 	if author := getSchemaAuthor(doc); author != "" {
-		return cleanAuthor(author)
+		return cleanAuthor(author), "schema.org author"
 	}
 
 	// Try common author selectors
 	if author := getAuthorBySelector(doc); author != "" {
-		return cleanAuthor(author)
+		return cleanAuthor(author), "author selector"
 	}
 
 	// Try byline patterns
 	if author := getAuthorByByline(doc); author != "" {
-		return cleanAuthor(author)
+		return cleanAuthor(author), "byline"
 	}
 
-	return ""
+	return "", ""
 }
 
 // getSchemaAuthor gets author from schema.org markup.
@@ -27,42 +27,49 @@ var dateFormats = []string{
 
 // ExtractDate extracts the publication date from a document.
 func ExtractDate(doc *goquery.Document) *time.Time {
+	value, _ := ExtractDateWithSource(doc)
+	return value
+}
+
+// ExtractDateWithSource extracts the publication date from a document
+// and returns the name of the source it came from.
+func ExtractDateWithSource(doc *goquery.Document) (*time.Time, string) {
 	// Try meta article:published_time (Open Graph)
 	if date := parseMetaDate(doc, "article:published_time"); date != nil {
-		return date
+		return date, "article:published_time"
 	}
 
 	// Try meta datePublished
 	if date := parseMetaDate(doc, "datePublished"); date != nil {
-		return date
+		return date, "meta datePublished"
 	}
 
 	// Try meta date
 	if date := parseMetaDate(doc, "date"); date != nil {
-		return date
+		return date, "meta date"
 	}
 
 	// Try meta DC.date
 	if date := parseMetaDate(doc, "DC.date"); date != nil {
-		return date
+		return date, "DC.date"
 	}
 
 	// Try schema.org datePublished
 	if date := getSchemaDate(doc); date != nil {
-		return date
+		return date, "schema.org datePublished"
 	}
 
 	// Try time element
 	if date := getTimeElement(doc); date != nil {
-		return date
+		return date, "time element"
 	}
 
 	// Try common date selectors
 	if date := getDateBySelector(doc); date != nil {
-		return date
+		return date, "date selector"
 	}
 
-	return nil
+	return nil, ""
 }
 
 // parseMetaDate parses a date from a meta tag.
@@ -173,6 +180,12 @@ func getDateBySelector(doc *goquery.Document) *time.Time {
 	return nil
 }
 
+// ParseDate parses a date string in any of the supported formats.
+// It returns nil if the string cannot be parsed.
+func ParseDate(dateStr string) *time.Time {
+	return parseDate(dateStr)
+}
+
 // parseDate attempts to parse a date string in various formats.
 func parseDate(dateStr string) *time.Time {
 	dateStr = strings.TrimSpace(dateStr)
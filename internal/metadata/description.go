@@ -0,0 +1,34 @@
+package metadata
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// whitespacePattern matches runs of whitespace.
+var whitespacePattern = regexp.MustCompile(`\s+`)
+
+// ExtractDescription extracts the page description from a document.
+func ExtractDescription(doc *goquery.Document) string {
+	value, _ := ExtractDescriptionWithSource(doc)
+	return value
+}
+
+// ExtractDescriptionWithSource extracts the page description from the
+// og:description, twitter:description or description meta tag and returns
+// the name of the source it came from.
+func ExtractDescriptionWithSource(doc *goquery.Document) (string, string) {
+	for _, property := range []string{"og:description", "twitter:description", "description"} {
+		if description := collapseSpace(getMetaContent(doc, property)); description != "" {
+			return description, property
+		}
+	}
+	return "", ""
+}
+
+// collapseSpace trims s and collapses its runs of whitespace.
+func collapseSpace(s string) string {
+	return whitespacePattern.ReplaceAllString(strings.TrimSpace(s), " ")
+}
@@ -0,0 +1,49 @@
+package metadata
+
+import (
+	"strings"
+
+	"github.com/LeadNewswire/article-extractor/internal/language"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Language hint sources.
+const (
+	SourceHTMLLang        = "html lang"
+	SourceContentLanguage = "content-language"
+	SourceOGLocale        = "og:locale"
+)
+
+// ExtractLanguageHints returns the languages a page declares in the html
+// lang attribute, the content-language meta tag and og:locale, in that
+// order, as ISO 639-1 codes. Tags that are not language codes are skipped.
+func ExtractLanguageHints(doc *goquery.Document) []language.Hint {
+	var hints []language.Hint
+	add := func(tag, source string) {
+		if code := language.Normalize(tag); code != "" {
+			hints = append(hints, language.Hint{Code: code, Source: source})
+		}
+	}
+
+	html := doc.Find("html").First()
+	if lang, ok := html.Attr("lang"); ok {
+		add(lang, SourceHTMLLang)
+	} else if lang, ok := html.Attr("xml:lang"); ok {
+		add(lang, SourceHTMLLang)
+	}
+
+	doc.Find("meta[http-equiv]").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		equiv, _ := sel.Attr("http-equiv")
+		if !strings.EqualFold(strings.TrimSpace(equiv), "content-language") {
+			return true
+		}
+		// The header may list several languages; the first is primary
+		content, _ := sel.Attr("content")
+		first, _, _ := strings.Cut(content, ",")
+		add(first, SourceContentLanguage)
+		return false
+	})
+
+	add(getMetaContent(doc, "og:locale"), SourceOGLocale)
+	return hints
+}
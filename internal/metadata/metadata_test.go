@@ -264,3 +264,120 @@ func TestExtractAuthors(t *testing.T) {
 		})
 	}
 }
+
+func TestExtractWithSource(t *testing.T) {
+	html := `<html><head>
+		<meta name="twitter:title" content="Twitter Title">
+		<meta property="article:published_time" content="2024-01-15T10:00:00Z">
+	</head><body><div class="byline">By Writer Name</div></body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if title, source := ExtractTitleWithSource(doc); title != "Twitter Title" || source != "twitter:title" {
+		t.Errorf("ExtractTitleWithSource = %q, %q", title, source)
+	}
+
+	if author, source := ExtractAuthorWithSource(doc); author != "Writer Name" || source != "byline" {
+		t.Errorf("ExtractAuthorWithSource = %q, %q", author, source)
+	}
+
+	if date, source := ExtractDateWithSource(doc); date == nil || source != "article:published_time" {
+		t.Errorf("ExtractDateWithSource = %v, %q", date, source)
+	}
+}
+
+func TestExtractSchemaTypes(t *testing.T) {
+	html := `<html><head>
+<meta property="og:type" content="Article">
+<script type="application/ld+json">{"@context":"https://schema.org","@graph":[{"@type":"WebPage"},{"@type":["NewsArticle","Article"]}]}</script>
+<script type="application/ld+json">[{"@type":"Organization"},{"@type":"WebPage"}]</script>
+<script type="application/ld+json">{not json</script>
+</head><body></body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	types := ExtractSchemaTypes(doc)
+	if strings.Join(types, ",") != "WebPage,NewsArticle,Article,Organization" {
+		t.Errorf("ExtractSchemaTypes = %v", types)
+	}
+
+	if ogType := ExtractOGType(doc); ogType != "article" {
+		t.Errorf("ExtractOGType = %q, want %q", ogType, "article")
+	}
+}
+
+func TestExtractLanguageHints(t *testing.T) {
+	html := `<html lang="en-US"><head>
+<meta http-equiv="Content-Language" content="de-DE, en">
+<meta property="og:locale" content="fr_FR">
+</head><body></body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, hint := range ExtractLanguageHints(doc) {
+		got = append(got, hint.Source+"="+hint.Code)
+	}
+	want := "html lang=en,content-language=de,og:locale=fr"
+	if strings.Join(got, ",") != want {
+		t.Errorf("ExtractLanguageHints = %v, want %s", got, want)
+	}
+
+	doc, _ = goquery.NewDocumentFromReader(strings.NewReader(`<html lang="x-default"><body></body></html>`))
+	if hints := ExtractLanguageHints(doc); len(hints) != 0 {
+		t.Errorf("Expected no hints, got %v", hints)
+	}
+}
+
+func TestExtractDescriptionWithSource(t *testing.T) {
+	tests := []struct {
+		html   string
+		want   string
+		source string
+	}{
+		{`<meta name="description" content="Plain"><meta property="og:description" content=" Open
+  Graph ">`, "Open Graph", "og:description"},
+		{`<meta name="twitter:description" content="Twitter"><meta name="description" content="Plain">`, "Twitter", "twitter:description"},
+		{`<meta name="description" content="Plain">`, "Plain", "description"},
+		{`<meta property="og:description" content="  ">`, "", ""},
+	}
+
+	for _, tt := range tests {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><head>" + tt.html + "</head></html>"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, source := ExtractDescriptionWithSource(doc)
+		if got != tt.want || source != tt.source {
+			t.Errorf("ExtractDescriptionWithSource(%s) = %q, %q, want %q, %q", tt.html, got, source, tt.want, tt.source)
+		}
+	}
+}
+
+func TestExtractKeywordTags(t *testing.T) {
+	html := `<html><head>
+<meta name="news_keywords" content="Transit, City Council,  budget ">
+<meta property="article:tag" content="Light rail">
+<meta property="article:tag" content="transit">
+<meta property="article:tag" content="">
+</head><body></body></html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tags := ExtractKeywordTags(doc)
+	if strings.Join(tags, ",") != "Transit,City Council,budget,Light rail" {
+		t.Errorf("ExtractKeywordTags = %q", tags)
+	}
+}
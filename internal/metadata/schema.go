@@ -0,0 +1,61 @@
+package metadata
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ExtractOGType returns the lowercased og:type of a page, or "".
+func ExtractOGType(doc *goquery.Document) string {
+	content, _ := doc.Find("meta[property='og:type']").First().Attr("content")
+	return strings.ToLower(strings.TrimSpace(content))
+}
+
+// ExtractSchemaTypes returns the schema.org @type values declared in the
+// page's JSON-LD blocks, including those nested in @graph, in document
+// order and without duplicates. Invalid JSON-LD is skipped.
+func ExtractSchemaTypes(doc *goquery.Document) []string {
+	var types []string
+	seen := make(map[string]bool)
+
+	doc.Find("script[type='application/ld+json']").Each(func(_ int, sel *goquery.Selection) {
+		var data interface{}
+		if err := json.Unmarshal([]byte(sel.Text()), &data); err != nil {
+			return
+		}
+		collectSchemaTypes(data, func(t string) {
+			if !seen[t] {
+				seen[t] = true
+				types = append(types, t)
+			}
+		})
+	})
+
+	return types
+}
+
+// collectSchemaTypes walks top-level JSON-LD items and @graph entries.
+func collectSchemaTypes(data interface{}, add func(string)) {
+	switch value := data.(type) {
+	case []interface{}:
+		for _, item := range value {
+			collectSchemaTypes(item, add)
+		}
+	case map[string]interface{}:
+		switch t := value["@type"].(type) {
+		case string:
+			add(t)
+		case []interface{}:
+			for _, item := range t {
+				if s, ok := item.(string); ok {
+					add(s)
+				}
+			}
+		}
+		if graph, ok := value["@graph"]; ok {
+			collectSchemaTypes(graph, add)
+		}
+	}
+}
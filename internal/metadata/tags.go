@@ -0,0 +1,30 @@
+package metadata
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// ExtractKeywordTags returns the keywords a page lists in its news_keywords
+// meta tag and article:tag meta tags, in order and without duplicates.
+func ExtractKeywordTags(doc *goquery.Document) []string {
+	var tags []string
+	seen := make(map[string]bool)
+	add := func(tag string) {
+		tag = collapseSpace(tag)
+		if key := strings.ToLower(tag); tag != "" && !seen[key] {
+			seen[key] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	for _, tag := range strings.Split(getMetaContent(doc, "news_keywords"), ",") {
+		add(tag)
+	}
+	doc.Find("meta[property='article:tag'], meta[name='article:tag']").Each(func(_ int, sel *goquery.Selection) {
+		content, _ := sel.Attr("content")
+		add(content)
+	})
+	return tags
+}
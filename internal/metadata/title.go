@@ -12,37 +12,44 @@ var titleSeparators = []string{" | ", " - ", " :: ", " / ", " » ", " — ", " 
 
 // ExtractTitle extracts the article title from a document.
 func ExtractTitle(doc *goquery.Document) string {
+	value, _ := ExtractTitleWithSource(doc)
+	return value
+}
+
+// ExtractTitleWithSource extracts the article title from a document
+// and returns the name of the source it came from.
+func ExtractTitleWithSource(doc *goquery.Document) (string, string) {
 	// Try og:title first
 	if title := getMetaContent(doc, "og:title"); title != "" {
-		return cleanTitle(title)
+		return cleanTitle(title), "og:title"
 	}
 
 	// Try twitter:title
 	if title := getMetaContent(doc, "twitter:title"); title != "" {
-		return cleanTitle(title)
+		return cleanTitle(title), "twitter:title"
 	}
 
 	// Try schema.org headline
 	if title := getSchemaHeadline(doc); title != "" {
-		return cleanTitle(title)
+		return cleanTitle(title), "schema.org headline"
 	}
 
 	// Try h1 in article
 	if title := getArticleH1(doc); title != "" {
-		return cleanTitle(title)
+		return cleanTitle(title), "article h1"
 	}
 
 	// Try first h1
 	if title := getFirstH1(doc); title != "" {
-		return cleanTitle(title)
+		return cleanTitle(title), "h1"
 	}
 
 	// Try title tag
 	if title := getTitleTag(doc); title != "" {
-		return cleanTitle(title)
+		return cleanTitle(title), "title tag"
 	}
 
-	return ""
+	return "", ""
 }
 
 // getMetaContent gets content from a meta tag.
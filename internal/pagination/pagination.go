@@ -0,0 +1,275 @@
+package pagination
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Scoring constants for next-page link candidates.
+const (
+	// NextTextBonus is the bonus for link text like "Next" or "»".
+	NextTextBonus = 50
+
+	// NextClassBonus is the bonus for a class/id containing "next".
+	NextClassBonus = 25
+
+	// PagerClassBonus is the bonus for a class/id suggesting pagination.
+	PagerClassBonus = 10
+
+	// PageNumberBonus is the bonus for a link to the following page number.
+	PageNumberBonus = 50
+
+	// SameArticleBonus is the bonus for a URL that only differs by page number.
+	SameArticleBonus = 25
+
+	// NegativeTextPenalty is the penalty for links like "Previous" or "Comments".
+	NegativeTextPenalty = -100
+
+	// MinNextPageScore is the minimum score for a link to be the next page.
+	MinNextPageScore = 50
+)
+
+var (
+	// nextTextRegex matches link text that points to the next page.
+	nextTextRegex = regexp.MustCompile(`(?i)^\s*(next( page)?|continue|more|›|»|>|→)\s*(›|»|>|→)?\s*$`)
+
+	// negativeTextRegex matches link text that does not point forward.
+	negativeTextRegex = regexp.MustCompile(`(?i)(prev|previous|first|last|comment|«|‹|←)`)
+
+	// pagerClassRegex matches class/id values of pagination widgets.
+	pagerClassRegex = regexp.MustCompile(`(?i)(pag(e|ing|inat)|pager)`)
+
+	// nextClassRegex matches class/id values of next-page links.
+	nextClassRegex = regexp.MustCompile(`(?i)next`)
+
+	// queryPageRegex matches the page number in a query parameter.
+	queryPageRegex = regexp.MustCompile(`(?i)(?:^|&)(?:page|p|pg|paged|pagina|seite)=(\d{1,3})(?:&|$)`)
+
+	// pagePathRegex matches an explicit page number at the end of a path,
+	// as in "/story/page/2".
+	pagePathRegex = regexp.MustCompile(`(?i)/page/(\d{1,3})/?$`)
+
+	// numberPathRegex matches a bare number at the end of a path, as in
+	// "/story/2". Article IDs look the same, so it is only taken for a page
+	// number in a pager.
+	numberPathRegex = regexp.MustCompile(`/(\d{1,3})/?$`)
+)
+
+// pagerDepth is the number of ancestors of a link searched for a pager.
+const pagerDepth = 3
+
+// FindNextPageURL returns the absolute URL of the next page of a paginated
+// article, or an empty string if there is none. It must be called before
+// preprocessing, which strips pagination widgets.
+func FindNextPageURL(doc *goquery.Document, pageURL string) string {
+	current, err := url.Parse(pageURL)
+	if err != nil || current.Host == "" {
+		return ""
+	}
+
+	// Prefer explicit rel="next" hints
+	var next string
+	doc.Find("link[rel='next'], a[rel='next']").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		candidate := resolve(current, dom.GetAttribute(sel, "href"))
+		if candidate == nil || candidate.Host != current.Host || IsSamePage(candidate.String(), pageURL) {
+			return true
+		}
+		next = candidate.String()
+		return false
+	})
+	if next != "" {
+		return next
+	}
+
+	// Score pager links
+	bestScore := 0
+	doc.Find("a[href]").Each(func(_ int, sel *goquery.Selection) {
+		candidate := resolve(current, dom.GetAttribute(sel, "href"))
+		if candidate == nil || candidate.Host != current.Host {
+			return
+		}
+		if IsSamePage(candidate.String(), pageURL) {
+			return
+		}
+
+		score := scoreLink(sel, candidate, current)
+		if score >= MinNextPageScore && score > bestScore {
+			bestScore = score
+			next = candidate.String()
+		}
+	})
+
+	return next
+}
+
+// scoreLink scores a link as a next-page candidate. Link text such as
+// "Next" or "More" is not enough on its own: the link must be in a pager,
+// have a next class, or differ from the page URL only by the following
+// page number. Otherwise it scores 0.
+func scoreLink(sel *goquery.Selection, candidate, current *url.URL) int {
+	score := 0
+	text := dom.GetText(sel)
+
+	if negativeTextRegex.MatchString(text) {
+		score += NegativeTextPenalty
+	}
+	if nextTextRegex.MatchString(text) {
+		score += NextTextBonus
+	}
+
+	// Check the link and its parent for pagination class names
+	for _, s := range []*goquery.Selection{sel, sel.Parent()} {
+		classID := dom.GetAttribute(s, "class") + " " + dom.GetAttribute(s, "id")
+		if nextClassRegex.MatchString(classID) {
+			score += NextClassBonus
+		}
+		if pagerClassRegex.MatchString(classID) {
+			score += PagerClassBonus
+		}
+	}
+	pager := inPager(sel)
+
+	// Link to the following page number; bare path numbers count only in
+	// a pager
+	pageNumber := PageNumber
+	if pager {
+		pageNumber = pagerPageNumber
+	}
+	currentPage := pageNumber(current)
+	if currentPage == 0 {
+		currentPage = 1
+	}
+	candidatePage := pageNumber(candidate)
+
+	sameArticle := false
+	if candidatePage == currentPage+1 {
+		score += PageNumberBonus
+		if stripPageNumber(candidate, pager) == stripPageNumber(current, pager) {
+			score += SameArticleBonus
+			sameArticle = true
+		}
+	} else if candidatePage > 0 && candidatePage <= currentPage {
+		score += NegativeTextPenalty
+	}
+
+	if n, err := strconv.Atoi(text); err == nil && n != currentPage+1 {
+		score += NegativeTextPenalty
+	}
+
+	if !pager && !sameArticle {
+		return 0
+	}
+	return score
+}
+
+// inPager reports whether a link has a next class, or it or one of its
+// nearest ancestors has a pagination class.
+func inPager(sel *goquery.Selection) bool {
+	if nextClassRegex.MatchString(dom.GetAttribute(sel, "class") + " " + dom.GetAttribute(sel, "id")) {
+		return true
+	}
+	for s, depth := sel, 0; s.Length() > 0 && depth <= pagerDepth; s, depth = s.Parent(), depth+1 {
+		if pagerClassRegex.MatchString(dom.GetAttribute(s, "class") + " " + dom.GetAttribute(s, "id")) {
+			return true
+		}
+	}
+	return false
+}
+
+// PageNumber returns the page number encoded in a URL's query or in a
+// "/page/N" path suffix, or 0 if none. Bare numbers at the end of a path
+// are not taken for page numbers.
+func PageNumber(u *url.URL) int {
+	if m := queryPageRegex.FindStringSubmatch(u.RawQuery); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	if m := pagePathRegex.FindStringSubmatch(u.Path); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// pagerPageNumber returns the page number of a URL linked from a pager,
+// where a bare number at the end of the path is a page number too.
+func pagerPageNumber(u *url.URL) int {
+	if n := PageNumber(u); n > 0 {
+		return n
+	}
+	if m := numberPathRegex.FindStringSubmatch(u.Path); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		return n
+	}
+	return 0
+}
+
+// stripPageNumber returns the URL without its page number, including a
+// bare path number when bare is set.
+func stripPageNumber(u *url.URL, bare bool) string {
+	stripped := *u
+	stripped.Fragment = ""
+	stripped.RawQuery = queryPageRegex.ReplaceAllString(u.RawQuery, "")
+	if pagePathRegex.MatchString(u.Path) {
+		stripped.Path = pagePathRegex.ReplaceAllString(u.Path, "")
+	} else if bare {
+		stripped.Path = numberPathRegex.ReplaceAllString(u.Path, "")
+	}
+	return strings.TrimSuffix(stripped.String(), "/")
+}
+
+// resolve resolves an href against the page URL.
+func resolve(base *url.URL, href string) *url.URL {
+	href = strings.TrimSpace(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(strings.ToLower(href), "javascript:") {
+		return nil
+	}
+
+	ref, err := url.Parse(href)
+	if err != nil {
+		return nil
+	}
+
+	resolved := base.ResolveReference(ref)
+	if resolved.Scheme != "http" && resolved.Scheme != "https" {
+		return nil
+	}
+	resolved.Fragment = ""
+	return resolved
+}
+
+// ResolveURL resolves an href against a page URL, returning an empty string
+// if it is not an http(s) link.
+func ResolveURL(pageURL, href string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return ""
+	}
+	resolved := resolve(base, href)
+	if resolved == nil {
+		return ""
+	}
+	return resolved.String()
+}
+
+// NormalizeURL normalizes a page URL for loop detection.
+func NormalizeURL(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return rawURL
+	}
+	u.Fragment = ""
+	u.Host = strings.ToLower(u.Host)
+	u.Scheme = strings.ToLower(u.Scheme)
+	return strings.TrimSuffix(u.String(), "/")
+}
+
+// IsSamePage reports whether two URLs point to the same page.
+func IsSamePage(a, b string) bool {
+	return NormalizeURL(a) == NormalizeURL(b)
+}
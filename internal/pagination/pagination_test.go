@@ -0,0 +1,134 @@
+package pagination
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestFindNextPageURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		pageURL  string
+		html     string
+		expected string
+	}{
+		{
+			name:     "link rel next",
+			pageURL:  "https://example.com/story",
+			html:     `<html><head><link rel="next" href="/story?page=2"></head><body></body></html>`,
+			expected: "https://example.com/story?page=2",
+		},
+		{
+			name:     "next text",
+			pageURL:  "https://example.com/story",
+			html:     `<html><body><div class="pagination"><a href="/story/2/">Next »</a></div></body></html>`,
+			expected: "https://example.com/story/2/",
+		},
+		{
+			name:     "page number link",
+			pageURL:  "https://example.com/story?page=2",
+			html:     `<html><body><a href="/story?page=1">1</a> <a href="/story?page=3">3</a></body></html>`,
+			expected: "https://example.com/story?page=3",
+		},
+		{
+			name:     "previous link ignored",
+			pageURL:  "https://example.com/story/2",
+			html:     `<html><body><a href="/story/1">« Previous</a></body></html>`,
+			expected: "",
+		},
+		{
+			name:     "other host ignored",
+			pageURL:  "https://example.com/story",
+			html:     `<html><body><a href="https://other.com/story/2">Next</a></body></html>`,
+			expected: "",
+		},
+		{
+			name:     "self link ignored",
+			pageURL:  "https://example.com/story",
+			html:     `<html><head><link rel="next" href="https://example.com/story/"></head><body></body></html>`,
+			expected: "",
+		},
+		{
+			name:     "bare path number in pager",
+			pageURL:  "https://example.com/story/2",
+			html:     `<html><body><ul class="pager"><li><a href="/story/1">1</a></li><li><a href="/story/3">3</a></li></ul></body></html>`,
+			expected: "https://example.com/story/3",
+		},
+		{
+			name:     "more link outside pager ignored",
+			pageURL:  "https://example.com/story",
+			html:     `<html><body><p>Sports</p><a href="/sports">More</a></body></html>`,
+			expected: "",
+		},
+		{
+			name:     "next article id ignored",
+			pageURL:  "https://example.com/story/481",
+			html:     `<html><body><div class="related"><a href="/story/482">Continue</a></div></body></html>`,
+			expected: "",
+		},
+		{
+			name:     "next text with page number",
+			pageURL:  "https://example.com/story",
+			html:     `<html><body><a href="/story?page=2">Continue</a></body></html>`,
+			expected: "https://example.com/story?page=2",
+		},
+		{
+			name:     "no pagination",
+			pageURL:  "https://example.com/story",
+			html:     `<html><body><a href="/about">About us</a></body></html>`,
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := FindNextPageURL(doc, tt.pageURL)
+			if result != tt.expected {
+				t.Errorf("FindNextPageURL = %q, want %q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestPageNumber(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int
+	}{
+		{"https://example.com/story?page=3", 3},
+		{"https://example.com/story?id=7&p=2", 2},
+		{"https://example.com/story/page/4/", 4},
+		{"https://example.com/story/4/", 0},
+		{"https://example.com/story/page/5", 5},
+		{"https://example.com/story", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			u, err := url.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result := PageNumber(u); result != tt.expected {
+				t.Errorf("PageNumber(%q) = %d, want %d", tt.input, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestIsSamePage(t *testing.T) {
+	if !IsSamePage("https://Example.com/story/#top", "https://example.com/story") {
+		t.Error("URLs differing only by fragment and trailing slash should match")
+	}
+	if IsSamePage("https://example.com/story?page=2", "https://example.com/story") {
+		t.Error("URLs with different page parameters should not match")
+	}
+}
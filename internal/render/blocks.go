@@ -0,0 +1,518 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// BlockType identifies the kind of a content block.
+type BlockType string
+
+// Content block types.
+const (
+	BlockParagraph BlockType = "paragraph"
+	BlockHeading   BlockType = "heading"
+	BlockList      BlockType = "list"
+	BlockQuote     BlockType = "quote"
+	BlockCode      BlockType = "code"
+	BlockImage     BlockType = "image"
+	BlockTable     BlockType = "table"
+	BlockEmbed     BlockType = "embed"
+)
+
+// Mark is an inline formatting mark.
+type Mark string
+
+// Inline marks.
+const (
+	MarkBold   Mark = "bold"
+	MarkItalic Mark = "italic"
+	MarkCode   Mark = "code"
+	MarkLink   Mark = "link"
+)
+
+// Block is a typed content block. Only the fields relevant to its Type are set.
+type Block struct {
+	// Type is the kind of block
+	Type BlockType `json:"type"`
+
+	// Level is the heading level (1-6)
+	Level int `json:"level,omitempty"`
+
+	// Inlines is the text of a paragraph or heading
+	Inlines []Inline `json:"inlines,omitempty"`
+
+	// Ordered is true for numbered lists
+	Ordered bool `json:"ordered,omitempty"`
+
+	// Start is the first number of an ordered list
+	Start int `json:"start,omitempty"`
+
+	// Items holds the blocks of each list item
+	Items [][]Block `json:"items,omitempty"`
+
+	// Children holds the blocks inside a quote
+	Children []Block `json:"children,omitempty"`
+
+	// Text is the source of a code block
+	Text string `json:"text,omitempty"`
+
+	// Language is the language hint of a code block
+	Language string `json:"language,omitempty"`
+
+	// URL is the source of an image or embed
+	URL string `json:"url,omitempty"`
+
+	// Alt is the alternative text of an image
+	Alt string `json:"alt,omitempty"`
+
+	// Caption is the caption of an image
+	Caption []Inline `json:"caption,omitempty"`
+
+	// Width is the width of an image or embed in pixels
+	Width int `json:"width,omitempty"`
+
+	// Height is the height of an image or embed in pixels
+	Height int `json:"height,omitempty"`
+
+	// Rows holds the cells of a table
+	Rows [][]TableCell `json:"rows,omitempty"`
+
+	// Tag is the element name of an embed, e.g. "iframe" or "video"
+	Tag string `json:"tag,omitempty"`
+}
+
+// Inline is a run of text with the same formatting.
+type Inline struct {
+	// Text is the run's text; line breaks are "\n"
+	Text string `json:"text"`
+
+	// Marks lists the formatting applied to the run
+	Marks []Mark `json:"marks,omitempty"`
+
+	// Href is the link target for runs marked as links
+	Href string `json:"href,omitempty"`
+}
+
+// TableCell is a cell of a table block.
+type TableCell struct {
+	// Header is true for th cells
+	Header bool `json:"header,omitempty"`
+
+	// Inlines is the cell text
+	Inlines []Inline `json:"inlines,omitempty"`
+}
+
+// embedTags are the elements rendered as embed blocks.
+var embedTags = map[string]bool{
+	"iframe": true,
+	"video":  true,
+	"audio":  true,
+	"embed":  true,
+	"object": true,
+}
+
+// Blocks converts cleaned content into a tree of typed blocks.
+// The selection's children are converted; the selected element itself is
+// treated as a container.
+func Blocks(sel *goquery.Selection) []Block {
+	var blocks []Block
+	for _, node := range sel.Nodes {
+		blocks = append(blocks, buildBlocks(node)...)
+	}
+	return blocks
+}
+
+// blockBuilder collects blocks, gathering runs of inline content into
+// paragraphs.
+type blockBuilder struct {
+	blocks  []Block
+	inlines inlineBuilder
+}
+
+// flush ends the current paragraph.
+func (b *blockBuilder) flush() {
+	if inlines := b.inlines.finish(); inlines != nil {
+		b.blocks = append(b.blocks, Block{Type: BlockParagraph, Inlines: inlines})
+	}
+}
+
+// add appends a block after ending the current paragraph.
+func (b *blockBuilder) add(blocks ...Block) {
+	b.flush()
+	b.blocks = append(b.blocks, blocks...)
+}
+
+// buildBlocks converts the children of a container node.
+func buildBlocks(parent *html.Node) []Block {
+	return buildChildren(parent, nil)
+}
+
+// buildChildren converts the children of a node, leaving out those for
+// which skip returns true.
+func buildChildren(parent *html.Node, skip func(n *html.Node) bool) []Block {
+	b := &blockBuilder{}
+	// Images inside paragraphs split the paragraph
+	b.inlines.onImage = func(n *html.Node) {
+		if image, ok := imageBlock(n); ok {
+			b.add(image)
+		}
+	}
+
+	for child := parent.FirstChild; child != nil; child = child.NextSibling {
+		if skip != nil && skip(child) {
+			continue
+		}
+		if isInline(child) {
+			b.inlines.add(child, nil, "")
+			continue
+		}
+		if child.Type != html.ElementNode {
+			continue
+		}
+		b.add(buildBlock(child)...)
+	}
+	b.flush()
+
+	return b.blocks
+}
+
+// buildBlock converts a block-level element.
+func buildBlock(n *html.Node) []Block {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		if inlines := collectInlines(n); inlines != nil {
+			return []Block{{Type: BlockHeading, Level: int(n.Data[1] - '0'), Inlines: inlines}}
+		}
+		return nil
+
+	case "ul", "ol":
+		if list, ok := listBlock(n); ok {
+			return []Block{list}
+		}
+		return nil
+
+	case "blockquote":
+		if children := buildBlocks(n); children != nil {
+			return []Block{{Type: BlockQuote, Children: children}}
+		}
+		return nil
+
+	case "pre":
+		code := strings.Trim(textContent(n), "\n")
+		if strings.TrimSpace(code) == "" {
+			return nil
+		}
+		return []Block{{Type: BlockCode, Text: code, Language: CodeLanguage(n)}}
+
+	case "table":
+		if rows := tableCells(n); rows != nil {
+			return []Block{{Type: BlockTable, Rows: rows}}
+		}
+		return nil
+
+	case "figure":
+		return figureBlocks(n)
+
+	case "img":
+		if image, ok := imageBlock(n); ok {
+			return []Block{image}
+		}
+		return nil
+
+	case "hr", "br":
+		return nil
+	}
+
+	if embedTags[n.Data] {
+		if embed, ok := embedBlock(n); ok {
+			return []Block{embed}
+		}
+		return nil
+	}
+
+	// Paragraphs and generic containers
+	return buildBlocks(n)
+}
+
+// listBlock converts a list element.
+func listBlock(n *html.Node) (Block, bool) {
+	list := Block{Type: BlockList, Ordered: n.Data == "ol"}
+	if list.Ordered {
+		list.Start = 1
+		if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+			list.Start = start
+		}
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.Data != "li" {
+			continue
+		}
+		if item := buildBlocks(child); item != nil {
+			list.Items = append(list.Items, item)
+		}
+	}
+
+	return list, list.Items != nil
+}
+
+// tableCells converts the rows of a table.
+func tableCells(n *html.Node) [][]TableCell {
+	var rows [][]TableCell
+	for _, row := range TableRows(n) {
+		cells := make([]TableCell, len(row))
+		for i, cell := range row {
+			cells[i] = TableCell{
+				Header:  cell.Data == "th",
+				Inlines: collectInlines(cell),
+			}
+		}
+		rows = append(rows, cells)
+	}
+	return rows
+}
+
+// figureBlocks converts a figure, attaching its caption to the last image.
+func figureBlocks(n *html.Node) []Block {
+	var caption []Inline
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if isCaption(child) {
+			caption = collectInlines(child)
+		}
+	}
+
+	blocks := buildChildren(n, isCaption)
+	if caption == nil {
+		return blocks
+	}
+
+	for i := len(blocks) - 1; i >= 0; i-- {
+		if blocks[i].Type == BlockImage {
+			blocks[i].Caption = caption
+			return blocks
+		}
+	}
+
+	// Figures without an image keep the caption as a paragraph
+	return append(blocks, Block{Type: BlockParagraph, Inlines: caption})
+}
+
+// isCaption reports whether a node is a figcaption element.
+func isCaption(n *html.Node) bool {
+	return n.Type == html.ElementNode && n.Data == "figcaption"
+}
+
+// imageBlock converts an img element.
+func imageBlock(n *html.Node) (Block, bool) {
+	src := attr(n, "src")
+	if src == "" {
+		return Block{}, false
+	}
+	return Block{
+		Type:   BlockImage,
+		URL:    src,
+		Alt:    normalizeSpace(attr(n, "alt")),
+		Width:  intAttr(n, "width"),
+		Height: intAttr(n, "height"),
+	}, true
+}
+
+// embedBlock converts an iframe, video or other embedded element.
+func embedBlock(n *html.Node) (Block, bool) {
+	src := attr(n, "src")
+	if src == "" {
+		src = attr(n, "data")
+	}
+	if src == "" {
+		// Media elements may list their sources as children
+		for child := n.FirstChild; child != nil && src == ""; child = child.NextSibling {
+			if child.Type == html.ElementNode && child.Data == "source" {
+				src = attr(child, "src")
+			}
+		}
+	}
+	if src == "" {
+		return Block{}, false
+	}
+	return Block{
+		Type:   BlockEmbed,
+		Tag:    n.Data,
+		URL:    src,
+		Width:  intAttr(n, "width"),
+		Height: intAttr(n, "height"),
+	}, true
+}
+
+// collectInlines converts the children of a node into inline runs.
+// Images are dropped.
+func collectInlines(n *html.Node) []Inline {
+	var b inlineBuilder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.add(child, nil, "")
+	}
+	return b.finish()
+}
+
+// inlineBuilder collects inline runs, merging adjacent runs with the same
+// formatting and collapsing whitespace across run boundaries.
+type inlineBuilder struct {
+	runs []Inline
+
+	// onImage is called for images found in inline content; if nil,
+	// images are dropped
+	onImage func(n *html.Node)
+}
+
+// add converts an inline node with the marks and link of its ancestors.
+func (b *inlineBuilder) add(n *html.Node, marks []Mark, href string) {
+	switch n.Type {
+	case html.TextNode:
+		b.text(collapseWhitespace(n.Data), marks, href)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.Data {
+	case "br":
+		b.text("\n", marks, href)
+		return
+
+	case "img":
+		if b.onImage != nil {
+			b.onImage(n)
+		}
+		return
+
+	case "code":
+		b.text(collapseWhitespace(textContent(n)), withMark(marks, MarkCode), href)
+		return
+
+	case "em", "i":
+		marks = withMark(marks, MarkItalic)
+
+	case "strong", "b":
+		marks = withMark(marks, MarkBold)
+
+	case "a":
+		if link := attr(n, "href"); link != "" {
+			marks = withMark(marks, MarkLink)
+			href = link
+		}
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.add(child, marks, href)
+	}
+}
+
+// text appends text with the given formatting.
+func (b *inlineBuilder) text(text string, marks []Mark, href string) {
+	if text == "" {
+		return
+	}
+
+	// Collapse whitespace across run boundaries and after line breaks
+	if last := b.lastText(); text[0] == ' ' && (last == "" || strings.HasSuffix(last, " ") || strings.HasSuffix(last, "\n")) {
+		text = text[1:]
+		if text == "" {
+			return
+		}
+	}
+	if text == "\n" {
+		b.trimTrailingSpace()
+	}
+
+	if n := len(b.runs); n > 0 && sameFormat(b.runs[n-1], marks, href) {
+		b.runs[n-1].Text += text
+		return
+	}
+
+	b.runs = append(b.runs, Inline{Text: text, Marks: marks, Href: href})
+}
+
+// lastText returns the text of the last run, or "".
+func (b *inlineBuilder) lastText() string {
+	if len(b.runs) == 0 {
+		return ""
+	}
+	return b.runs[len(b.runs)-1].Text
+}
+
+// trimTrailingSpace removes a trailing space from the last run.
+func (b *inlineBuilder) trimTrailingSpace() {
+	if n := len(b.runs); n > 0 {
+		b.runs[n-1].Text = strings.TrimRight(b.runs[n-1].Text, " ")
+		if b.runs[n-1].Text == "" {
+			b.runs = b.runs[:n-1]
+		}
+	}
+}
+
+// finish trims the collected runs and returns them, or nil if there is no
+// text. The builder is reset.
+func (b *inlineBuilder) finish() []Inline {
+	runs := b.runs
+	b.runs = nil
+
+	// Trim leading and trailing whitespace and line breaks
+	for len(runs) > 0 {
+		runs[0].Text = strings.TrimLeft(runs[0].Text, " \n")
+		if runs[0].Text != "" {
+			break
+		}
+		runs = runs[1:]
+	}
+	for len(runs) > 0 {
+		last := &runs[len(runs)-1]
+		last.Text = strings.TrimRight(last.Text, " \n")
+		if last.Text != "" {
+			break
+		}
+		runs = runs[:len(runs)-1]
+	}
+
+	if len(runs) == 0 {
+		return nil
+	}
+	return runs
+}
+
+// withMark returns a copy of marks with mark added.
+func withMark(marks []Mark, mark Mark) []Mark {
+	for _, m := range marks {
+		if m == mark {
+			return marks
+		}
+	}
+	result := make([]Mark, len(marks), len(marks)+1)
+	copy(result, marks)
+	return append(result, mark)
+}
+
+// sameFormat reports whether a run has the given marks and link.
+func sameFormat(run Inline, marks []Mark, href string) bool {
+	if run.Href != href || len(run.Marks) != len(marks) {
+		return false
+	}
+	for i := range marks {
+		if run.Marks[i] != marks[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// intAttr returns an attribute parsed as an integer, or 0.
+func intAttr(n *html.Node, key string) int {
+	value, err := strconv.Atoi(strings.TrimSpace(attr(n, key)))
+	if err != nil || value < 0 {
+		return 0
+	}
+	return value
+}
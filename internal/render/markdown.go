@@ -0,0 +1,402 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// hardBreak is a Markdown hard line break.
+const hardBreak = "  \n"
+
+// Markdown renders cleaned content as GitHub-flavored Markdown.
+// The selection's children are rendered; the selected element itself is
+// treated as a container.
+func Markdown(sel *goquery.Selection) string {
+	var blocks []string
+	for _, node := range sel.Nodes {
+		blocks = append(blocks, markdownBlocks(node)...)
+	}
+	return strings.Join(blocks, "\n\n")
+}
+
+// markdownBlocks renders the children of a container node as blocks.
+// Runs of inline content become paragraphs.
+func markdownBlocks(parent *html.Node) []string {
+	var blocks []string
+	var inline strings.Builder
+
+	flush := func() {
+		if text := strings.TrimSpace(inline.String()); text != "" {
+			blocks = append(blocks, escapeLineStart(text))
+		}
+		inline.Reset()
+	}
+
+	for child := parent.FirstChild; child != nil; child = child.NextSibling {
+		if isInline(child) {
+			inline.WriteString(markdownInline(child))
+			continue
+		}
+		if child.Type != html.ElementNode {
+			continue
+		}
+
+		flush()
+		if block := markdownBlock(child); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+	flush()
+
+	return blocks
+}
+
+// markdownBlock renders a block-level element.
+func markdownBlock(n *html.Node) string {
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level := int(n.Data[1] - '0')
+		text := strings.TrimSpace(inlineChildren(n))
+		if text == "" {
+			return ""
+		}
+		return strings.Repeat("#", level) + " " + text
+
+	case "p":
+		return escapeLineStart(strings.TrimSpace(inlineChildren(n)))
+
+	case "ul", "ol":
+		return markdownList(n)
+
+	case "blockquote":
+		content := strings.Join(markdownBlocks(n), "\n\n")
+		if content == "" {
+			return ""
+		}
+		return prefixLines(content, "> ", ">")
+
+	case "pre":
+		return markdownCode(n)
+
+	case "table":
+		return markdownTable(n)
+
+	case "figure":
+		return strings.Join(markdownFigure(n), "\n\n")
+
+	case "figcaption":
+		return emphasize(strings.TrimSpace(inlineChildren(n)), "*")
+
+	case "img":
+		return markdownImage(n)
+
+	case "hr":
+		return "---"
+
+	case "br":
+		return ""
+	}
+
+	// Generic containers
+	return strings.Join(markdownBlocks(n), "\n\n")
+}
+
+// markdownList renders an ordered or unordered list, including nested lists.
+func markdownList(n *html.Node) string {
+	var items []string
+	index := 1
+	if start, err := strconv.Atoi(attr(n, "start")); err == nil {
+		index = start
+	}
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type != html.ElementNode || child.Data != "li" {
+			continue
+		}
+
+		marker := "- "
+		if n.Data == "ol" {
+			marker = strconv.Itoa(index) + ". "
+			index++
+		}
+
+		content := strings.Join(markdownBlocks(child), "\n\n")
+		if content == "" {
+			continue
+		}
+
+		// Indent continuation lines to the marker width
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+prefixLines(content, indent, "")[len(indent):])
+	}
+
+	return strings.Join(items, "\n")
+}
+
+// markdownCode renders a pre element as a fenced code block.
+func markdownCode(n *html.Node) string {
+	code := textContent(n)
+	code = strings.Trim(code, "\n")
+	if strings.TrimSpace(code) == "" {
+		return ""
+	}
+
+	lang := CodeLanguage(n)
+	fence := "```"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+
+	return fence + lang + "\n" + code + "\n" + fence
+}
+
+// markdownTable renders a table as a GFM pipe table. The first row is used
+// as the header.
+func markdownTable(n *html.Node) string {
+	rows := TableRows(n)
+	if len(rows) == 0 {
+		return ""
+	}
+
+	columns := 0
+	for _, row := range rows {
+		if len(row) > columns {
+			columns = len(row)
+		}
+	}
+
+	var lines []string
+	for i, row := range rows {
+		cells := make([]string, columns)
+		for j := range cells {
+			if j < len(row) {
+				cell := strings.TrimSpace(inlineChildren(row[j]))
+				cell = strings.ReplaceAll(cell, hardBreak, " ")
+				cells[j] = cell
+			}
+		}
+		lines = append(lines, "| "+strings.Join(cells, " | ")+" |")
+
+		if i == 0 {
+			separators := make([]string, columns)
+			for j := range separators {
+				separators[j] = "---"
+			}
+			lines = append(lines, "| "+strings.Join(separators, " | ")+" |")
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// markdownFigure renders a figure's image(s) followed by its caption.
+func markdownFigure(n *html.Node) []string {
+	var blocks []string
+	var caption string
+
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == "figcaption" {
+			caption = strings.TrimSpace(inlineChildren(child))
+			continue
+		}
+		if child.Type == html.TextNode && strings.TrimSpace(child.Data) == "" {
+			continue
+		}
+		if isInline(child) {
+			if text := strings.TrimSpace(markdownInline(child)); text != "" {
+				blocks = append(blocks, text)
+			}
+			continue
+		}
+		if block := markdownBlock(child); block != "" {
+			blocks = append(blocks, block)
+		}
+	}
+
+	if caption != "" {
+		blocks = append(blocks, emphasize(caption, "*"))
+	}
+
+	return blocks
+}
+
+// markdownImage renders an image.
+func markdownImage(n *html.Node) string {
+	src := attr(n, "src")
+	if src == "" {
+		return ""
+	}
+	alt := escapeText(normalizeSpace(attr(n, "alt")))
+	result := "![" + alt + "](" + escapeURL(src)
+	if title := attr(n, "title"); title != "" {
+		result += ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+	}
+	return result + ")"
+}
+
+// inlineChildren renders the children of a node as inline Markdown.
+func inlineChildren(n *html.Node) string {
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(markdownInline(child))
+	}
+	return collapseSpaces(b.String())
+}
+
+// markdownInline renders a node as inline Markdown.
+func markdownInline(n *html.Node) string {
+	switch n.Type {
+	case html.TextNode:
+		return escapeText(collapseWhitespace(n.Data))
+	case html.ElementNode:
+	default:
+		return ""
+	}
+
+	switch n.Data {
+	case "a":
+		text := strings.TrimSpace(inlineChildren(n))
+		href := attr(n, "href")
+		if href == "" || text == "" {
+			return text
+		}
+		link := "[" + text + "](" + escapeURL(href)
+		if title := attr(n, "title"); title != "" {
+			link += ` "` + strings.ReplaceAll(title, `"`, `\"`) + `"`
+		}
+		return link + ")"
+
+	case "em", "i":
+		return wrapInline(inlineChildren(n), "*")
+
+	case "strong", "b":
+		return wrapInline(inlineChildren(n), "**")
+
+	case "code":
+		return inlineCode(textContent(n))
+
+	case "br":
+		return hardBreak
+
+	case "img":
+		return markdownImage(n)
+	}
+
+	// Unknown inline or stray block elements inside inline content
+	return inlineChildren(n)
+}
+
+// wrapInline wraps text in emphasis markers, keeping surrounding
+// whitespace outside the markers.
+func wrapInline(text, marker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+	leading := text[:strings.Index(text, trimmed)]
+	trailing := text[len(leading)+len(trimmed):]
+	return leading + marker + trimmed + marker + trailing
+}
+
+// emphasize wraps a block of text in emphasis markers.
+func emphasize(text, marker string) string {
+	if text == "" {
+		return ""
+	}
+	return marker + text + marker
+}
+
+// inlineCode renders text as an inline code span.
+func inlineCode(code string) string {
+	code = collapseWhitespace(code)
+	if strings.TrimSpace(code) == "" {
+		return ""
+	}
+	fence := "`"
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	if strings.HasPrefix(code, "`") || strings.HasSuffix(code, "`") {
+		code = " " + code + " "
+	}
+	return fence + code + fence
+}
+
+// markdownEscaper escapes characters with inline meaning in Markdown.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"`", "\\`",
+	`*`, `\*`,
+	`_`, `\_`,
+	`[`, `\[`,
+	`]`, `\]`,
+	`<`, `\<`,
+	`>`, `\>`,
+	`|`, `\|`,
+)
+
+// escapeText escapes Markdown syntax in text.
+func escapeText(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// escapeLineStart escapes characters that would start a block construct at
+// the beginning of a paragraph, such as headings or list markers.
+func escapeLineStart(text string) string {
+	switch {
+	case strings.HasPrefix(text, "#"),
+		strings.HasPrefix(text, "- "),
+		strings.HasPrefix(text, "+ "),
+		strings.HasPrefix(text, "= "):
+		return `\` + text
+	}
+
+	// Ordered list markers such as "1. "
+	i := 0
+	for i < len(text) && text[i] >= '0' && text[i] <= '9' {
+		i++
+	}
+	if i > 0 && i+1 < len(text) && (text[i] == '.' || text[i] == ')') && text[i+1] == ' ' {
+		return text[:i] + `\` + text[i:]
+	}
+
+	return text
+}
+
+// escapeURL escapes characters that would end a Markdown link destination.
+func escapeURL(url string) string {
+	return strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29").Replace(url)
+}
+
+// prefixLines prefixes every line of text, using blankPrefix for empty lines.
+func prefixLines(text, prefix, blankPrefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = blankPrefix
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// collapseSpaces collapses runs of whitespace in rendered inline Markdown
+// while keeping hard line breaks intact.
+func collapseSpaces(text string) string {
+	parts := strings.Split(text, hardBreak)
+	for i, part := range parts {
+		part = collapseWhitespace(part)
+		if i > 0 {
+			part = strings.TrimLeft(part, " ")
+		}
+		if i < len(parts)-1 {
+			part = strings.TrimRight(part, " ")
+		}
+		parts[i] = part
+	}
+	return strings.Join(parts, hardBreak)
+}
@@ -0,0 +1,132 @@
+// Package render converts cleaned article content into other formats.
+package render
+
+import (
+	"strings"
+
+	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"golang.org/x/net/html"
+)
+
+// languagePrefixes are the class prefixes that carry a code language hint.
+var languagePrefixes = []string{"language-", "lang-"}
+
+// LanguageClass returns the language named by a class attribute value,
+// e.g. "go" for "language-go", or "" if there is none.
+func LanguageClass(class string) string {
+	for _, name := range strings.Fields(class) {
+		for _, prefix := range languagePrefixes {
+			if strings.HasPrefix(name, prefix) && len(name) > len(prefix) {
+				return name[len(prefix):]
+			}
+		}
+	}
+	return ""
+}
+
+// CodeLanguage returns the language hint of a pre element, taken from its
+// own class or that of a code element inside it.
+func CodeLanguage(n *html.Node) string {
+	if lang := LanguageClass(attr(n, "class")); lang != "" {
+		return lang
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode && child.Data == "code" {
+			if lang := LanguageClass(attr(child, "class")); lang != "" {
+				return lang
+			}
+		}
+	}
+	return ""
+}
+
+// TableRows returns the cells of each row of a table, in document order.
+// Rows in thead, tbody and tfoot are included; nested tables are not.
+func TableRows(table *html.Node) [][]*html.Node {
+	var rows [][]*html.Node
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			switch child.Data {
+			case "thead", "tbody", "tfoot":
+				walk(child)
+			case "tr":
+				var cells []*html.Node
+				for cell := child.FirstChild; cell != nil; cell = cell.NextSibling {
+					if cell.Type == html.ElementNode && (cell.Data == "td" || cell.Data == "th") {
+						cells = append(cells, cell)
+					}
+				}
+				if len(cells) > 0 {
+					rows = append(rows, cells)
+				}
+			}
+		}
+	}
+	walk(table)
+
+	return rows
+}
+
+// isInline reports whether a node is rendered as part of a paragraph.
+func isInline(n *html.Node) bool {
+	if n.Type == html.TextNode {
+		return true
+	}
+	return n.Type == html.ElementNode && dom.IsInlineElement(n.Data)
+}
+
+// attr returns the value of an attribute, or "".
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// textContent returns the raw text of a node and its descendants.
+func textContent(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return n.Data
+	}
+	var b strings.Builder
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		b.WriteString(textContent(child))
+	}
+	return b.String()
+}
+
+// collapseWhitespace replaces each run of whitespace with a single space,
+// keeping a leading or trailing space if there was one.
+func collapseWhitespace(text string) string {
+	if text == "" {
+		return ""
+	}
+	collapsed := strings.Join(strings.Fields(text), " ")
+	if collapsed == "" {
+		return " "
+	}
+	if isSpace(text[0]) {
+		collapsed = " " + collapsed
+	}
+	if isSpace(text[len(text)-1]) {
+		collapsed += " "
+	}
+	return collapsed
+}
+
+// normalizeSpace collapses and trims whitespace.
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// isSpace reports whether b is an ASCII whitespace character.
+func isSpace(b byte) bool {
+	return b == ' ' || b == '\t' || b == '\n' || b == '\r' || b == '\f'
+}
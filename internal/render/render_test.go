@@ -0,0 +1,223 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// content parses an HTML fragment and returns its wrapping div.
+func content(t *testing.T, fragment string) *goquery.Selection {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader("<div id=\"content\">" + fragment + "</div>"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return doc.Find("#content")
+}
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{
+			name:     "headings and paragraphs",
+			html:     `<h2>Title</h2><p>First  <em>emphasized</em> and <strong>bold</strong>.</p><p>Second</p>`,
+			expected: "## Title\n\nFirst *emphasized* and **bold**.\n\nSecond",
+		},
+		{
+			name:     "links and images",
+			html:     `<p>See <a href="https://example.com/a b" title="Ex">the site</a> <img src="https://example.com/i.png" alt="Pic"></p>`,
+			expected: "See [the site](https://example.com/a%20b \"Ex\") ![Pic](https://example.com/i.png)",
+		},
+		{
+			name:     "nested lists",
+			html:     `<ul><li>One<ul><li>Inner</li></ul></li><li>Two</li></ul><ol start="3"><li>Three</li><li>Four</li></ol>`,
+			expected: "- One\n\n  - Inner\n- Two\n\n3. Three\n4. Four",
+		},
+		{
+			name:     "blockquote",
+			html:     `<blockquote><p>Quoted</p><p>Again</p></blockquote>`,
+			expected: "> Quoted\n>\n> Again",
+		},
+		{
+			name:     "code with language",
+			html:     "<pre><code class=\"language-go\">func main() {\n\tprintln(\"*\")\n}</code></pre><p>Use <code>go run</code></p>",
+			expected: "```go\nfunc main() {\n\tprintln(\"*\")\n}\n```\n\nUse `go run`",
+		},
+		{
+			name:     "table",
+			html:     `<table><thead><tr><th>Name</th><th>Value</th></tr></thead><tbody><tr><td>a|b</td><td>1</td></tr><tr><td>c</td></tr></tbody></table>`,
+			expected: "| Name | Value |\n| --- | --- |\n| a\\|b | 1 |\n| c |  |",
+		},
+		{
+			name:     "figure with caption",
+			html:     `<figure><img src="https://example.com/p.jpg" alt="Photo"><figcaption>A caption</figcaption></figure>`,
+			expected: "![Photo](https://example.com/p.jpg)\n\n*A caption*",
+		},
+		{
+			name:     "line breaks",
+			html:     `<p>Line one<br>Line two</p>`,
+			expected: "Line one  \nLine two",
+		},
+		{
+			name:     "escaping",
+			html:     `<p>1. Not a list with *stars* and [brackets]</p><p># Not a heading</p>`,
+			expected: "1\\. Not a list with \\*stars\\* and \\[brackets\\]\n\n\\# Not a heading",
+		},
+		{
+			name:     "loose inline content",
+			html:     `Intro text <b>bold</b><p>Paragraph</p>`,
+			expected: "Intro text **bold**\n\nParagraph",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Markdown(content(t, tt.html))
+			if result != tt.expected {
+				t.Errorf("Markdown =\n%q\nwant\n%q", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLanguageClass(t *testing.T) {
+	tests := map[string]string{
+		"language-go":      "go",
+		"hljs lang-python": "python",
+		"highlight":        "",
+		"language-":        "",
+		"":                 "",
+	}
+
+	for class, expected := range tests {
+		if result := LanguageClass(class); result != expected {
+			t.Errorf("LanguageClass(%q) = %q, want %q", class, result, expected)
+		}
+	}
+}
+
+func TestBlocks(t *testing.T) {
+	html := `
+<h2>Title <em>here</em></h2>
+<p>Read <a href="https://example.com">the <strong>full</strong> story</a> and run <code>go test</code>.<br> Next line</p>
+<ol start="2"><li>First<ul><li>Nested</li></ul></li><li>Second</li></ol>
+<blockquote><p>Quoted text</p></blockquote>
+<pre><code class="language-go">fmt.Println("hi")</code></pre>
+<figure><img src="https://example.com/a.jpg" alt="A" width="640" height="480"><figcaption>The <b>caption</b></figcaption></figure>
+<table><tr><th>H</th></tr><tr><td>C</td></tr></table>
+<iframe src="https://www.youtube.com/embed/xyz" width="560"></iframe>`
+
+	blocks := Blocks(content(t, html))
+
+	var types []string
+	for _, block := range blocks {
+		types = append(types, string(block.Type))
+	}
+	expected := "heading,paragraph,list,quote,code,image,table,embed"
+	if strings.Join(types, ",") != expected {
+		t.Fatalf("Block types = %v, want %s", types, expected)
+	}
+
+	heading := blocks[0]
+	if heading.Level != 2 || len(heading.Inlines) != 2 || heading.Inlines[1].Text != "here" || heading.Inlines[1].Marks[0] != MarkItalic {
+		t.Errorf("Unexpected heading: %+v", heading)
+	}
+
+	paragraph := blocks[1]
+	var text strings.Builder
+	for _, inline := range paragraph.Inlines {
+		text.WriteString(inline.Text)
+	}
+	if text.String() != "Read the full story and run go test.\nNext line" {
+		t.Errorf("Paragraph text = %q", text.String())
+	}
+	bold := paragraph.Inlines[2]
+	if bold.Text != "full" || bold.Href != "https://example.com" || len(bold.Marks) != 2 {
+		t.Errorf("Expected bold link run, got %+v", bold)
+	}
+
+	list := blocks[2]
+	if !list.Ordered || list.Start != 2 || len(list.Items) != 2 || list.Items[0][1].Type != BlockList {
+		t.Errorf("Unexpected list: %+v", list)
+	}
+
+	if blocks[3].Children[0].Inlines[0].Text != "Quoted text" {
+		t.Errorf("Unexpected quote: %+v", blocks[3])
+	}
+	if blocks[4].Language != "go" || blocks[4].Text != `fmt.Println("hi")` {
+		t.Errorf("Unexpected code block: %+v", blocks[4])
+	}
+
+	image := blocks[5]
+	if image.URL != "https://example.com/a.jpg" || image.Width != 640 || len(image.Caption) != 2 {
+		t.Errorf("Unexpected image: %+v", image)
+	}
+
+	table := blocks[6]
+	if len(table.Rows) != 2 || !table.Rows[0][0].Header || table.Rows[1][0].Inlines[0].Text != "C" {
+		t.Errorf("Unexpected table: %+v", table)
+	}
+
+	if blocks[7].Tag != "iframe" || blocks[7].URL != "https://www.youtube.com/embed/xyz" {
+		t.Errorf("Unexpected embed: %+v", blocks[7])
+	}
+}
+
+func TestBlocks_InlineImage(t *testing.T) {
+	blocks := Blocks(content(t, `<p>Before <img src="https://example.com/i.png"> after</p>`))
+
+	if len(blocks) != 3 || blocks[1].Type != BlockImage {
+		t.Fatalf("Expected the image to split the paragraph, got %+v", blocks)
+	}
+	if blocks[0].Inlines[0].Text != "Before" || blocks[2].Inlines[0].Text != "after" {
+		t.Errorf("Unexpected paragraph text: %+v", blocks)
+	}
+}
+
+func TestText(t *testing.T) {
+	html := `
+<h1>Title</h1>
+<p>Intro with a <a href="https://example.com/a">link</a> and <a href="https://example.com/b">another</a>.</p>
+<h2>Steps</h2>
+<ol><li>First<ul><li>Detail</li></ul></li><li>Second</li></ol>
+<blockquote><p>Quoted</p></blockquote>
+<table><tr><th>Name</th><th>Count</th></tr><tr><td>Apples</td><td>3</td></tr><tr><td>Kiwi</td><td>12</td></tr></table>
+<p>Again the <a href="https://example.com/a">first link</a>.</p>`
+
+	expected := "Title\n=====\n\n" +
+		"Intro with a link and another.\n\n" +
+		"Steps\n-----\n\n" +
+		"1. First\n\n   - Detail\n2. Second\n\n" +
+		"> Quoted\n\n" +
+		"Name    Count\n------  -----\nApples  3\nKiwi    12\n\n" +
+		"Again the first link."
+
+	if result := Text(content(t, html), TextOptions{}); result != expected {
+		t.Errorf("Text =\n%s\nwant\n%s", result, expected)
+	}
+
+	withNotes := Text(content(t, html), TextOptions{LinkFootnotes: true})
+	for _, want := range []string{
+		"Intro with a link [1] and another [2].",
+		"Again the first link [1].",
+		"\n\n[1] https://example.com/a\n[2] https://example.com/b",
+	} {
+		if !strings.Contains(withNotes, want) {
+			t.Errorf("Text with footnotes missing %q:\n%s", want, withNotes)
+		}
+	}
+}
+
+func TestDisplayWidth(t *testing.T) {
+	if width := displayWidth("abc"); width != 3 {
+		t.Errorf("displayWidth(abc) = %d, want 3", width)
+	}
+	if width := displayWidth("中文"); width != 4 {
+		t.Errorf("displayWidth(中文) = %d, want 4", width)
+	}
+}
@@ -0,0 +1,232 @@
+package render
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// TextOptions controls plain text rendering.
+type TextOptions struct {
+	// LinkFootnotes marks links with numbered references and lists their
+	// URLs at the end of the text
+	LinkFootnotes bool
+}
+
+// Text renders cleaned content as structured plain text: blocks are
+// separated by blank lines, list items are marked with "- " or "1. ",
+// headings are underlined and tables are laid out in aligned columns.
+func Text(sel *goquery.Selection, opts TextOptions) string {
+	r := &textRenderer{opts: opts, footnotes: make(map[string]int)}
+
+	text := r.blocks(Blocks(sel))
+	if len(r.links) > 0 {
+		var notes []string
+		for i, link := range r.links {
+			notes = append(notes, "["+strconv.Itoa(i+1)+"] "+link)
+		}
+		text += "\n\n" + strings.Join(notes, "\n")
+	}
+
+	return text
+}
+
+// textRenderer renders blocks as plain text, collecting link footnotes.
+type textRenderer struct {
+	opts      TextOptions
+	links     []string
+	footnotes map[string]int
+}
+
+// blocks renders a list of blocks separated by blank lines.
+func (r *textRenderer) blocks(blocks []Block) string {
+	var parts []string
+	for _, block := range blocks {
+		if text := r.block(block); text != "" {
+			parts = append(parts, text)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// block renders a single block.
+func (r *textRenderer) block(block Block) string {
+	switch block.Type {
+	case BlockParagraph:
+		return r.inlines(block.Inlines)
+
+	case BlockHeading:
+		text := r.inlines(block.Inlines)
+		underline := "-"
+		if block.Level == 1 {
+			underline = "="
+		}
+		return text + "\n" + strings.Repeat(underline, maxLineWidth(text))
+
+	case BlockList:
+		return r.list(block)
+
+	case BlockQuote:
+		return prefixLines(r.blocks(block.Children), "> ", ">")
+
+	case BlockCode:
+		return block.Text
+
+	case BlockImage:
+		// Images are represented by their caption only
+		return r.inlines(block.Caption)
+
+	case BlockTable:
+		return r.table(block)
+	}
+
+	return ""
+}
+
+// list renders a list, indenting nested content under each marker.
+func (r *textRenderer) list(block Block) string {
+	var items []string
+	for i, item := range block.Items {
+		marker := "- "
+		if block.Ordered {
+			marker = strconv.Itoa(block.Start+i) + ". "
+		}
+
+		content := r.blocks(item)
+		if content == "" {
+			continue
+		}
+
+		indent := strings.Repeat(" ", len(marker))
+		items = append(items, marker+prefixLines(content, indent, "")[len(indent):])
+	}
+	return strings.Join(items, "\n")
+}
+
+// table renders a table as aligned columns. A header row is followed by a
+// line of dashes.
+func (r *textRenderer) table(block Block) string {
+	var rows [][]string
+	var widths []int
+
+	for _, row := range block.Rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = strings.ReplaceAll(r.inlines(cell.Inlines), "\n", " ")
+			if i >= len(widths) {
+				widths = append(widths, 0)
+			}
+			if width := displayWidth(cells[i]); width > widths[i] {
+				widths[i] = width
+			}
+		}
+		rows = append(rows, cells)
+	}
+
+	var lines []string
+	for i, cells := range rows {
+		lines = append(lines, alignRow(cells, widths))
+
+		if i == 0 && isHeaderRow(block.Rows[0]) && len(rows) > 1 {
+			dashes := make([]string, len(widths))
+			for j, width := range widths {
+				dashes[j] = strings.Repeat("-", width)
+			}
+			lines = append(lines, alignRow(dashes, widths))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// inlines renders inline runs, adding footnote references to links if
+// enabled.
+func (r *textRenderer) inlines(inlines []Inline) string {
+	var b strings.Builder
+	for i, inline := range inlines {
+		b.WriteString(inline.Text)
+
+		// Reference the link after its last run
+		if !r.opts.LinkFootnotes || inline.Href == "" {
+			continue
+		}
+		if i+1 < len(inlines) && inlines[i+1].Href == inline.Href {
+			continue
+		}
+		b.WriteString(" [" + strconv.Itoa(r.footnote(inline.Href)) + "]")
+	}
+	return b.String()
+}
+
+// footnote returns the number of a link's footnote, adding it if needed.
+func (r *textRenderer) footnote(href string) int {
+	if n, ok := r.footnotes[href]; ok {
+		return n
+	}
+	r.links = append(r.links, href)
+	r.footnotes[href] = len(r.links)
+	return len(r.links)
+}
+
+// isHeaderRow reports whether every cell of a row is a header cell.
+func isHeaderRow(row []TableCell) bool {
+	for _, cell := range row {
+		if !cell.Header {
+			return false
+		}
+	}
+	return len(row) > 0
+}
+
+// alignRow pads cells to the column widths, separated by two spaces.
+func alignRow(cells []string, widths []int) string {
+	var b strings.Builder
+	for i, width := range widths {
+		var cell string
+		if i < len(cells) {
+			cell = cells[i]
+		}
+		if i > 0 {
+			b.WriteString("  ")
+		}
+		b.WriteString(cell)
+		if i < len(widths)-1 {
+			b.WriteString(strings.Repeat(" ", width-displayWidth(cell)))
+		}
+	}
+	return strings.TrimRight(b.String(), " ")
+}
+
+// maxLineWidth returns the display width of the longest line of text.
+func maxLineWidth(text string) int {
+	width := 0
+	for _, line := range strings.Split(text, "\n") {
+		if w := displayWidth(line); w > width {
+			width = w
+		}
+	}
+	return width
+}
+
+// displayWidth returns the number of terminal columns text occupies,
+// counting wide East Asian characters as two.
+func displayWidth(text string) int {
+	width := 0
+	for _, r := range text {
+		if isWide(r) {
+			width += 2
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// isWide reports whether a rune is displayed double width.
+func isWide(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hangul, unicode.Hiragana, unicode.Katakana) ||
+		(r >= 0x3000 && r <= 0x303F) || // CJK punctuation
+		(r >= 0xFF01 && r <= 0xFF60) // Full-width forms
+}
@@ -23,6 +23,10 @@ const (
 	// LengthChunkSize is the character count per length chunk.
 	LengthChunkSize = 50
 
+	// CJKLengthChunkSize is the character count per length chunk for
+	// Chinese and Japanese text, whose characters carry more content.
+	CJKLengthChunkSize = 20
+
 	// MaxLengthBonus is the maximum score bonus from length.
 	MaxLengthBonus = 3
 
@@ -22,12 +22,8 @@ func ScoreParagraph(sel *goquery.Selection, minLength int) float64 {
 	commas := dom.CountCommas(text)
 	score += float64(commas) * CommaBonus
 
-	// Bonus for length (1 point per 50 chars, max 3)
-	lengthBonus := textLen / LengthChunkSize
-	if lengthBonus > MaxLengthBonus {
-		lengthBonus = MaxLengthBonus
-	}
-	score += float64(lengthBonus)
+	// Bonus for length (1 point per chunk, max 3)
+	score += float64(lengthBonus(text, textLen))
 
 	return score
 }
@@ -58,16 +54,21 @@ func ScoreParagraphWithSelection(sel *goquery.Selection, minLength int) *Paragra
 	ps.Score += float64(commas) * CommaBonus
 
 	// Bonus for length
-	lengthBonus := textLen / LengthChunkSize
-	if lengthBonus > MaxLengthBonus {
-		lengthBonus = MaxLengthBonus
-	}
-	ps.LengthBonus = lengthBonus
-	ps.Score += float64(lengthBonus)
+	ps.LengthBonus = lengthBonus(text, textLen)
+	ps.Score += float64(ps.LengthBonus)
 
 	return ps
 }
 
+// lengthBonus returns one point per length chunk of text, up to
+// MaxLengthBonus. The chunk size shrinks from LengthChunkSize to
+// CJKLengthChunkSize with the share of CJK characters.
+func lengthBonus(text string, textLen int) int {
+	ratio := dom.CJKRatio(text)
+	chunk := LengthChunkSize - int(ratio*float64(LengthChunkSize-CJKLengthChunkSize)+0.5)
+	return min(textLen/chunk, MaxLengthBonus)
+}
+
 // ParagraphScore holds detailed paragraph scoring information.
 type ParagraphScore struct {
 	Selection   *goquery.Selection
@@ -1,6 +1,8 @@
 package scorer
 
 import (
+	"context"
+
 	"github.com/LeadNewswire/article-extractor/internal/dom"
 	"github.com/LeadNewswire/article-extractor/internal/keywords"
 	"github.com/PuerkitoBio/goquery"
@@ -8,11 +10,21 @@ import (
 
 // PropagateScores propagates paragraph scores to parent and grandparent elements.
 func PropagateScores(doc *goquery.Document, scoreMap *ScoreMap, minParagraphLength int) {
+	propagateScores(context.Background(), doc, scoreMap, minParagraphLength, nil)
+}
+
+// propagateScores propagates paragraph scores, weighting newly scored
+// elements with the keyword set. It stops early once ctx is done.
+func propagateScores(ctx context.Context, doc *goquery.Document, scoreMap *ScoreMap, minParagraphLength int, kw *keywords.Set) {
 	// Score all paragraphs and propagate
-	doc.Find("p, pre").Each(func(_ int, sel *goquery.Selection) {
+	doc.Find("p, pre").EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+
 		paragraphScore := ScoreParagraph(sel, minParagraphLength)
 		if paragraphScore == 0 {
-			return
+			return true
 		}
 
 		// Get parent and grandparent
@@ -23,7 +35,7 @@ func PropagateScores(doc *goquery.Document, scoreMap *ScoreMap, minParagraphLeng
 		if parent != nil && parent.Length() > 0 {
 			parentScore := scoreMap.Get(parent)
 			if parentScore.ContentScore == 0 {
-				initializeNodeScore(parentScore, parent)
+				initializeNodeScore(parentScore, parent, kw)
 			}
 			// Add full score to parent
 			parentScore.AddScore(paragraphScore * ParentScoreProportion)
@@ -33,16 +45,17 @@ func PropagateScores(doc *goquery.Document, scoreMap *ScoreMap, minParagraphLeng
 		if grandparent != nil && grandparent.Length() > 0 {
 			grandparentScore := scoreMap.Get(grandparent)
 			if grandparentScore.ContentScore == 0 {
-				initializeNodeScore(grandparentScore, grandparent)
+				initializeNodeScore(grandparentScore, grandparent, kw)
 			}
 			// Add half score to grandparent
 			grandparentScore.AddScore(paragraphScore * GrandparentScoreProportion)
 		}
+		return true
 	})
 }
 
 // initializeNodeScore initializes a node's score based on its properties.
-func initializeNodeScore(ns *NodeScore, sel *goquery.Selection) {
+func initializeNodeScore(ns *NodeScore, sel *goquery.Selection, kw *keywords.Set) {
 	// Get tag name
 	tag := dom.GetTagName(sel)
 
@@ -52,7 +65,7 @@ func initializeNodeScore(ns *NodeScore, sel *goquery.Selection) {
 	// Add weight from class/id
 	class := dom.GetAttribute(sel, "class")
 	id := dom.GetAttribute(sel, "id")
-	weight := keywords.GetWeight(class, id)
+	weight := kw.Weight(class, id)
 	ns.SetWeight(weight)
 
 	// Check for hNews microformat
@@ -95,11 +108,28 @@ func hasHNews(sel *goquery.Selection, class string) bool {
 
 // ScoreAndPropagate scores all content and returns the score map.
 func ScoreAndPropagate(doc *goquery.Document, minParagraphLength int) *ScoreMap {
-	scoreMap := NewScoreMap()
-	PropagateScores(doc, scoreMap, minParagraphLength)
+	return ScoreAndPropagateWithKeywords(doc, minParagraphLength, nil)
+}
+
+// ScoreAndPropagateWithKeywords scores all content, weighting candidates
+// by their class and id with the keyword set (nil uses the defaults).
+func ScoreAndPropagateWithKeywords(doc *goquery.Document, minParagraphLength int, kw *keywords.Set) *ScoreMap {
+	scoreMap, _ := ScoreAndPropagateContext(context.Background(), doc, minParagraphLength, kw)
 	return scoreMap
 }
 
+// ScoreAndPropagateContext scores all content like
+// ScoreAndPropagateWithKeywords, returning the context's error if ctx is
+// done before scoring finishes.
+func ScoreAndPropagateContext(ctx context.Context, doc *goquery.Document, minParagraphLength int, kw *keywords.Set) (*ScoreMap, error) {
+	scoreMap := NewScoreMap()
+	propagateScores(ctx, doc, scoreMap, minParagraphLength, kw)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return scoreMap, nil
+}
+
 // RefineScores adjusts scores based on link density and other factors.
 func RefineScores(scoreMap *ScoreMap) {
 	for _, ns := range scoreMap.scores {
@@ -1,6 +1,8 @@
 package scorer
 
 import (
+	"context"
+
 	"github.com/LeadNewswire/article-extractor/internal/dom"
 	"github.com/LeadNewswire/article-extractor/internal/keywords"
 	"github.com/PuerkitoBio/goquery"
@@ -11,6 +13,7 @@ type Scorer struct {
 	minParagraphLength int
 	minContentLength   int
 	debug              bool
+	keywords           *keywords.Set
 }
 
 // NewScorer creates a new Scorer.
@@ -22,10 +25,26 @@ func NewScorer(minParagraphLength, minContentLength int, debug bool) *Scorer {
 	}
 }
 
+// SetKeywords sets the class and id keywords used to weight candidates
+// (nil uses the defaults).
+func (s *Scorer) SetKeywords(set *keywords.Set) {
+	s.keywords = set
+}
+
 // Score scores a document and returns the top candidate.
 func (s *Scorer) Score(doc *goquery.Document) (*NodeScore, *ScoreMap) {
+	topCandidate, scoreMap, _ := s.ScoreContext(context.Background(), doc)
+	return topCandidate, scoreMap
+}
+
+// ScoreContext scores a document like Score, returning the context's error
+// if ctx is done before scoring finishes.
+func (s *Scorer) ScoreContext(ctx context.Context, doc *goquery.Document) (*NodeScore, *ScoreMap, error) {
 	// Build score map
-	scoreMap := ScoreAndPropagate(doc, s.minParagraphLength)
+	scoreMap, err := ScoreAndPropagateContext(ctx, doc, s.minParagraphLength, s.keywords)
+	if err != nil {
+		return nil, nil, err
+	}
 
 	// Refine scores
 	RefineScores(scoreMap)
@@ -33,7 +52,7 @@ func (s *Scorer) Score(doc *goquery.Document) (*NodeScore, *ScoreMap) {
 	// Get top candidate
 	topCandidate := scoreMap.GetTopCandidate()
 
-	return topCandidate, scoreMap
+	return topCandidate, scoreMap, nil
 }
 
 // FindTopCandidate finds the best content candidate from a document.
@@ -58,7 +77,7 @@ func (s *Scorer) ScoreSelection(sel *goquery.Selection) *NodeScore {
 	// Add weight from class/id
 	class := dom.GetAttribute(sel, "class")
 	id := dom.GetAttribute(sel, "id")
-	weight := keywords.GetWeight(class, id)
+	weight := s.keywords.Weight(class, id)
 	ns.SetWeight(weight)
 
 	// Calculate link density
@@ -1,9 +1,12 @@
 package scorer
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 
+	"github.com/LeadNewswire/article-extractor/internal/keywords"
 	"github.com/PuerkitoBio/goquery"
 )
 
@@ -43,6 +46,13 @@ func TestScoreParagraph(t *testing.T) {
 			minScore:  3, // Base + length bonus
 			maxScore:  20,
 		},
+		{
+			name:      "Chinese paragraph",
+			text:      "市议会周二批准了新的预算，将在未来两年增加公共交通、学校和医疗方面的支出。市长表示，该计划是与居民、企业和社区团体数月谈判的结果。",
+			minLength: 25,
+			minScore:  10, // Base + full-width commas and stops + full length bonus
+			maxScore:  10,
+		},
 	}
 
 	for _, tt := range tests {
@@ -219,3 +229,43 @@ func TestScorer_Score(t *testing.T) {
 		t.Error("TopCandidate should have positive score")
 	}
 }
+
+func TestScoreAndPropagateWithKeywords(t *testing.T) {
+	paragraph := `<p>This paragraph has enough text to be scored, with commas, clauses, and more words to pass the minimum length.</p>`
+	html := `<html><body><div class="alpha">` + paragraph + `</div><div class="beta">` + paragraph + `</div></body></html>`
+
+	for _, class := range []string{"alpha", "beta"} {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		set := keywords.NewSet(nil, []string{class}, keywords.DefaultWhitelistWeight, keywords.DefaultBlacklistWeight)
+		top := ScoreAndPropagateWithKeywords(doc, 25, set).GetTopCandidate()
+		if top == nil {
+			t.Fatal("Expected a top candidate")
+		}
+		if got, _ := top.Selection.Attr("class"); got != class {
+			t.Errorf("Whitelisting %q should make it the top candidate, got %q", class, got)
+		}
+	}
+}
+
+func TestScoreAndPropagateContext(t *testing.T) {
+	html := `<html><body><div><p>This paragraph has enough text to be scored, with commas, clauses, and more words to pass the minimum length.</p></div></body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ScoreAndPropagateContext(ctx, doc, 25, nil); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	scoreMap, err := ScoreAndPropagateContext(context.Background(), doc, 25, nil)
+	if err != nil || scoreMap.GetTopCandidate() == nil {
+		t.Errorf("Expected a top candidate, got %v", err)
+	}
+}
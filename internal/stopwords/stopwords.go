@@ -0,0 +1,55 @@
+// Package stopwords provides the stopword lists of the text analysis
+// packages, keyed by ISO 639-1 language code.
+package stopwords
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed lists/*.txt
+var files embed.FS
+
+// lists maps language codes to their stopwords.
+var lists = load()
+
+// For returns the stopwords of a language as a set of lowercase words, or
+// nil if the language has no list. The set is shared and must not be
+// modified.
+func For(lang string) map[string]bool {
+	return lists[lang]
+}
+
+// Languages returns the codes of the languages with stopword lists, sorted.
+func Languages() []string {
+	codes := make([]string, 0, len(lists))
+	for code := range lists {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// load reads the stopword lists, one word per line.
+func load() map[string]map[string]bool {
+	entries, err := files.ReadDir("lists")
+	if err != nil {
+		panic("stopwords: reading lists: " + err.Error())
+	}
+
+	lists := make(map[string]map[string]bool)
+	for _, entry := range entries {
+		data, err := files.ReadFile(path.Join("lists", entry.Name()))
+		if err != nil {
+			panic("stopwords: reading lists: " + err.Error())
+		}
+		list := make(map[string]bool)
+		for _, word := range strings.Fields(string(data)) {
+			list[word] = true
+		}
+		lists[strings.TrimSuffix(entry.Name(), ".txt")] = list
+	}
+	return lists
+}
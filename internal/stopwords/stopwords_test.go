@@ -0,0 +1,28 @@
+package stopwords
+
+import "testing"
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		lang, word string
+		want       bool
+	}{
+		{"en", "the", true},
+		{"en", "council", false},
+		{"de", "und", true},
+		{"fr", "les", true},
+		{"ru", "и", true},
+	}
+	for _, tt := range tests {
+		if got := For(tt.lang)[tt.word]; got != tt.want {
+			t.Errorf("For(%q)[%q] = %v, want %v", tt.lang, tt.word, got, tt.want)
+		}
+	}
+
+	if For("xx") != nil {
+		t.Error("Expected no list for an unknown language")
+	}
+	if langs := Languages(); len(langs) == 0 || langs[0] != "cs" {
+		t.Errorf("Unexpected languages %v", langs)
+	}
+}
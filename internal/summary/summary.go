@@ -0,0 +1,392 @@
+// Package summary builds extractive summaries by ranking an article's own
+// sentences with TextRank: sentences are nodes of a graph weighted by
+// their word overlap, and the most central sentences form the summary.
+// Stopwords of the text's language are left out of the overlap.
+package summary
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/LeadNewswire/article-extractor/internal/stopwords"
+)
+
+const (
+	// minWords is the number of words a sentence needs to be summarized
+	minWords = 5
+
+	// maxSentences is the number of sentences ranked, from the start of
+	// the text
+	maxSentences = 200
+
+	// damping is the PageRank damping factor
+	damping = 0.85
+
+	// maxIterations bounds the PageRank iterations
+	maxIterations = 100
+
+	// tolerance is the largest change in any rank at convergence
+	tolerance = 1e-6
+)
+
+var (
+	// datelinePatterns match wire datelines such as "WASHINGTON (AP) — ",
+	// "LONDON, March 3 (Reuters) - ", "NEW YORK, March 3, 2024
+	// /PRNewswire/ -- " and "BOSTON--(BUSINESS WIRE)--". Title-case cities
+	// need an agency in brackets or slashes.
+	datelinePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`^\p{Lu}[\p{Lu}\p{M} .,'’-]*\p{Lu}\.?(?:,\s*[^—–()/\n]{1,40}?)?(?:\s*(?:--)?\s*(?:\([^)\n]{1,30}\)|/[^/\n]{1,30}/))?(?:\s*(?:--|—|–)|\s+-)\s*`),
+		regexp.MustCompile(`^\p{Lu}[\p{L}\p{M} .'’-]{1,30}(?:,\s*[^—–()/\n]{1,40}?)?\s*(?:\([^):\n]{1,30}\)|/[^/\n]{1,30}/)(?:\s*(?:--|—|–)|\s+-)\s*`),
+	}
+
+	// footnoteRefPattern matches link footnote references such as " [3]"
+	footnoteRefPattern = regexp.MustCompile(` \[\d+\]`)
+
+	// footnoteLinePattern matches link footnote lines such as "[3] https://..."
+	footnoteLinePattern = regexp.MustCompile(`^\[\d+\]\s+\S+$`)
+
+	// listMarkerPattern matches list item markers such as "- " and "2. "
+	listMarkerPattern = regexp.MustCompile(`^(?:[-*•]|\d+[.)])\s+`)
+
+	// abbreviations end with a period that does not end a sentence
+	abbreviations = map[string]bool{
+		"mr": true, "mrs": true, "ms": true, "dr": true, "prof": true,
+		"sr": true, "jr": true, "st": true, "mt": true, "vs": true,
+		"etc": true, "inc": true, "ltd": true, "co": true, "corp": true,
+		"gen": true, "gov": true, "sen": true, "rep": true, "rev": true,
+		"jan": true, "feb": true, "mar": true, "apr": true, "jun": true,
+		"jul": true, "aug": true, "sep": true, "sept": true, "oct": true,
+		"nov": true, "dec": true, "no": true, "fig": true, "approx": true,
+	}
+)
+
+// StripDateline removes a wire dateline from the start of text.
+func StripDateline(text string) string {
+	for _, pattern := range datelinePatterns {
+		if loc := pattern.FindStringIndex(text); loc != nil && loc[1] < len(text) {
+			return text[loc[1]:]
+		}
+	}
+	return text
+}
+
+// Summarize returns up to n of the highest ranked sentences of text, in
+// their original order and joined by spaces (or nothing for Chinese and
+// Japanese). Blocks are separated by blank lines, as in Article.TextContent.
+// lang is the ISO 639-1 code of the text's language ("" for English).
+func Summarize(text, lang string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+
+	var candidates []string
+	for _, sentence := range Sentences(text) {
+		if dom.CountWords(sentence) >= minWords {
+			candidates = append(candidates, sentence)
+		}
+		if len(candidates) == maxSentences {
+			break
+		}
+	}
+	if len(candidates) == 0 {
+		return ""
+	}
+
+	ranks := Rank(candidates, lang)
+	order := make([]int, len(candidates))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return ranks[order[a]] > ranks[order[b]]
+	})
+
+	chosen := order[:min(n, len(order))]
+	sort.Ints(chosen)
+
+	var b strings.Builder
+	for _, i := range chosen {
+		if b.Len() > 0 && !endsCJK(b.String()) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(candidates[i])
+	}
+	return b.String()
+}
+
+// Sentences splits text into sentences. Each block (separated by blank
+// lines) and each line of a list is split separately; heading underlines
+// and link footnotes are skipped, and a leading dateline is removed.
+func Sentences(text string) []string {
+	text = footnoteRefPattern.ReplaceAllString(text, "")
+
+	var sentences []string
+	first := true
+	for _, block := range strings.Split(text, "\n\n") {
+		var lines []string
+		for _, line := range strings.Split(block, "\n") {
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "", strings.Trim(line, "=-") == "", footnoteLinePattern.MatchString(line):
+				continue
+			case listMarkerPattern.MatchString(line):
+				// List items are sentences of their own
+				sentences = append(sentences, splitSentences(listMarkerPattern.ReplaceAllString(line, ""))...)
+				continue
+			}
+			lines = append(lines, line)
+		}
+
+		paragraph := strings.Join(lines, " ")
+		if paragraph == "" {
+			continue
+		}
+		if first {
+			paragraph = StripDateline(paragraph)
+			first = false
+		}
+		sentences = append(sentences, splitSentences(paragraph)...)
+	}
+	return sentences
+}
+
+// splitSentences splits a paragraph at sentence terminators that are not
+// part of an abbreviation, initial or number.
+func splitSentences(paragraph string) []string {
+	runes := []rune(paragraph)
+	var sentences []string
+	start := 0
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if !isTerminator(r) {
+			continue
+		}
+
+		// Include repeated terminators and closing quotes and brackets
+		end := i + 1
+		for end < len(runes) && (isTerminator(runes[end]) || isCloser(runes[end])) {
+			end++
+		}
+
+		if !isFullWidthTerminator(r) {
+			if end < len(runes) && !unicode.IsSpace(runes[end]) {
+				continue // "3.5", "example.com"
+			}
+			if r == '.' && isAbbreviation(runes[start:i]) {
+				continue
+			}
+			if next := nextLetter(runes[end:]); next != 0 && unicode.IsLower(next) {
+				continue
+			}
+		}
+
+		if sentence := strings.TrimSpace(string(runes[start:end])); sentence != "" {
+			sentences = append(sentences, sentence)
+		}
+		start = end
+		i = end - 1
+	}
+
+	if sentence := strings.TrimSpace(string(runes[start:])); sentence != "" {
+		sentences = append(sentences, sentence)
+	}
+	return sentences
+}
+
+// isTerminator reports whether r ends a sentence.
+func isTerminator(r rune) bool {
+	switch r {
+	case '.', '!', '?', '。', '！', '？':
+		return true
+	}
+	return false
+}
+
+// isFullWidthTerminator reports whether r is a Chinese or Japanese
+// sentence terminator, which needs no following space.
+func isFullWidthTerminator(r rune) bool {
+	return r == '。' || r == '！' || r == '？'
+}
+
+// isCloser reports whether r closes a quotation or bracket.
+func isCloser(r rune) bool {
+	switch r {
+	case '"', '\'', ')', ']', '’', '”', '»', '」', '』', '）':
+		return true
+	}
+	return false
+}
+
+// isAbbreviation reports whether the word before a period is an
+// abbreviation or an initial, such as "Dr", "U.S" or "J".
+func isAbbreviation(before []rune) bool {
+	word := strings.TrimLeftFunc(string(before[lastSpace(before)+1:]), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	if word == "" {
+		return false
+	}
+	if abbreviations[strings.ToLower(word)] {
+		return true
+	}
+
+	// Initials: single letters, optionally separated by periods
+	for _, part := range strings.Split(word, ".") {
+		if len([]rune(part)) != 1 {
+			return false
+		}
+	}
+	return true
+}
+
+// lastSpace returns the index of the last space in runes, or -1.
+func lastSpace(runes []rune) int {
+	for i := len(runes) - 1; i >= 0; i-- {
+		if unicode.IsSpace(runes[i]) {
+			return i
+		}
+	}
+	return -1
+}
+
+// nextLetter returns the first letter in runes, or 0 if there is none
+// before another terminator.
+func nextLetter(runes []rune) rune {
+	for _, r := range runes {
+		if unicode.IsLetter(r) {
+			return r
+		}
+		if isTerminator(r) || unicode.IsDigit(r) {
+			return 0
+		}
+	}
+	return 0
+}
+
+// endsCJK reports whether text ends with a CJK character or punctuation.
+func endsCJK(text string) bool {
+	runes := []rune(text)
+	if len(runes) == 0 {
+		return false
+	}
+	last := runes[len(runes)-1]
+	return dom.IsCJK(last) || isFullWidthTerminator(last)
+}
+
+// Rank returns the TextRank score of each sentence: its PageRank in the
+// graph whose edges are weighted by the sentences' word overlap. The
+// stopwords of lang ("" for English) are not counted; languages without a
+// stopword list count every word.
+func Rank(sentences []string, lang string) []float64 {
+	if lang == "" {
+		lang = "en"
+	}
+	stop := stopwords.For(lang)
+
+	n := len(sentences)
+	tokens := make([]map[string]bool, n)
+	for i, sentence := range sentences {
+		tokens[i] = tokenSet(sentence, stop)
+	}
+
+	// weights[i][j] is the similarity of sentences i and j; sums[i] is the
+	// total weight of i's edges
+	weights := make([][]float64, n)
+	sums := make([]float64, n)
+	for i := range weights {
+		weights[i] = make([]float64, n)
+	}
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			w := similarity(tokens[i], tokens[j])
+			weights[i][j], weights[j][i] = w, w
+			sums[i] += w
+			sums[j] += w
+		}
+	}
+
+	ranks := make([]float64, n)
+	for i := range ranks {
+		ranks[i] = 1
+	}
+	next := make([]float64, n)
+	for iter := 0; iter < maxIterations; iter++ {
+		delta := 0.0
+		for i := 0; i < n; i++ {
+			sum := 0.0
+			for j := 0; j < n; j++ {
+				if weights[j][i] > 0 {
+					sum += weights[j][i] / sums[j] * ranks[j]
+				}
+			}
+			next[i] = (1 - damping) + damping*sum
+			delta = math.Max(delta, math.Abs(next[i]-ranks[i]))
+		}
+		ranks, next = next, ranks
+		if delta < tolerance {
+			break
+		}
+	}
+	return ranks
+}
+
+// similarity is the TextRank sentence similarity: the number of shared
+// tokens, normalized by the logarithms of the sentence lengths.
+func similarity(a, b map[string]bool) float64 {
+	if len(a) < 2 || len(b) < 2 {
+		return 0
+	}
+	shared := 0
+	for token := range a {
+		if b[token] {
+			shared++
+		}
+	}
+	return float64(shared) / (math.Log(float64(len(a))) + math.Log(float64(len(b))))
+}
+
+// tokenSet returns the distinct lowercase words of a sentence, skipping
+// stopwords and words shorter than three letters. Chinese and Japanese
+// text, which has no spaces, is split into character bigrams.
+func tokenSet(sentence string, stop map[string]bool) map[string]bool {
+	tokens := make(map[string]bool)
+	var word, cjk []rune
+
+	flushWord := func() {
+		if len(word) >= 3 && !stop[string(word)] {
+			tokens[string(word)] = true
+		}
+		word = word[:0]
+	}
+	flushCJK := func() {
+		for i := 0; i+1 < len(cjk); i++ {
+			tokens[string(cjk[i:i+2])] = true
+		}
+		if len(cjk) == 1 {
+			tokens[string(cjk)] = true
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range strings.ToLower(sentence) {
+		switch {
+		case dom.IsCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			flushCJK()
+			word = append(word, r)
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return tokens
+}
@@ -0,0 +1,126 @@
+package summary
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/LeadNewswire/article-extractor/internal/stopwords"
+)
+
+func TestStripDateline(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"WASHINGTON (AP) — The Senate passed the bill.", "The Senate passed the bill."},
+		{"LONDON, March 3 (Reuters) - Shares fell.", "Shares fell."},
+		{"NEW YORK, March 3, 2024 /PRNewswire/ -- Acme today announced a merger.", "Acme today announced a merger."},
+		{"SAN FRANCISCO--(BUSINESS WIRE)--Acme today announced a merger.", "Acme today announced a merger."},
+		{"Paris (AFP) - Strikes continued.", "Strikes continued."},
+		{"The company — which makes widgets — grew.", "The company — which makes widgets — grew."},
+		{"US-China talks resumed on Monday.", "US-China talks resumed on Monday."},
+		{"The company (NYSE: ACME) - said revenue rose.", "The company (NYSE: ACME) - said revenue rose."},
+	}
+
+	for _, tt := range tests {
+		if got := StripDateline(tt.input); got != tt.want {
+			t.Errorf("StripDateline(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestSentences(t *testing.T) {
+	text := `WASHINGTON (AP) — Dr. Smith met Sen. Jones at 3.30 p.m. on Friday. They discussed the U.S. budget! "Is it enough?" she asked. It was not.
+
+Budget Talks
+============
+
+- First item of the list
+- Second item. With two sentences.
+
+The story links to a report [1].
+
+[1] https://example.com/report
+
+市议会周二批准了新的预算。市长表示满意！`
+
+	want := []string{
+		"Dr. Smith met Sen. Jones at 3.30 p.m. on Friday.",
+		"They discussed the U.S. budget!",
+		`"Is it enough?" she asked.`,
+		"It was not.",
+		"Budget Talks",
+		"First item of the list",
+		"Second item.",
+		"With two sentences.",
+		"The story links to a report.",
+		"市议会周二批准了新的预算。",
+		"市长表示满意！",
+	}
+
+	if got := Sentences(text); !reflect.DeepEqual(got, want) {
+		t.Errorf("Sentences() =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	text := strings.Join([]string{
+		"WASHINGTON (AP) — The Senate passed a budget bill on Tuesday that funds the government through September.",
+		"The budget bill raises spending on schools and roads while cutting funds for several federal agencies.",
+		"Senators from both parties praised the budget bill as a fair compromise between competing demands.",
+		"The weather in the capital was unusually warm for the season.",
+		"The House is expected to vote on the budget bill later this week, and the president said he would sign it.",
+	}, " ")
+
+	got := Summarize(text, "en", 2)
+	if strings.Contains(got, "weather") || strings.Contains(got, "WASHINGTON") {
+		t.Errorf("Expected central sentences without the dateline, got %q", got)
+	}
+	if len(Sentences(got)) != 2 {
+		t.Errorf("Expected two sentences, got %q", got)
+	}
+
+	// Sentences keep their original order
+	first := strings.Index(text, Sentences(got)[0])
+	second := strings.Index(text, Sentences(got)[1])
+	if first > second {
+		t.Errorf("Expected the sentences in their original order, got %q", got)
+	}
+
+	if got := Summarize(text, "en", 0); got != "" {
+		t.Errorf("Expected no summary for n = 0, got %q", got)
+	}
+	if got := Summarize("Too short.", "en", 3); got != "" {
+		t.Errorf("Expected no summary for short text, got %q", got)
+	}
+
+	cjk := "市议会周二批准了新的预算方案。新的预算方案将增加学校的支出。市长表示预算方案是谈判的结果。今天天气很好。"
+	if got := Summarize(cjk, "zh", 2); strings.Contains(got, " ") || strings.Contains(got, "天气") {
+		t.Errorf("Expected two related Chinese sentences joined without spaces, got %q", got)
+	}
+}
+
+func TestRank_Stopwords(t *testing.T) {
+	// The first two sentences share only function words; the last two
+	// share content words
+	sentences := []string{
+		"The mayor said that the plan would be ready for the council by then.",
+		"They said that the weather would be warm for the rest of the week.",
+		"The council approved the transit plan after a long debate.",
+		"The transit plan approved by the council adds two bus lines.",
+	}
+
+	stop := stopwords.For("en")
+	if got := similarity(tokenSet(sentences[0], stop), tokenSet(sentences[1], stop)); got != 0 {
+		t.Errorf("Expected no similarity from stopwords, got %v", got)
+	}
+	if got := similarity(tokenSet(sentences[0], nil), tokenSet(sentences[1], nil)); got == 0 {
+		t.Error("Expected similarity from shared words without a stopword list")
+	}
+
+	ranks := Rank(sentences, "")
+	if ranks[1] >= ranks[2] || ranks[1] >= ranks[3] {
+		t.Errorf("Expected the sentences sharing content words to rank highest, got %v", ranks)
+	}
+}
@@ -0,0 +1,191 @@
+package trace
+
+import (
+	"strings"
+
+	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+// MaxCandidates is the number of top scoring candidates kept in a trace.
+const MaxCandidates = 10
+
+// Removal reasons recorded during preprocessing.
+const (
+	ReasonHidden      = "hidden"
+	ReasonWidget      = "widget"
+	ReasonUnlikelyTag = "unlikely tag"
+	ReasonBlacklisted = "blacklisted"
+)
+
+// Trace records the decisions made during an extraction.
+// All methods are safe to call on a nil Trace, which records nothing.
+type Trace struct {
+	// Removed lists the elements removed during preprocessing
+	Removed []Removal `json:"removed"`
+
+	// Candidates lists the top scoring content candidates
+	Candidates []Candidate `json:"candidates"`
+
+	// Siblings lists the sibling merge decisions
+	Siblings []Sibling `json:"siblings"`
+
+	// Metadata lists where each metadata field came from
+	Metadata []Field `json:"metadata"`
+}
+
+// Removal describes an element removed during preprocessing.
+type Removal struct {
+	// Element is a short CSS-like description of the element
+	Element string `json:"element"`
+
+	// Reason is why the element was removed
+	Reason string `json:"reason"`
+
+	// Detail is additional information such as the matched keyword
+	Detail string `json:"detail,omitempty"`
+
+	// TextLength is the text length of the removed element
+	TextLength int `json:"textLength"`
+}
+
+// Candidate describes a scored content candidate.
+type Candidate struct {
+	// Element is a short CSS-like description of the element
+	Element string `json:"element"`
+
+	// ContentScore is the content score
+	ContentScore float64 `json:"contentScore"`
+
+	// Weight is the class/id based weight
+	Weight int `json:"weight"`
+
+	// LinkDensity is the link text to total text ratio
+	LinkDensity float64 `json:"linkDensity"`
+
+	// TextLength is the text length of the element
+	TextLength int `json:"textLength"`
+
+	// Selected is true for the chosen candidate
+	Selected bool `json:"selected"`
+}
+
+// Sibling describes a sibling merge decision.
+type Sibling struct {
+	// Element is a short CSS-like description of the element
+	Element string `json:"element"`
+
+	// Accepted is true if the sibling was merged
+	Accepted bool `json:"accepted"`
+
+	// Reason explains the decision
+	Reason string `json:"reason"`
+
+	// Score is the sibling's content score, if it was scored
+	Score float64 `json:"score,omitempty"`
+}
+
+// Field describes where a metadata field came from.
+type Field struct {
+	// Name is the article field name
+	Name string `json:"name"`
+
+	// Source is the extraction source, e.g. "og:title" or "site rule"
+	Source string `json:"source"`
+
+	// Value is the extracted value
+	Value string `json:"value"`
+}
+
+// New creates an empty Trace.
+func New() *Trace {
+	return &Trace{}
+}
+
+// AddRemoval records an element removed during preprocessing. Elements
+// inside an already removed subtree are not recorded.
+func (t *Trace) AddRemoval(sel *goquery.Selection, reason, detail string) {
+	if t == nil || isDetached(sel) {
+		return
+	}
+	removal := Removal{
+		Element:    Describe(sel),
+		Reason:     reason,
+		Detail:     detail,
+		TextLength: dom.GetTextLength(sel),
+	}
+	t.Removed = append(t.Removed, removal)
+}
+
+// AddCandidate records a scored content candidate.
+func (t *Trace) AddCandidate(c Candidate) {
+	if t == nil {
+		return
+	}
+	t.Candidates = append(t.Candidates, c)
+}
+
+// AddSibling records a sibling merge decision.
+func (t *Trace) AddSibling(sel *goquery.Selection, accepted bool, reason string, score float64) {
+	if t == nil {
+		return
+	}
+	sibling := Sibling{
+		Element:  Describe(sel),
+		Accepted: accepted,
+		Reason:   reason,
+		Score:    score,
+	}
+	t.Siblings = append(t.Siblings, sibling)
+}
+
+// SetField records the source of a metadata field, replacing any previous
+// record for the same field. Empty values are not recorded.
+func (t *Trace) SetField(name, source, value string) {
+	if t == nil || value == "" {
+		return
+	}
+	for i := range t.Metadata {
+		if t.Metadata[i].Name == name {
+			t.Metadata[i] = Field{Name: name, Source: source, Value: value}
+			return
+		}
+	}
+	t.Metadata = append(t.Metadata, Field{Name: name, Source: source, Value: value})
+}
+
+// Describe returns a short CSS-like description of an element,
+// e.g. "div#main.content.wide".
+func Describe(sel *goquery.Selection) string {
+	if sel == nil || sel.Length() == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(dom.GetTagName(sel))
+
+	if id := strings.TrimSpace(dom.GetAttribute(sel, "id")); id != "" {
+		b.WriteString("#")
+		b.WriteString(id)
+	}
+
+	for _, class := range strings.Fields(dom.GetAttribute(sel, "class")) {
+		b.WriteString(".")
+		b.WriteString(class)
+	}
+
+	return b.String()
+}
+
+// isDetached reports whether an element is no longer part of a document.
+func isDetached(sel *goquery.Selection) bool {
+	if sel.Length() == 0 {
+		return true
+	}
+	node := sel.Nodes[0]
+	for node.Parent != nil {
+		node = node.Parent
+	}
+	return node.Type != html.DocumentNode
+}
@@ -0,0 +1,68 @@
+package trace
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestDescribe(t *testing.T) {
+	html := `<div id="main" class="content  wide"><p>Text</p></div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := Describe(doc.Find("div")); result != "div#main.content.wide" {
+		t.Errorf("Describe = %q, want %q", result, "div#main.content.wide")
+	}
+
+	if result := Describe(doc.Find("p")); result != "p" {
+		t.Errorf("Describe = %q, want %q", result, "p")
+	}
+}
+
+func TestNilTrace(t *testing.T) {
+	var tr *Trace
+
+	// Recording on a nil trace must not panic
+	tr.AddRemoval(nil, ReasonHidden, "")
+	tr.AddCandidate(Candidate{})
+	tr.AddSibling(nil, true, "", 0)
+	tr.SetField("title", "og:title", "Title")
+}
+
+func TestAddRemoval_SkipsDetached(t *testing.T) {
+	html := `<div class="outer"><div class="inner">Text</div></div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tr := New()
+	outer := doc.Find(".outer")
+	inner := doc.Find(".inner")
+
+	tr.AddRemoval(outer, ReasonBlacklisted, "outer")
+	outer.Remove()
+	tr.AddRemoval(inner, ReasonBlacklisted, "inner")
+
+	if len(tr.Removed) != 1 {
+		t.Errorf("Expected 1 removal, got %d", len(tr.Removed))
+	}
+}
+
+func TestSetField(t *testing.T) {
+	tr := New()
+	tr.SetField("title", "og:title", "First")
+	tr.SetField("title", "site rule", "Second")
+	tr.SetField("author", "meta author", "")
+
+	if len(tr.Metadata) != 1 {
+		t.Fatalf("Expected 1 field, got %d", len(tr.Metadata))
+	}
+	if tr.Metadata[0].Source != "site rule" {
+		t.Errorf("Expected replaced source, got %q", tr.Metadata[0].Source)
+	}
+}
@@ -0,0 +1,53 @@
+package extractor
+
+import (
+	"strings"
+
+	"github.com/LeadNewswire/article-extractor/internal/keyphrase"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Keyword is a key phrase of an article.
+type Keyword struct {
+	// Text is the phrase as it appears in the article or its tags
+	Text string `json:"text"`
+
+	// Score is the relevance of the phrase, from 0 to 1
+	Score float64 `json:"score"`
+
+	// Tagged reports whether the page's news_keywords or article:tag meta
+	// tags list the phrase
+	Tagged bool `json:"tagged,omitempty"`
+}
+
+// emphasisSelector selects the headings and bold text whose words weigh
+// more in keyword extraction.
+const emphasisSelector = "h1, h2, h3, h4, h5, h6, b, strong"
+
+// emphasizedText returns the text of the headings and bold text in content.
+func emphasizedText(content *goquery.Selection) []string {
+	var texts []string
+	content.Find(emphasisSelector).Each(func(_ int, sel *goquery.Selection) {
+		if text := strings.TrimSpace(sel.Text()); text != "" {
+			texts = append(texts, text)
+		}
+	})
+	return texts
+}
+
+// articleKeywords extracts the key phrases of an article.
+func articleKeywords(config *Config, article *Article, emphasis, tags []string) []Keyword {
+	phrases := keyphrase.Extract(keyphrase.Input{
+		Text:     article.TextContent,
+		Language: article.Language,
+		Title:    article.Title,
+		Emphasis: emphasis,
+		Tags:     tags,
+	}, config.MaxKeywords)
+
+	var keywords []Keyword
+	for _, p := range phrases {
+		keywords = append(keywords, Keyword{Text: p.Text, Score: p.Score, Tagged: p.Tagged})
+	}
+	return keywords
+}
@@ -0,0 +1,125 @@
+package extractor
+
+import (
+	"context"
+	"strings"
+
+	"github.com/LeadNewswire/article-extractor/internal/language"
+	"github.com/LeadNewswire/article-extractor/internal/pagination"
+	"github.com/LeadNewswire/article-extractor/internal/render"
+	"github.com/LeadNewswire/article-extractor/internal/summary"
+)
+
+// extractPages follows next-page links from the first page, extracts each
+// later page and merges them into a single article. Pages that fail to
+// fetch or extract end the chain; the pages merged so far are kept.
+func (e *Extractor) extractPages(ctx context.Context, first *page) *Article {
+	pages := []*page{first}
+	pageURLs := []string{first.article.URL}
+	visited := map[string]bool{
+		pagination.NormalizeURL(first.article.URL): true,
+	}
+
+	next := first.article.NextPageURL
+	for next != "" && len(pages) < e.config.MaxPages {
+		if ctx.Err() != nil {
+			break
+		}
+
+		// Stop on pagination loops
+		key := pagination.NormalizeURL(next)
+		if visited[key] {
+			next = ""
+			break
+		}
+		visited[key] = true
+
+		doc, err := e.fetchDocument(ctx, next)
+		if err != nil {
+			break
+		}
+
+		p, err := e.extractPage(ctx, doc, next)
+		if err != nil {
+			break
+		}
+
+		pages = append(pages, p)
+		pageURLs = append(pageURLs, next)
+		next = p.article.NextPageURL
+	}
+
+	article := e.mergePages(pages)
+	article.Pages = pageURLs
+	article.NextPageURL = next
+	return article
+}
+
+// mergePages merges the content of several pages into the first page's
+// article and recomputes the text, word count and confidence over the
+// merged content.
+func (e *Extractor) mergePages(pages []*page) *Article {
+	first := pages[0]
+	article := first.article
+	if len(pages) == 1 {
+		return article
+	}
+
+	var contents, rawTexts, markdowns, emphasis []string
+	var blocks []Block
+	var pageImages []Image
+	var linkText float64
+	var totalLength, wordCount, paragraphs int
+
+	content := first.content
+	for _, p := range pages {
+		contents = append(contents, p.article.Content)
+		rawTexts = append(rawTexts, p.rawText)
+		if p != first {
+			content = content.AddSelection(p.content)
+		}
+		wordCount += p.article.WordCount
+		blocks = append(blocks, p.article.Blocks...)
+		pageImages = append(pageImages, p.article.Images...)
+		emphasis = append(emphasis, p.emphasis...)
+		if p.article.Markdown != "" {
+			markdowns = append(markdowns, p.article.Markdown)
+		}
+
+		linkText += p.topCandidate.LinkDensity * float64(p.topCandidate.TextLength)
+		totalLength += p.topCandidate.TextLength
+		paragraphs += p.features.Paragraphs
+	}
+
+	// Combine the content features for the confidence model; page-level
+	// features and the score come from the first page, since a sum over
+	// pages would grow with the page count
+	features := first.features
+	features.WordCount = wordCount
+	features.Paragraphs = paragraphs
+	if totalLength > 0 {
+		features.LinkDensity = linkText / float64(totalLength)
+	}
+
+	article.Content = strings.Join(contents, "\n")
+	// The text is rendered over all pages at once so that link footnotes
+	// are numbered across pages
+	article.TextContent = render.Text(content, render.TextOptions{
+		LinkFootnotes: e.config.LinkFootnotes,
+	})
+	article.Markdown = strings.Join(markdowns, "\n\n")
+	article.Blocks = blocks
+	// Positions are counted across pages
+	for i := range pageImages {
+		pageImages[i].Position = i + 1
+	}
+	article.Images = pageImages
+	article.WordCount = wordCount
+	article.ReadingTime = language.ReadingTime(wordCount, article.Language)
+	article.Summary = summary.Summarize(article.TextContent, article.Language, e.config.SummarySentences)
+	article.Keywords = articleKeywords(e.config, article, emphasis, first.keywordTags)
+	article.Fingerprint = NewFingerprint(strings.Join(rawTexts, "\n"))
+	article.Confidence = confidenceModel(e.config).Predict(features)
+
+	return article
+}
@@ -0,0 +1,38 @@
+package extractor
+
+import (
+	"github.com/LeadNewswire/article-extractor/internal/classify"
+	"github.com/LeadNewswire/article-extractor/internal/cleaner"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// PageType is the kind of a page, such as a news article or a listing.
+type PageType = classify.PageType
+
+// Page types.
+const (
+	PageTypeNews         = classify.PageTypeNews
+	PageTypePressRelease = classify.PageTypePressRelease
+	PageTypeBlog         = classify.PageTypeBlog
+	PageTypeVideo        = classify.PageTypeVideo
+	PageTypeGallery      = classify.PageTypeGallery
+	PageTypeListing      = classify.PageTypeListing
+	PageTypeAuthor       = classify.PageTypeAuthor
+	PageTypeError        = classify.PageTypeError
+	PageTypeArticle      = classify.PageTypeArticle
+	PageTypeOther        = classify.PageTypeOther
+)
+
+// ClassifyPage determines the type of a page from its og:type, JSON-LD
+// @type, URL, content candidate score distribution and link density.
+// pageURL may be empty. The document is not modified.
+func ClassifyPage(doc *goquery.Document, pageURL string) PageType {
+	signals := classify.CollectSignals(doc, pageURL)
+
+	// Score a preprocessed copy for the candidate distribution
+	clone := goquery.NewDocumentFromNode(doc.Selection.Clone().Nodes[0])
+	cleaner.Preprocess(clone)
+	_, scoreMap := newScorer(DefaultConfig(), nil).Score(clone)
+
+	return signals.Classify(scoreMap)
+}
@@ -0,0 +1,559 @@
+package extractor
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/LeadNewswire/article-extractor/internal/classify"
+	"github.com/LeadNewswire/article-extractor/internal/cleaner"
+	"github.com/LeadNewswire/article-extractor/internal/confidence"
+	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/LeadNewswire/article-extractor/internal/keywords"
+	"github.com/LeadNewswire/article-extractor/internal/language"
+	"github.com/LeadNewswire/article-extractor/internal/metadata"
+	"github.com/LeadNewswire/article-extractor/internal/pagination"
+	"github.com/LeadNewswire/article-extractor/internal/render"
+	"github.com/LeadNewswire/article-extractor/internal/scorer"
+	"github.com/LeadNewswire/article-extractor/internal/summary"
+	"github.com/LeadNewswire/article-extractor/internal/trace"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Names of the built-in pipeline stages, in their default order.
+const (
+	StageMetadata      = "metadata"
+	StagePreprocess    = "preprocess"
+	StageScore         = "score"
+	StageMergeSiblings = "merge-siblings"
+	StagePostprocess   = "postprocess"
+	StageConvertURLs   = "convert-urls"
+	StageFinalize      = "finalize"
+	StageAlternatives  = "alternatives"
+	StageClassify      = "classify"
+)
+
+// Stage is one step of the extraction pipeline. Stages run in order on a
+// shared State; an error stops the extraction.
+type Stage interface {
+	// Name identifies the stage so it can be replaced or positioned
+	Name() string
+
+	// Run performs the stage
+	Run(state *State) error
+}
+
+// State is the extraction state shared by the pipeline stages.
+type State struct {
+	// Document is the parsed page; stages before StagePostprocess modify it
+	Document *goquery.Document
+
+	// URL is the page URL, empty if unknown
+	URL string
+
+	// Config is the extractor configuration and must not be modified
+	Config *Config
+
+	// Article holds the result; metadata is set by StageMetadata and the
+	// content fields by StageFinalize
+	Article *Article
+
+	// Candidate is the chosen content element in Document, set by
+	// StageScore and widened by StageMergeSiblings
+	Candidate *goquery.Selection
+
+	// Content is the cleaned copy of Candidate, set by StagePostprocess
+	Content *goquery.Selection
+
+	// Trace records extraction decisions; nil unless debug mode is enabled
+	Trace *Trace
+
+	ctx          context.Context
+	rule         *SiteRule
+	ruleContent  *goquery.Selection
+	topCandidate *scorer.NodeScore
+	scoreMap     *scorer.ScoreMap
+	cleanerOpts  *cleaner.Options
+	signals      *classify.Signals
+	features     confidence.Features
+	pageText     int
+	rawText      string
+	langHints    []language.Hint
+	description  string
+	descSource   string
+	keywordTags  []string
+	emphasis     []string
+}
+
+// Context returns the context of the extraction. Long-running stages
+// should stop when it is done; the pipeline then returns ErrTimeout.
+func (s *State) Context() context.Context {
+	return s.ctx
+}
+
+// StageFunc adapts a function to the Stage interface.
+type StageFunc struct {
+	name string
+	fn   func(state *State) error
+}
+
+// NewStage creates a Stage from a function.
+func NewStage(name string, fn func(state *State) error) Stage {
+	return &StageFunc{name: name, fn: fn}
+}
+
+// Name implements Stage.
+func (s *StageFunc) Name() string {
+	return s.name
+}
+
+// Run implements Stage.
+func (s *StageFunc) Run(state *State) error {
+	return s.fn(state)
+}
+
+// HookPoint identifies where a hook runs in the pipeline.
+type HookPoint int
+
+const (
+	// BeforePreprocess runs after metadata extraction, before preprocessing
+	BeforePreprocess HookPoint = iota
+
+	// AfterCandidate runs once the content candidate has been chosen
+	AfterCandidate
+
+	// AfterPostprocess runs on the cleaned content copy
+	AfterPostprocess
+)
+
+// Hook is a user function run at a HookPoint.
+type Hook func(state *State) error
+
+// PipelineEdit modifies the list of pipeline stages.
+type PipelineEdit func(stages []Stage) []Stage
+
+// DefaultStages returns the built-in pipeline stages in their default order.
+func DefaultStages() []Stage {
+	return []Stage{
+		NewStage(StageMetadata, runMetadata),
+		NewStage(StagePreprocess, runPreprocess),
+		NewStage(StageScore, runScore),
+		NewStage(StageMergeSiblings, runMergeSiblings),
+		NewStage(StagePostprocess, runPostprocess),
+		NewStage(StageConvertURLs, runConvertURLs),
+		NewStage(StageFinalize, runFinalize),
+		NewStage(StageAlternatives, runAlternatives),
+		NewStage(StageClassify, runClassify),
+	}
+}
+
+// buildPipeline applies the configured edits to the default stages.
+func buildPipeline(edits []PipelineEdit) []Stage {
+	stages := DefaultStages()
+	for _, edit := range edits {
+		stages = edit(stages)
+	}
+	return stages
+}
+
+// stageIndex returns the index of the named stage, or -1.
+func stageIndex(stages []Stage, name string) int {
+	for i, stage := range stages {
+		if stage.Name() == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// insertStage inserts a stage at index i.
+func insertStage(stages []Stage, i int, stage Stage) []Stage {
+	result := make([]Stage, 0, len(stages)+1)
+	result = append(result, stages[:i]...)
+	result = append(result, stage)
+	return append(result, stages[i:]...)
+}
+
+// InsertBefore returns an edit that inserts a stage before the named stage,
+// or appends it if there is no such stage.
+func InsertBefore(name string, stage Stage) PipelineEdit {
+	return func(stages []Stage) []Stage {
+		if i := stageIndex(stages, name); i != -1 {
+			return insertStage(stages, i, stage)
+		}
+		return append(stages, stage)
+	}
+}
+
+// InsertAfter returns an edit that inserts a stage after the named stage,
+// or appends it if there is no such stage.
+func InsertAfter(name string, stage Stage) PipelineEdit {
+	return func(stages []Stage) []Stage {
+		if i := stageIndex(stages, name); i != -1 {
+			return insertStage(stages, i+1, stage)
+		}
+		return append(stages, stage)
+	}
+}
+
+// Replace returns an edit that replaces the named stage.
+func Replace(name string, stage Stage) PipelineEdit {
+	return func(stages []Stage) []Stage {
+		if i := stageIndex(stages, name); i != -1 {
+			result := append([]Stage(nil), stages...)
+			result[i] = stage
+			return result
+		}
+		return stages
+	}
+}
+
+// Remove returns an edit that removes the named stage.
+func Remove(name string) PipelineEdit {
+	return func(stages []Stage) []Stage {
+		if i := stageIndex(stages, name); i != -1 {
+			result := append([]Stage(nil), stages[:i]...)
+			return append(result, stages[i+1:]...)
+		}
+		return stages
+	}
+}
+
+// String returns the name of the hook point, e.g. "after-candidate".
+func (p HookPoint) String() string {
+	switch p {
+	case BeforePreprocess:
+		return "before-preprocess"
+	case AfterCandidate:
+		return "after-candidate"
+	case AfterPostprocess:
+		return "after-postprocess"
+	default:
+		return "HookPoint(" + strconv.Itoa(int(p)) + ")"
+	}
+}
+
+// HookName returns the stage name of the nth hook (counting from 1)
+// installed at a point, e.g. "after-candidate-hook-2". It can be passed to
+// Replace, Remove or the insert edits to target that hook.
+func HookName(point HookPoint, n int) string {
+	return point.String() + "-hook-" + strconv.Itoa(n)
+}
+
+// hookEdit returns the edit that installs a hook at its point. Hooks at
+// the same point run in the order they were added. Unknown points are
+// ignored.
+func hookEdit(point HookPoint, hook Hook) PipelineEdit {
+	return func(stages []Stage) []Stage {
+		n := 1
+		for stageIndex(stages, HookName(point, n)) != -1 {
+			n++
+		}
+		stage := NewStage(HookName(point, n), hook)
+
+		// Later hooks at a point run after the earlier ones
+		if n > 1 {
+			return InsertAfter(HookName(point, n-1), stage)(stages)
+		}
+		switch point {
+		case BeforePreprocess:
+			return InsertBefore(StagePreprocess, stage)(stages)
+		case AfterCandidate:
+			return InsertAfter(StageMergeSiblings, stage)(stages)
+		case AfterPostprocess:
+			return InsertAfter(StagePostprocess, stage)(stages)
+		default:
+			return stages
+		}
+	}
+}
+
+// runPipeline runs the extractor's stages on a document. The context is
+// checked between stages and by the long element loops; once it is done,
+// an ErrTimeout error naming the interrupted stage is returned.
+func (e *Extractor) runPipeline(ctx context.Context, doc *goquery.Document, baseURL string) (*State, error) {
+	state := &State{
+		Document: doc,
+		URL:      baseURL,
+		Config:   e.config,
+		Article:  &Article{URL: baseURL},
+		ctx:      ctx,
+		rule:     e.config.Rules.Lookup(baseURL),
+	}
+
+	// Record extraction decisions in debug mode
+	if e.config.Debug {
+		state.Trace = trace.New()
+	}
+	cleanerOpts := e.cleaning
+	cleanerOpts.Trace = state.Trace
+	cleanerOpts.Context = ctx
+	state.cleanerOpts = &cleanerOpts
+
+	for _, stage := range e.stages {
+		if ctx.Err() != nil {
+			return nil, NewExtractionError(stage.Name(), baseURL, timeoutError(ctx))
+		}
+
+		err := stage.Run(state)
+
+		// Stages interrupted by the context may return partial results
+		if ctx.Err() != nil {
+			return nil, NewExtractionError(stage.Name(), baseURL, timeoutError(ctx))
+		}
+		if err != nil {
+			var extractionErr *ExtractionError
+			if errors.As(err, &extractionErr) {
+				return nil, err
+			}
+			return nil, NewExtractionError(stage.Name(), baseURL, err)
+		}
+	}
+
+	state.Article.Trace = state.Trace
+	return state, nil
+}
+
+// runMetadata extracts metadata and the next page URL before preprocessing
+// removes elements. Site rules override the generic heuristics.
+func runMetadata(state *State) error {
+	doc := state.Document
+	article := state.Article
+
+	title, titleSource := metadata.ExtractTitleWithSource(doc)
+	author, authorSource := metadata.ExtractAuthorWithSource(doc)
+	publishedAt, dateSource := metadata.ExtractDateWithSource(doc)
+	leadImage, imageSource := extractLeadImage(doc, state.URL)
+
+	// Collect page type signals before preprocessing removes JSON-LD
+	state.signals = classify.CollectSignals(doc, state.URL)
+	state.pageText = dom.GetVisibleTextLength(doc.Find("body"))
+	state.langHints = metadata.ExtractLanguageHints(doc)
+	state.description, state.descSource = metadata.ExtractDescriptionWithSource(doc)
+	state.keywordTags = metadata.ExtractKeywordTags(doc)
+
+	// Find the next page before preprocessing strips pagination links
+	var nextPageURL string
+	if state.URL != "" {
+		nextPageURL = pagination.FindNextPageURL(doc, state.URL)
+	}
+
+	if rule := state.rule; rule != nil {
+		if value := selectValue(doc, rule.Title); value != "" {
+			title, titleSource = value, ruleSource
+		}
+		if value := selectValue(doc, rule.Author); value != "" {
+			author, authorSource = value, ruleSource
+		}
+		if value := selectValue(doc, rule.Date); value != "" {
+			if date := metadata.ParseDate(value); date != nil {
+				publishedAt, dateSource = date, ruleSource
+			}
+		}
+		if img := rule.selectLeadImage(doc); img != nil {
+			leadImage, imageSource = img, ruleSource
+		}
+		if next := rule.selectNextPage(doc, state.URL); next != "" {
+			nextPageURL = next
+		}
+		state.ruleContent = rule.selectContent(doc)
+	}
+
+	tr := state.Trace
+	tr.SetField("title", titleSource, title)
+	tr.SetField("author", authorSource, author)
+	if publishedAt != nil {
+		tr.SetField("publishedAt", dateSource, publishedAt.Format(time.RFC3339))
+	}
+	if leadImage != nil {
+		tr.SetField("leadImage", imageSource, leadImage.URL)
+	}
+
+	article.Title = title
+	article.Author = author
+	article.PublishedAt = publishedAt
+	article.LeadImage = leadImage
+	article.NextPageURL = nextPageURL
+
+	state.features.HasAuthor = author != ""
+	state.features.HasDate = publishedAt != nil
+	return nil
+}
+
+// runPreprocess removes non-content elements from the document.
+func runPreprocess(state *State) error {
+	cleaner.PreprocessWithOptions(state.Document, state.cleanerOpts)
+
+	// Record how much of the page text was removed
+	remaining := dom.GetVisibleTextLength(state.Document.Find("body"))
+	if state.pageText > 0 {
+		state.features.RemovedRatio = 1 - float64(remaining)/float64(state.pageText)
+	}
+	state.pageText = remaining
+	return nil
+}
+
+// runScore scores the document and chooses the content candidate, unless
+// a site rule already selected the content.
+func runScore(state *State) error {
+	s := newScorer(state.Config, state.cleanerOpts.Keywords)
+
+	if state.ruleContent != nil {
+		// Use the rule's content container instead of the top candidate
+		state.topCandidate = s.ScoreSelection(state.ruleContent)
+		state.scoreMap = scorer.NewScoreMap()
+		state.scoreMap.Set(state.ruleContent, state.topCandidate)
+		state.Candidate = state.ruleContent
+		state.Trace.SetField("content", ruleSource, trace.Describe(state.ruleContent.Children().First()))
+		return nil
+	}
+
+	topCandidate, scoreMap, err := s.ScoreContext(state.ctx, state.Document)
+	if err != nil {
+		return err
+	}
+
+	// Check if we found content
+	if topCandidate == nil || topCandidate.Selection == nil {
+		return NewExtractionError("extract", state.URL, ErrNoContent)
+	}
+
+	traceCandidates(state.Trace, topCandidate, scoreMap)
+
+	state.topCandidate = topCandidate
+	state.scoreMap = scoreMap
+	state.Candidate = topCandidate.Selection
+	return nil
+}
+
+// runMergeSiblings widens the candidate with qualifying siblings.
+func runMergeSiblings(state *State) error {
+	if state.ruleContent != nil || state.topCandidate == nil || state.Candidate == nil {
+		return nil
+	}
+
+	state.Candidate = cleaner.MergeSiblingsWithOptions(
+		state.Candidate,
+		state.topCandidate.GetScore(),
+		state.Config.MinParagraphLength,
+		state.cleanerOpts,
+	)
+	return nil
+}
+
+// runPostprocess cleans a copy of the candidate.
+func runPostprocess(state *State) error {
+	if state.Candidate == nil || state.Candidate.Length() == 0 {
+		return NewExtractionError("extract", state.URL, ErrNoContent)
+	}
+
+	// Clone the content for cleaning
+	state.Content = state.Candidate.Clone()
+
+	cleaner.PostprocessWithOptions(state.Content, state.cleanerOpts)
+	return nil
+}
+
+// runConvertURLs converts relative URLs in the content to absolute.
+func runConvertURLs(state *State) error {
+	if state.URL != "" && state.Content != nil {
+		cleaner.ConvertRelativeURLs(state.Content, state.URL)
+	}
+	return nil
+}
+
+// runFinalize renders the content and computes the content statistics.
+func runFinalize(state *State) error {
+	if state.Content == nil {
+		return NewExtractionError("extract", state.URL, ErrNoContent)
+	}
+
+	// Score the candidate if a custom stage chose it
+	if state.topCandidate == nil {
+		state.topCandidate = newScorer(state.Config, state.cleanerOpts.Keywords).ScoreSelection(state.Candidate)
+		state.scoreMap = scorer.NewScoreMap()
+		state.scoreMap.Set(state.Candidate, state.topCandidate)
+	}
+
+	// Get cleaned HTML and text. The statistics use the raw text so that
+	// list markers and heading underlines are not counted.
+	contentHTML := cleaner.GetCleanHTML(state.Content)
+	rawText := cleaner.GetCleanText(state.Content)
+
+	// Check content length
+	if len(rawText) < state.Config.MinContentLength {
+		return NewExtractionError("validate", state.URL, ErrContentTooShort)
+	}
+
+	article := state.Article
+	article.Content = contentHTML
+	article.TextContent = render.Text(state.Content, render.TextOptions{
+		LinkFootnotes: state.Config.LinkFootnotes,
+	})
+	article.WordCount = dom.CountWords(rawText)
+	// The fingerprint leaves out link footnotes, whose URLs differ between
+	// copies of an article
+	article.Fingerprint = NewFingerprint(rawText)
+	state.rawText = rawText
+	article.Score = state.topCandidate.GetScore()
+
+	// Score the images around the content when no meta tag or site rule
+	// named a usable lead image
+	if article.LeadImage == nil {
+		if img := contentLeadImage(state); img != nil {
+			article.LeadImage = img
+			state.Trace.SetField("leadImage", "article image", img.URL)
+		}
+	}
+	article.Images = contentImages(state.Content)
+	captionLeadImage(article.LeadImage, article.Images, state.URL)
+
+	// Declared languages win unless they are missing or disagree
+	lang := language.Resolve(state.langHints, article.TextContent)
+	article.Language = lang.Code
+	article.LanguageConfidence = lang.Confidence
+	article.ReadingTime = language.ReadingTime(article.WordCount, article.Language)
+	state.Trace.SetField("language", lang.Source, lang.Code)
+
+	// How far the top candidate leads the runner-up
+	var second float64
+	if candidates := state.scoreMap.GetCandidatesByScore(); len(candidates) >= 2 {
+		second = candidates[1].GetScore()
+	}
+	state.features = contentFeatures(state, state.topCandidate, state.Content, rawText, article.WordCount, second)
+	article.Confidence = confidenceModel(state.Config).Predict(state.features)
+	article.Summary = summary.Summarize(article.TextContent, article.Language, state.Config.SummarySentences)
+	article.Excerpt = articleExcerpt(state, rawText)
+	state.emphasis = emphasizedText(state.Content)
+	article.Keywords = articleKeywords(state.Config, article, state.emphasis, state.keywordTags)
+
+	if state.Config.Markdown {
+		article.Markdown = render.Markdown(state.Content)
+	}
+	if state.Config.Blocks {
+		article.Blocks = render.Blocks(state.Content)
+	}
+	return nil
+}
+
+// runClassify sets the page type.
+func runClassify(state *State) error {
+	if state.signals == nil {
+		state.signals = classify.CollectSignals(state.Document, state.URL)
+	}
+	state.Article.PageType = state.signals.Classify(state.scoreMap)
+	return nil
+}
+
+// newScorer creates a scorer from the configuration and keyword set (nil
+// uses the default keywords).
+func newScorer(config *Config, kw *keywords.Set) *scorer.Scorer {
+	s := scorer.NewScorer(
+		config.MinParagraphLength,
+		config.MinContentLength,
+		config.Debug,
+	)
+	s.SetKeywords(kw)
+	return s
+}
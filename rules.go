@@ -0,0 +1,316 @@
+package extractor
+
+import (
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/LeadNewswire/article-extractor/internal/pagination"
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html/atom"
+)
+
+// SiteRule describes site-specific extraction rules for one or more domains.
+// When a rule matches the document URL, its selectors take precedence over
+// the generic metadata heuristics and the content scorer.
+//
+// Each field selector list is tried in order and the first selector that
+// yields a non-empty value wins. The value of a matched element is taken
+// from its "content" attribute for meta tags, "datetime" for time tags,
+// "src" for images and the element text otherwise.
+type SiteRule struct {
+	// Domain is the primary domain the rule applies to (e.g. "example.com").
+	// Subdomains of Domain match as well.
+	Domain string
+
+	// SupportedDomains lists additional domains the rule applies to.
+	SupportedDomains []string
+
+	// Title selectors for the article title
+	Title []string
+
+	// Author selectors for the article author
+	Author []string
+
+	// Date selectors for the publication date
+	Date []string
+
+	// Content selectors for the main content container
+	Content []string
+
+	// LeadImage selectors for the lead image
+	LeadImage []string
+
+	// NextPage selectors for the link to the next page of the article.
+	// The href attribute of the matched element is used.
+	NextPage []string
+
+	// Clean selectors for elements to remove from the content
+	Clean []string
+
+	// Transforms are applied to the content before cleaning, in order.
+	Transforms []TransformRule
+}
+
+// TransformRule applies a Transform to every content element matching Selector.
+type TransformRule struct {
+	// Selector is the CSS selector of the elements to transform
+	Selector string
+
+	// Transform is the transformation to apply
+	Transform Transform
+}
+
+// Transform modifies a matched element in the content.
+type Transform func(sel *goquery.Selection)
+
+// RenameTag returns a Transform that changes the tag name of matched elements.
+func RenameTag(tag string) Transform {
+	tag = strings.ToLower(tag)
+	return func(sel *goquery.Selection) {
+		for _, node := range sel.Nodes {
+			node.Data = tag
+			node.DataAtom = atom.Lookup([]byte(tag))
+		}
+	}
+}
+
+// Unwrap returns a Transform that replaces matched elements with their children.
+func Unwrap() Transform {
+	return func(sel *goquery.Selection) {
+		dom.UnwrapElement(sel)
+	}
+}
+
+// RuleRegistry holds site rules keyed by domain.
+// It is safe for concurrent use.
+type RuleRegistry struct {
+	mu    sync.RWMutex
+	rules map[string]*SiteRule
+}
+
+// NewRuleRegistry creates a registry with the given rules.
+func NewRuleRegistry(rules ...*SiteRule) *RuleRegistry {
+	r := &RuleRegistry{
+		rules: make(map[string]*SiteRule),
+	}
+	for _, rule := range rules {
+		r.Register(rule)
+	}
+	return r
+}
+
+// Register adds a rule to the registry, replacing any rule previously
+// registered for the same domains.
+func (r *RuleRegistry) Register(rule *SiteRule) {
+	if rule == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, domain := range append([]string{rule.Domain}, rule.SupportedDomains...) {
+		domain = normalizeDomain(domain)
+		if domain != "" {
+			r.rules[domain] = rule
+		}
+	}
+}
+
+// clone returns a registry with the same rules, or an empty registry for
+// a nil receiver.
+func (r *RuleRegistry) clone() *RuleRegistry {
+	if r == nil {
+		return NewRuleRegistry()
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	c := &RuleRegistry{
+		rules: make(map[string]*SiteRule, len(r.rules)),
+	}
+	for domain, rule := range r.rules {
+		c.rules[domain] = rule
+	}
+	return c
+}
+
+// Lookup returns the rule for a URL or host name, or nil if none matches.
+// Parent domains are tried when there is no exact match, so a rule for
+// "example.com" also applies to "news.example.com".
+func (r *RuleRegistry) Lookup(rawURL string) *SiteRule {
+	if r == nil {
+		return nil
+	}
+
+	host := hostFromURL(rawURL)
+	if host == "" {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for host != "" {
+		if rule, ok := r.rules[host]; ok {
+			return rule
+		}
+		idx := strings.Index(host, ".")
+		if idx == -1 {
+			break
+		}
+		host = host[idx+1:]
+	}
+
+	return nil
+}
+
+// Len returns the number of registered domains.
+func (r *RuleRegistry) Len() int {
+	if r == nil {
+		return 0
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.rules)
+}
+
+// hostFromURL extracts the normalized host name from a URL or bare host.
+func hostFromURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	if rawURL == "" {
+		return ""
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return normalizeDomain(u.Hostname())
+}
+
+// normalizeDomain lowercases a domain and strips a leading "www.".
+func normalizeDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	domain = strings.TrimSuffix(domain, ".")
+	return strings.TrimPrefix(domain, "www.")
+}
+
+// selectValue returns the first non-empty value matched by the selectors.
+func selectValue(doc *goquery.Document, selectors []string) string {
+	for _, selector := range selectors {
+		var value string
+		doc.Find(selector).EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+			value = elementValue(sel)
+			return value == ""
+		})
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// selectNextPage returns the absolute next page URL matched by the rule.
+func (rule *SiteRule) selectNextPage(doc *goquery.Document, pageURL string) string {
+	for _, selector := range rule.NextPage {
+		href := strings.TrimSpace(doc.Find(selector).First().AttrOr("href", ""))
+		if next := pagination.ResolveURL(pageURL, href); next != "" {
+			return next
+		}
+	}
+	return ""
+}
+
+// elementValue returns the meaningful value of an element.
+func elementValue(sel *goquery.Selection) string {
+	switch dom.GetTagName(sel) {
+	case "meta":
+		return strings.TrimSpace(dom.GetAttribute(sel, "content"))
+	case "time":
+		if datetime := strings.TrimSpace(dom.GetAttribute(sel, "datetime")); datetime != "" {
+			return datetime
+		}
+	case "img":
+		src := strings.TrimSpace(dom.GetAttribute(sel, "src"))
+		if src == "" {
+			src = strings.TrimSpace(dom.GetAttribute(sel, "data-src"))
+		}
+		return src
+	}
+	return dom.GetText(sel)
+}
+
+// selectContent returns a copy of the first content container matched by
+// the rule, with the rule's transforms and clean selectors applied.
+func (rule *SiteRule) selectContent(doc *goquery.Document) *goquery.Selection {
+	for _, selector := range rule.Content {
+		var content *goquery.Selection
+		doc.Find(selector).EachWithBreak(func(_ int, sel *goquery.Selection) bool {
+			if dom.GetText(sel) != "" {
+				content = sel
+				return false
+			}
+			return true
+		})
+		if content == nil {
+			continue
+		}
+
+		// Work on a copy wrapped in a container so unwrapping the
+		// root element keeps its children
+		clone := content.Clone()
+		wrapper, err := goquery.NewDocumentFromReader(strings.NewReader("<div></div>"))
+		if err != nil {
+			return clone
+		}
+		root := wrapper.Find("div").First()
+		root.AppendSelection(clone)
+
+		for _, tr := range rule.Transforms {
+			if tr.Transform == nil || tr.Selector == "" {
+				continue
+			}
+			root.Find(tr.Selector).Each(func(_ int, sel *goquery.Selection) {
+				tr.Transform(sel)
+			})
+		}
+
+		for _, selector := range rule.Clean {
+			root.Find(selector).Remove()
+		}
+
+		return root
+	}
+	return nil
+}
+
+// selectLeadImage returns the lead image matched by the rule.
+func (rule *SiteRule) selectLeadImage(doc *goquery.Document) *Image {
+	for _, selector := range rule.LeadImage {
+		sel := doc.Find(selector).First()
+		if sel.Length() == 0 {
+			continue
+		}
+
+		src := elementValue(sel)
+		if src == "" {
+			continue
+		}
+
+		img := &Image{URL: src}
+		if dom.GetTagName(sel) == "img" {
+			img.Alt = dom.GetAttribute(sel, "alt")
+			img.Width = parseInt(dom.GetAttribute(sel, "width"))
+			img.Height = parseInt(dom.GetAttribute(sel, "height"))
+		}
+		return img
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package extractor
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/LeadNewswire/article-extractor/internal/dom"
+	"github.com/LeadNewswire/article-extractor/internal/fingerprint"
+	"github.com/LeadNewswire/article-extractor/internal/summary"
+)
+
+const (
+	// excerptLength is the maximum length of Article.Excerpt in runes
+	excerptLength = 200
+
+	// minDescriptionLength is the length in runes a page description
+	// needs to be used as the excerpt
+	minDescriptionLength = 40
+
+	// minDescriptionOverlap is the share of a description's words that
+	// must appear in the content for it to be used as the excerpt
+	minDescriptionOverlap = 0.5
+)
+
+// articleExcerpt returns the excerpt of an article: the page description
+// if it is a good one, otherwise the start of the text without its wire
+// dateline.
+func articleExcerpt(state *State, rawText string) string {
+	if usableDescription(state.description, state.Article.Title, rawText) {
+		state.Trace.SetField("excerpt", state.descSource, state.description)
+		return dom.GetExcerpt(state.description, excerptLength)
+	}
+	return dom.GetExcerpt(summary.StripDateline(strings.TrimSpace(rawText)), excerptLength)
+}
+
+// usableDescription reports whether a page description describes the
+// content: it is long enough, is not just the title, and most of its words
+// appear in the text. Site-wide boilerplate descriptions fail the last test.
+func usableDescription(description, title, text string) bool {
+	if utf8.RuneCountInString(description) < minDescriptionLength {
+		return false
+	}
+	if fingerprint.Normalize(description) == fingerprint.Normalize(title) {
+		return false
+	}
+
+	normalized := fingerprint.Normalize(text)
+	words, found := 0, 0
+	for _, word := range fingerprint.Words(description) {
+		if utf8.RuneCountInString(word) <= 3 {
+			continue
+		}
+		words++
+		if strings.Contains(normalized, word) {
+			found++
+		}
+	}
+	return words > 0 && float64(found) >= minDescriptionOverlap*float64(words)
+}
@@ -0,0 +1,47 @@
+package extractor
+
+import (
+	"github.com/LeadNewswire/article-extractor/internal/scorer"
+	"github.com/LeadNewswire/article-extractor/internal/trace"
+)
+
+// ruleSource is the trace source name for values taken from a site rule.
+const ruleSource = "site rule"
+
+// Trace is a structured record of the decisions made during an extraction.
+// It is returned in Article.Trace when debug mode is enabled and can be
+// serialized to JSON for bug reports.
+type Trace = trace.Trace
+
+// TraceRemoval describes an element removed during preprocessing.
+type TraceRemoval = trace.Removal
+
+// TraceCandidate describes a scored content candidate.
+type TraceCandidate = trace.Candidate
+
+// TraceSibling describes a sibling merge decision.
+type TraceSibling = trace.Sibling
+
+// TraceField describes where a metadata field came from.
+type TraceField = trace.Field
+
+// traceCandidates records the top scoring candidates.
+func traceCandidates(tr *trace.Trace, topCandidate *scorer.NodeScore, scoreMap *scorer.ScoreMap) {
+	if tr == nil {
+		return
+	}
+
+	for i, ns := range scoreMap.GetCandidatesByScore() {
+		if i >= trace.MaxCandidates {
+			break
+		}
+		tr.AddCandidate(trace.Candidate{
+			Element:      trace.Describe(ns.Selection),
+			ContentScore: ns.ContentScore,
+			Weight:       ns.Weight,
+			LinkDensity:  ns.LinkDensity,
+			TextLength:   ns.TextLength,
+			Selected:     ns == topCandidate,
+		})
+	}
+}